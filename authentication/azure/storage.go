@@ -26,7 +26,7 @@ func GetAzureStorageCredentials(log logger.Logger, accountName string, metadata
 	accountKey, ok := metadata[storageAccountKeyKey]
 	if ok && accountKey != "" {
 		credential, newSharedKeyErr := azblob.NewSharedKeyCredential(accountName, accountKey)
-		if err != nil {
+		if newSharedKeyErr != nil {
 			return nil, nil, fmt.Errorf("invalid credentials with error: %s", newSharedKeyErr.Error())
 		}
 
@@ -0,0 +1,64 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Defines if Init validates the bucket name against the naming rules, defaults to true. Disable it
+// for legacy buckets or S3 compatible services with other rules.
+const metadataKeyValidateBucketName = "validateBucketName"
+
+// Prefixes and suffixes reserved by S3
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3"}
+)
+
+// validateBucketName checks name against the naming rules of general purpose buckets, which keep
+// the bucket addressable as a DNS name, so that an invalid name fails in Init rather than on the
+// first request.
+// See: https://docs.aws.amazon.com/AmazonS3/latest/userguide/bucketnamingrules.html
+func validateBucketName(name string) error {
+	var rule string
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		rule = "must be between 3 and 63 characters long"
+	case strings.IndexFunc(name, func(r rune) bool { return !isLowerAlphanumeric(r) && r != '-' && r != '.' }) >= 0:
+		rule = "can only contain lowercase letters, numbers, dots and hyphens"
+	case !isLowerAlphanumeric(rune(name[0])):
+		rule = "must start with a letter or a number"
+	case !isLowerAlphanumeric(rune(name[len(name)-1])):
+		rule = "must end with a letter or a number"
+	case strings.Contains(name, ".."):
+		rule = "can't contain consecutive dots"
+	case net.ParseIP(name) != nil:
+		rule = "can't be formatted as an IP address"
+	default:
+		for _, prefix := range reservedBucketPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				rule = fmt.Sprintf("can't start with the reserved prefix %s", prefix)
+			}
+		}
+		for _, suffix := range reservedBucketSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				rule = fmt.Sprintf("can't end with the reserved suffix %s", suffix)
+			}
+		}
+		if rule == "" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid bucket name %q: bucket names %s, disable %s to skip this check", name, rule, metadataKeyValidateBucketName)
+}
+
+func isLowerAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,50 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBucketName(t *testing.T) {
+	for _, name := range []string{"abc", "my-bucket.logs", "000", "xn-bucket"} {
+		assert.Nil(t, validateBucketName(name), name)
+	}
+
+	for name, rule := range map[string]string{
+		"ab":           "between 3 and 63 characters",
+		"MyBucket":     "lowercase letters, numbers, dots and hyphens",
+		"my_bucket":    "lowercase letters, numbers, dots and hyphens",
+		".bucket":      "start with a letter or a number",
+		"bucket-":      "end with a letter or a number",
+		"my..bucket":   "consecutive dots",
+		"192.168.5.4":  "IP address",
+		"xn--bucket":   "reserved prefix xn--",
+		"sthree-data":  "reserved prefix sthree-",
+		"data-s3alias": "reserved suffix -s3alias",
+		"data--ol-s3":  "reserved suffix --ol-s3",
+	} {
+		err := validateBucketName(name)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), rule, name)
+		}
+	}
+}
+
+func TestParseMetadataBucketName(t *testing.T) {
+	s := NewAWSS3(logger.NewLogger("test"))
+
+	_, err := s.parseMetadata(bindings.Metadata{Properties: map[string]string{"bucket": "My_Bucket"}})
+	assert.Error(t, err)
+
+	m, err := s.parseMetadata(bindings.Metadata{Properties: map[string]string{"bucket": "My_Bucket", "validateBucketName": "false"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "My_Bucket", m.Bucket)
+}
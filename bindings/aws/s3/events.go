@@ -0,0 +1,233 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// When sqsQueueUrl is set the binding can be used as an input binding: it consumes the S3 event
+// notifications the bucket publishes to the queue and triggers for every created object.
+// See: https://docs.aws.amazon.com/AmazonS3/latest/userguide/NotificationHowTo.html
+
+const (
+	metadataKeyBucket = "bucket"
+
+	s3EventObjectCreatedPrefix = "ObjectCreated:"
+	s3TestEvent                = "s3:TestEvent"
+)
+
+var ErrSQSQueueURLRequired = errors.New("sqsQueueUrl is required to use the s3 binding as an input binding")
+
+// errInvalidEventNotification is returned for messages that can't be parsed as S3 event
+// notifications, which would fail the same way every time they are received.
+var errInvalidEventNotification = errors.New("invalid s3 event notification")
+
+// ErrMD5Mismatch is returned when the contents of an object don't match its MD5 digest.
+var ErrMD5Mismatch = errors.New("the object contents don't match their MD5 digest")
+
+// s3EventNotification is the message S3 sends to the queue for bucket events.
+type s3EventNotification struct {
+	// Set on the test message S3 sends when the notification is configured
+	Event   string          `json:"Event"`
+	Records []s3EventRecord `json:"Records"`
+}
+
+type s3EventRecord struct {
+	EventName string `json:"eventName"`
+	EventTime string `json:"eventTime"`
+	S3        struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+			ETag string `json:"eTag"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+type objectCreatedEvent struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"etag,omitempty"`
+	EventName string `json:"eventName"`
+	EventTime string `json:"eventTime"`
+}
+
+// Read receives the event notifications of the queue. Messages are deleted once the handler
+// succeeded for all the objects they contain, otherwise they are received again after the
+// visibility timeout of the queue. Messages that aren't valid event notifications are deleted
+// without calling the handler.
+func (s *AWSS3) Read(handler func(*bindings.ReadResponse) ([]byte, error)) error {
+	if s.sqsClient == nil {
+		return ErrSQSQueueURLRequired
+	}
+
+	for {
+		result, err := s.sqsClient.ReceiveMessage(&sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.metadata.SQSQueueURL),
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(20),
+		})
+		if err != nil {
+			s.logger.Errorf("unable to receive message from queue %q: %v", s.metadata.SQSQueueURL, err)
+			time.Sleep(time.Second)
+
+			continue
+		}
+
+		for _, m := range result.Messages {
+			s.handleMessage(handler, m)
+		}
+	}
+}
+
+// handleMessage handles a message of the queue and deletes it, unless the handler or the
+// download of an object failed.
+func (s *AWSS3) handleMessage(handler func(*bindings.ReadResponse) ([]byte, error), m *sqs.Message) {
+	if err := s.handleEventMessage(handler, aws.StringValue(m.Body)); err != nil {
+		if !errors.Is(err, errInvalidEventNotification) {
+			s.logger.Errorf("error handling s3 event notification %s: %v", aws.StringValue(m.MessageId), err)
+
+			return
+		}
+		s.logger.Errorf("deleting message %s from queue %q: %v", aws.StringValue(m.MessageId), s.metadata.SQSQueueURL, err)
+	}
+
+	_, err := s.sqsClient.DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.metadata.SQSQueueURL),
+		ReceiptHandle: m.ReceiptHandle,
+	})
+	if err != nil {
+		s.logger.Errorf("error deleting message %s from queue %q: %v", aws.StringValue(m.MessageId), s.metadata.SQSQueueURL, err)
+	}
+}
+
+// handleEventMessage calls the handler for every object created event of the message. The event
+// is delivered as JSON, or the object contents are delivered when fetchContent is enabled.
+func (s *AWSS3) handleEventMessage(handler func(*bindings.ReadResponse) ([]byte, error), body string) error {
+	var notification s3EventNotification
+	if err := json.Unmarshal([]byte(body), &notification); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidEventNotification, err)
+	}
+	if notification.Event == s3TestEvent {
+		return nil
+	}
+
+	for _, record := range notification.Records {
+		if !strings.HasPrefix(record.EventName, s3EventObjectCreatedPrefix) {
+			continue
+		}
+
+		// Object keys are URL encoded in event notifications
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return fmt.Errorf("%w: invalid object key %q: %v", errInvalidEventNotification, record.S3.Object.Key, err)
+		}
+
+		event := objectCreatedEvent{
+			Bucket:    record.S3.Bucket.Name,
+			Key:       key,
+			Size:      record.S3.Object.Size,
+			ETag:      record.S3.Object.ETag,
+			EventName: record.EventName,
+			EventTime: record.EventTime,
+		}
+
+		var data []byte
+		if s.metadata.FetchContent {
+			data, err = s.getObjectContent(event.Bucket, key)
+		} else {
+			data, err = json.Marshal(event)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = handler(&bindings.ReadResponse{
+			Data: data,
+			Metadata: map[string]string{
+				metadataKeyBucket: event.Bucket,
+				metadataKeyKey:    key,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getObjectContent downloads an object within the read timeout, so that a stalled download doesn't
+// block the receive loop. With validateMD5 the contents are compared with the ETag of the object, see
+// objectMD5. The message is received again after a mismatch.
+func (s *AWSS3) getObjectContent(bucket, key string) ([]byte, error) {
+	ctx, cancel := s.metadata.Timeouts.ReadContext(context.Background())
+	defer cancel()
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket:              aws.String(bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting object %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	data, err := ioutil.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading object %s: %w", key, err)
+	}
+
+	if s.metadata.ValidateMD5 {
+		expected, ok := objectMD5(out)
+		if !ok {
+			s.logger.Debugf("not validating object %s, its ETag %s isn't an MD5 digest", key, aws.StringValue(out.ETag))
+
+			return data, nil
+		}
+		if digest := md5.Sum(data); hex.EncodeToString(digest[:]) != expected { //nolint:gosec
+			return nil, fmt.Errorf("%w: object %s", ErrMD5Mismatch, key)
+		}
+	}
+
+	return data, nil
+}
+
+// objectMD5 returns the hex encoded MD5 digest of an object from its ETag. S3 doesn't store the
+// Content-MD5 of uploads, but the ETag is their MD5 digest unless the object was uploaded in parts,
+// which gives "<digest>-<part count>" ETags, or is encrypted with SSE-C or SSE-KMS. The checksums S3
+// can store for those objects aren't returned by the SDK version of the binding, so they can't be
+// validated.
+func objectMD5(out *s3.GetObjectOutput) (string, bool) {
+	if out.SSECustomerAlgorithm != nil || aws.StringValue(out.ServerSideEncryption) == s3.ServerSideEncryptionAwsKms {
+		return "", false
+	}
+	etag := strings.ToLower(strings.Trim(aws.StringValue(out.ETag), "\""))
+	if digest, err := hex.DecodeString(etag); err != nil || len(digest) != md5.Size {
+		return "", false
+	}
+
+	return etag, true
+}
@@ -0,0 +1,197 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testEventNotification = `{"Records":[
+	{"eventName":"ObjectCreated:Put","eventTime":"2021-08-01T10:00:00.000Z","s3":{"bucket":{"name":"test"},"object":{"key":"dir/my+file.txt","size":5,"eTag":"abc"}}},
+	{"eventName":"ObjectRemoved:Delete","eventTime":"2021-08-01T10:00:01.000Z","s3":{"bucket":{"name":"test"},"object":{"key":"other.txt"}}}
+]}`
+
+func TestHandleEventMessage(t *testing.T) {
+	t.Run("deliver object created events", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{}, logger: logger.NewLogger("test")}
+
+		var responses []*bindings.ReadResponse
+		err := binding.handleEventMessage(func(r *bindings.ReadResponse) ([]byte, error) {
+			responses = append(responses, r)
+
+			return nil, nil
+		}, testEventNotification)
+		assert.Nil(t, err)
+		assert.Len(t, responses, 1)
+		assert.Equal(t, "dir/my file.txt", responses[0].Metadata["key"])
+
+		var event objectCreatedEvent
+		assert.Nil(t, json.Unmarshal(responses[0].Data, &event))
+		assert.Equal(t, objectCreatedEvent{
+			Bucket:    "test",
+			Key:       "dir/my file.txt",
+			Size:      5,
+			ETag:      "abc",
+			EventName: "ObjectCreated:Put",
+			EventTime: "2021-08-01T10:00:00.000Z",
+		}, event)
+	})
+
+	t.Run("deliver object contents with fetchContent", func(t *testing.T) {
+		client := &mockS3Client{
+			getObject: func(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+				assert.Equal(t, "dir/my file.txt", aws.StringValue(input.Key))
+
+				return &s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader("hello"))}, nil
+			},
+		}
+		binding := AWSS3{metadata: &s3Metadata{FetchContent: true}, client: client, logger: logger.NewLogger("test")}
+
+		var data []byte
+		err := binding.handleEventMessage(func(r *bindings.ReadResponse) ([]byte, error) {
+			data = r.Data
+
+			return nil, nil
+		}, testEventNotification)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+
+	t.Run("ignore test events", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{}, logger: logger.NewLogger("test")}
+
+		err := binding.handleEventMessage(func(r *bindings.ReadResponse) ([]byte, error) {
+			t.Fatal("handler should not be called")
+
+			return nil, nil
+		}, `{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"test"}`)
+		assert.Nil(t, err)
+	})
+
+	t.Run("return handler error", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{}, logger: logger.NewLogger("test")}
+
+		err := binding.handleEventMessage(func(r *bindings.ReadResponse) ([]byte, error) {
+			return nil, errors.New("failed")
+		}, testEventNotification)
+		assert.Error(t, err)
+	})
+}
+
+type mockSQSClient struct {
+	sqsiface.SQSAPI
+	deleted []string
+}
+
+func (m *mockSQSClient) DeleteMessage(input *sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error) {
+	m.deleted = append(m.deleted, aws.StringValue(input.ReceiptHandle))
+
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestHandleMessage(t *testing.T) {
+	handle := func(body string, handlerErr error) []string {
+		sqsClient := &mockSQSClient{}
+		binding := AWSS3{metadata: &s3Metadata{}, sqsClient: sqsClient, logger: logger.NewLogger("test")}
+		binding.handleMessage(func(r *bindings.ReadResponse) ([]byte, error) {
+			return nil, handlerErr
+		}, &sqs.Message{Body: aws.String(body), ReceiptHandle: aws.String("receipt")})
+
+		return sqsClient.deleted
+	}
+
+	t.Run("delete handled messages", func(t *testing.T) {
+		assert.Equal(t, []string{"receipt"}, handle(testEventNotification, nil))
+	})
+
+	t.Run("keep messages the handler failed for", func(t *testing.T) {
+		assert.Empty(t, handle(testEventNotification, errors.New("failed")))
+	})
+
+	t.Run("delete messages that aren't event notifications", func(t *testing.T) {
+		assert.Equal(t, []string{"receipt"}, handle("not json", errors.New("failed")))
+		assert.Equal(t, []string{"receipt"}, handle(`{"Records":[{"eventName":"ObjectCreated:Put","s3":{"object":{"key":"%zz"}}}]}`, nil))
+	})
+}
+
+func TestGetObjectContentTimeout(t *testing.T) {
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}, map[string]string{"forcePathStyle": "true", "readTimeout": "10ms"})
+
+	start := time.Now()
+	_, err := s.getObjectContent("test", "foo")
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestGetObjectContentValidateMD5(t *testing.T) {
+	getContent := func(t *testing.T, header map[string]string) ([]byte, error) {
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range header {
+				w.Header().Set(k, v)
+			}
+			w.Write([]byte("hello"))
+		}, map[string]string{"forcePathStyle": "true", "validateMD5": "true"})
+
+		return s.getObjectContent("test", "foo")
+	}
+
+	t.Run("return the contents matching the ETag", func(t *testing.T) {
+		data, err := getContent(t, map[string]string{"ETag": "\"5d41402abc4b2a76b9719d911017c592\""})
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("hello"), data)
+	})
+
+	t.Run("return error for corrupted contents", func(t *testing.T) {
+		_, err := getContent(t, map[string]string{"ETag": "\"00000000000000000000000000000000\""})
+		assert.True(t, errors.Is(err, ErrMD5Mismatch))
+	})
+
+	t.Run("skip ETags that aren't MD5 digests", func(t *testing.T) {
+		for _, header := range []map[string]string{
+			{"ETag": "\"00000000000000000000000000000000-2\""},
+			{"ETag": "\"00000000000000000000000000000000\"", "x-amz-server-side-encryption": "aws:kms"},
+			{"ETag": "\"00000000000000000000000000000000\"", "x-amz-server-side-encryption-customer-algorithm": "AES256"},
+		} {
+			data, err := getContent(t, header)
+			assert.Nil(t, err, header)
+			assert.Equal(t, []byte("hello"), data)
+		}
+	})
+}
+
+func TestObjectMD5(t *testing.T) {
+	md5, ok := objectMD5(&s3.GetObjectOutput{ETag: aws.String("\"5D41402ABC4B2A76B9719D911017C592\"")})
+	assert.True(t, ok)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", md5)
+
+	_, ok = objectMD5(&s3.GetObjectOutput{})
+	assert.False(t, ok)
+}
+
+func TestReadWithoutQueue(t *testing.T) {
+	binding := AWSS3{metadata: &s3Metadata{}, logger: logger.NewLogger("test")}
+
+	err := binding.Read(func(r *bindings.ReadResponse) ([]byte, error) {
+		return nil, nil
+	})
+	assert.Equal(t, ErrSQSQueueURLRequired, err)
+}
@@ -0,0 +1,257 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+const (
+	// Continuation token of the next page of a listing, returned in the list response metadata
+	metadataKeyMarker = "marker"
+	// Number of objects and prefixes of the list response
+	metadataKeyNumber = "number"
+
+	// Number of objects listed by default, and largest page returned by S3
+	defaultMaxResults = 1000
+)
+
+// listPayload is the request of the list operation. The names match those of the Azure Blob
+// Storage binding, so that callers can list both the same way: marker is the continuation token.
+type listPayload struct {
+	Marker     string `json:"marker"`
+	Prefix     string `json:"prefix"`
+	MaxResults int32  `json:"maxResults"`
+	// When set, the objects are listed as a hierarchy, see listHierarchyResult
+	Delimiter string `json:"delimiter"`
+	// Cursor returned by a previous list to resume from, see objectstorage.ListCursor. The prefix
+	// and maxResults of the cursor are used when they are not set.
+	Cursor string `json:"cursor"`
+	// When true, the content type and user metadata of each object are read with HeadObject, like
+	// the metadata of listInclude of the Azure Blob Storage binding. S3 doesn't return them in
+	// listings, so this sends one more request per listed object, defaultBatchConcurrency at a time.
+	FetchMetadata bool `json:"fetchMetadata"`
+}
+
+// listObjectEntry is an object of the list response, with the fields of the blobs listed by the
+// Azure Blob Storage binding. The storage class is returned as the tier.
+type listObjectEntry struct {
+	Name         string     `json:"name"`
+	Size         int64      `json:"size"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Tier         string     `json:"tier,omitempty"`
+	ETag         string     `json:"etag"`
+	// Set when fetchMetadata is set
+	ContentType string            `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// listHierarchyResult is the response of list with a delimiter. Prefixes are the common prefixes of
+// the keys directly under the prefix of the listing, ending with the delimiter, and Blobs the
+// objects directly under it. Both count in the number of items of the listing.
+type listHierarchyResult struct {
+	Prefixes []string          `json:"prefixes"`
+	Blobs    []listObjectEntry `json:"blobs"`
+}
+
+// list lists the objects of the bucket with ListObjectsV2, in pages of at most defaultMaxResults
+// until maxResults items were listed. The continuation token is returned in the marker and cursor
+// response metadata, which are empty once the listing is complete.
+func (s *AWSS3) list(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var payload listPayload
+	if len(req.Data) > 0 {
+		err := json.Unmarshal(req.Data, &payload)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing list payload: %w", err)
+		}
+	}
+	if payload.MaxResults < 0 {
+		return nil, fmt.Errorf("invalid maxResults %d, expected a positive number", payload.MaxResults)
+	}
+
+	prefix, token, total := payload.Prefix, payload.Marker, payload.MaxResults
+	if payload.Cursor != "" {
+		if payload.Marker != "" {
+			return nil, fmt.Errorf("marker and cursor can't be used together")
+		}
+		cursor, err := objectstorage.DecodeCursor(payload.Cursor, canonicalResponseProvider, payload.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		prefix, token = cursor.Prefix, cursor.Marker
+		if total == 0 {
+			total = cursor.PageSize
+		}
+	}
+	if total == 0 {
+		total = defaultMaxResults
+	}
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if payload.Delimiter != "" {
+		input.Delimiter = aws.String(payload.Delimiter)
+	}
+
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+
+	result := listHierarchyResult{
+		Prefixes: []string{},
+		Blobs:    []listObjectEntry{},
+	}
+	var listed int32
+	for {
+		if token != "" {
+			input.ContinuationToken = aws.String(token)
+		}
+		pageSize := total - listed
+		if pageSize > defaultMaxResults {
+			pageSize = defaultMaxResults
+		}
+		input.MaxKeys = aws.Int64(int64(pageSize))
+
+		out, err := client.ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing objects: %w", err)
+		}
+		for _, commonPrefix := range out.CommonPrefixes {
+			result.Prefixes = append(result.Prefixes, aws.StringValue(commonPrefix.Prefix))
+		}
+		for _, object := range out.Contents {
+			result.Blobs = append(result.Blobs, listObjectEntry{
+				Name:         aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: object.LastModified,
+				Tier:         aws.StringValue(object.StorageClass),
+				ETag:         aws.StringValue(object.ETag),
+			})
+		}
+		listed += int32(len(out.CommonPrefixes) + len(out.Contents))
+
+		token = ""
+		// Some S3 compatible stores don't return a token for the last page, or return one
+		// with IsTruncated false
+		if !aws.BoolValue(out.IsTruncated) || aws.StringValue(out.NextContinuationToken) == "" {
+			break
+		}
+		token = aws.StringValue(out.NextContinuationToken)
+		if listed >= total {
+			break
+		}
+	}
+
+	if payload.FetchMetadata {
+		if err = s.fetchListMetadata(ctx, client, result.Blobs); err != nil {
+			return nil, err
+		}
+	}
+
+	var data interface{} = result.Blobs
+	if payload.Delimiter != "" {
+		data = result
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling list response for s3: %w", err)
+	}
+
+	metadata := map[string]string{
+		metadataKeyNumber:               strconv.FormatInt(int64(listed), 10),
+		metadataKeyMarker:               token,
+		objectstorage.MetadataKeyCursor: "",
+	}
+	if token != "" {
+		metadata[objectstorage.MetadataKeyCursor], err = objectstorage.EncodeCursor(objectstorage.ListCursor{
+			Provider: canonicalResponseProvider,
+			Marker:   token,
+			Prefix:   prefix,
+			PageSize: total,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     b,
+		Metadata: metadata,
+	}, nil
+}
+
+// fetchListMetadata sets the content type, user metadata and storage class of the listed objects from
+// HeadObject, sharing the retry budget of the operation. Objects deleted since they were listed are
+// kept without metadata.
+func (s *AWSS3) fetchListMetadata(ctx context.Context, client s3iface.S3API, entries []listObjectEntry) error {
+	retryBudget := retryBudgetOption(objectstorage.NewRetryBudget(s.metadata.RetryBudget))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, defaultBatchConcurrency)
+	for i := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(entry *listObjectEntry) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			out, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+				Bucket:              aws.String(s.metadata.Bucket),
+				ExpectedBucketOwner: s.expectedBucketOwner(),
+				Key:                 aws.String(entry.Name),
+			}, retryBudget)
+			if err != nil {
+				if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+					return
+				}
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error getting the metadata of object %s: %w", entry.Name, err)
+				}
+				mu.Unlock()
+
+				return
+			}
+
+			entry.ContentType = aws.StringValue(out.ContentType)
+			if len(out.Metadata) > 0 {
+				entry.Metadata = aws.StringValueMap(out.Metadata)
+			}
+			// HeadObject only returns the storage class of objects that aren't STANDARD
+			if out.StorageClass != nil {
+				entry.Tier = aws.StringValue(out.StorageClass)
+			}
+		}(&entries[i])
+	}
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,190 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeListObjects returns the keys in pages of at most MaxKeys objects. The continuation token is
+// the index of the next key.
+func fakeListObjects(keys []string, inputs *[]s3.ListObjectsV2Input) func(*s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	return func(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+		*inputs = append(*inputs, *input)
+		start, _ := strconv.Atoi(aws.StringValue(input.ContinuationToken))
+		end := start + int(aws.Int64Value(input.MaxKeys))
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		out := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(end < len(keys))}
+		for _, key := range keys[start:end] {
+			out.Contents = append(out.Contents, &s3.Object{Key: aws.String(key), Size: aws.Int64(1), ETag: aws.String("\"etag\""), StorageClass: aws.String("STANDARD")})
+		}
+		if end < len(keys) {
+			out.NextContinuationToken = aws.String(strconv.Itoa(end))
+		}
+
+		return out, nil
+	}
+}
+
+func TestList(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+	list := func(t *testing.T, payload string) ([]listObjectEntry, map[string]string, []s3.ListObjectsV2Input) {
+		var inputs []s3.ListObjectsV2Input
+		binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: &mockS3Client{listObjectsV2: fakeListObjects(keys, &inputs)}}
+		resp, err := binding.list(context.Background(), &bindings.InvokeRequest{Data: []byte(payload)})
+		if !assert.Nil(t, err) {
+			return nil, nil, inputs
+		}
+
+		var entries []listObjectEntry
+		assert.Nil(t, json.Unmarshal(resp.Data, &entries))
+
+		return entries, resp.Metadata, inputs
+	}
+
+	t.Run("list all the objects", func(t *testing.T) {
+		entries, metadata, inputs := list(t, `{"prefix": "logs/"}`)
+		assert.Len(t, entries, 5)
+		assert.Equal(t, listObjectEntry{Name: "a", Size: 1, Tier: "STANDARD", ETag: "\"etag\""}, entries[0])
+		assert.Equal(t, "5", metadata["number"])
+		assert.Equal(t, "", metadata["marker"])
+		assert.Equal(t, "", metadata["cursor"])
+		if assert.Len(t, inputs, 1) {
+			assert.Equal(t, "test", aws.StringValue(inputs[0].Bucket))
+			assert.Equal(t, "logs/", aws.StringValue(inputs[0].Prefix))
+			assert.Equal(t, int64(defaultMaxResults), aws.Int64Value(inputs[0].MaxKeys))
+		}
+	})
+
+	t.Run("return the token of the next page", func(t *testing.T) {
+		entries, metadata, _ := list(t, `{"maxResults": 2}`)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "2", metadata["marker"])
+		assert.NotEmpty(t, metadata["cursor"])
+
+		entries, metadata, inputs := list(t, `{"marker": "2", "maxResults": 2}`)
+		assert.Equal(t, "c", entries[0].Name)
+		assert.Equal(t, "4", metadata["marker"])
+		assert.Equal(t, "2", aws.StringValue(inputs[0].ContinuationToken))
+
+		cursor, err := objectstorage.DecodeCursor(metadata["cursor"], "aws.s3", "")
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), cursor.PageSize)
+		entries, metadata, _ = list(t, `{"cursor": "`+metadata["cursor"]+`"}`)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "e", entries[0].Name)
+		assert.Equal(t, "", metadata["cursor"])
+	})
+
+	t.Run("return error for invalid payloads", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: &mockS3Client{}}
+		for _, payload := range []string{`{"maxResults": -1}`, `{"marker": "2", "cursor": "c"}`, `{"cursor": "c"}`, `[]`} {
+			_, err := binding.list(context.Background(), &bindings.InvokeRequest{Data: []byte(payload)})
+			assert.Error(t, err, payload)
+		}
+	})
+}
+
+func TestListHierarchy(t *testing.T) {
+	var input *s3.ListObjectsV2Input
+	client := &mockS3Client{
+		listObjectsV2: func(in *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+			input = in
+
+			return &s3.ListObjectsV2Output{
+				CommonPrefixes: []*s3.CommonPrefix{{Prefix: aws.String("photos/2021/")}, {Prefix: aws.String("photos/2022/")}},
+				Contents:       []*s3.Object{{Key: aws.String("photos/cover.jpg"), Size: aws.Int64(3)}},
+				IsTruncated:    aws.Bool(false),
+			}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+	resp, err := binding.list(context.Background(), &bindings.InvokeRequest{Data: []byte(`{"prefix": "photos/", "delimiter": "/"}`)})
+	assert.Nil(t, err)
+	assert.Equal(t, "/", aws.StringValue(input.Delimiter))
+
+	var result listHierarchyResult
+	assert.Nil(t, json.Unmarshal(resp.Data, &result))
+	assert.Equal(t, []string{"photos/2021/", "photos/2022/"}, result.Prefixes)
+	assert.Equal(t, []listObjectEntry{{Name: "photos/cover.jpg", Size: 3}}, result.Blobs)
+	assert.Equal(t, "3", resp.Metadata["number"])
+}
+
+func TestListFetchMetadata(t *testing.T) {
+	keys := []string{"a", "b", "c"}
+	var inputs []s3.ListObjectsV2Input
+	var lock sync.Mutex
+	var heads []string
+	client := &mockS3Client{
+		listObjectsV2: fakeListObjects(keys, &inputs),
+		headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+			lock.Lock()
+			heads = append(heads, aws.StringValue(input.Key))
+			lock.Unlock()
+			switch aws.StringValue(input.Key) {
+			case "a":
+				return &s3.HeadObjectOutput{
+					ContentType:  aws.String("text/plain"),
+					Metadata:     map[string]*string{"Owner": aws.String("dapr")},
+					StorageClass: aws.String("GLACIER"),
+				}, nil
+			case "b":
+				return &s3.HeadObjectOutput{ContentType: aws.String("image/png")}, nil
+			default:
+				return nil, awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), http.StatusNotFound, "")
+			}
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+	t.Run("merge the properties read with HeadObject", func(t *testing.T) {
+		resp, err := binding.list(context.Background(), &bindings.InvokeRequest{Data: []byte(`{"fetchMetadata": true}`)})
+		assert.Nil(t, err)
+
+		var entries []listObjectEntry
+		assert.Nil(t, json.Unmarshal(resp.Data, &entries))
+		assert.Equal(t, []listObjectEntry{
+			{Name: "a", Size: 1, Tier: "GLACIER", ETag: "\"etag\"", ContentType: "text/plain", Metadata: map[string]string{"Owner": "dapr"}},
+			{Name: "b", Size: 1, Tier: "STANDARD", ETag: "\"etag\"", ContentType: "image/png"},
+			{Name: "c", Size: 1, Tier: "STANDARD", ETag: "\"etag\""},
+		}, entries)
+		assert.ElementsMatch(t, keys, heads)
+	})
+
+	t.Run("don't send HeadObject requests without fetchMetadata", func(t *testing.T) {
+		heads = nil
+		_, err := binding.list(context.Background(), &bindings.InvokeRequest{})
+		assert.Nil(t, err)
+		assert.Empty(t, heads)
+	})
+
+	t.Run("return the errors of HeadObject", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: &mockS3Client{
+			listObjectsV2: fakeListObjects(keys, &inputs),
+			headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+				return nil, awserr.NewRequestFailure(awserr.New("Forbidden", "Forbidden", nil), http.StatusForbidden, "")
+			},
+		}}
+		_, err := binding.list(context.Background(), &bindings.InvokeRequest{Data: []byte(`{"fetchMetadata": true}`)})
+		assert.Error(t, err)
+	})
+}
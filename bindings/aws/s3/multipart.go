@@ -0,0 +1,161 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// The ETag of an object created with a multipart upload is not the MD5 digest of its data but the
+// MD5 digest of the concatenated binary MD5 digests of its parts, followed by "-<number of parts>".
+// create returns the ETags of the parts of multipart uploads, so that callers can store them and
+// later check with verifyMultipartETag that the object still is the one they uploaded.
+
+const verifyMultipartETagOperation bindings.OperationKind = "verifyMultipartETag"
+
+type verifyMultipartETagPayload struct {
+	// ETags of the parts in part number order, as returned by create
+	PartETags []string `json:"partETags"`
+}
+
+type verifyMultipartETagResponse struct {
+	ETag         string `json:"etag"`
+	ExpectedETag string `json:"expectedETag"`
+	Match        bool   `json:"match"`
+}
+
+// partETags records the ETags returned by the UploadPart requests of an upload. The uploader sends
+// the parts concurrently, so the ETags are sorted by part number when read.
+type partETags struct {
+	lock  sync.Mutex
+	parts map[int64]string
+}
+
+func newPartETags() *partETags {
+	return &partETags{parts: map[int64]string{}}
+}
+
+// option returns the request option recording the ETags of the successful UploadPart requests.
+func (p *partETags) option() request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			if r.Operation.Name != "UploadPart" || r.Error != nil {
+				return
+			}
+			input, ok := r.Params.(*s3.UploadPartInput)
+			if !ok {
+				return
+			}
+			output, ok := r.Data.(*s3.UploadPartOutput)
+			if !ok {
+				return
+			}
+
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.parts[aws.Int64Value(input.PartNumber)] = aws.StringValue(output.ETag)
+		})
+	}
+}
+
+// list returns the recorded ETags in part number order.
+func (p *partETags) list() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	numbers := make([]int64, 0, len(p.parts))
+	for n := range p.parts {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	etags := make([]string, len(numbers))
+	for i, n := range numbers {
+		etags[i] = p.parts[n]
+	}
+
+	return etags
+}
+
+// multipartETag computes the ETag of an object uploaded with the parts whose ETags are given. Part
+// ETags are the hex encoded MD5 digests of the parts, optionally quoted.
+func multipartETag(partETags []string) (string, error) {
+	if len(partETags) == 0 {
+		return "", fmt.Errorf("at least one part ETag is required")
+	}
+
+	h := md5.New() //nolint:gosec
+	for _, etag := range partETags {
+		digest, err := hex.DecodeString(strings.Trim(etag, "\""))
+		if err != nil || len(digest) != md5.Size {
+			return "", fmt.Errorf("invalid part ETag %q, expected a hex encoded MD5 digest", etag)
+		}
+		h.Write(digest)
+	}
+
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(partETags)), nil
+}
+
+// verifyMultipartETag compares the ETag of the object with the ETag computed from the part ETags
+// recorded when the object was uploaded. The comparison doesn't apply to objects encrypted with
+// SSE-C or SSE-KMS, whose ETags aren't MD5 digests.
+func (s *AWSS3) verifyMultipartETag(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKeyKey]
+	if key == "" {
+		return nil, fmt.Errorf("%s is a required attribute", metadataKeyKey)
+	}
+
+	var payload verifyMultipartETagPayload
+	err := json.Unmarshal(req.Data, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing verifyMultipartETag payload: %w", err)
+	}
+	expected, err := multipartETag(payload.PartETags)
+	if err != nil {
+		return nil, err
+	}
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	out, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting object %s: %w", key, err)
+	}
+
+	etag := strings.Trim(aws.StringValue(out.ETag), "\"")
+	b, err := json.Marshal(verifyMultipartETagResponse{
+		ETag:         etag,
+		ExpectedETag: expected,
+		Match:        etag == expected,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling verifyMultipartETag response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
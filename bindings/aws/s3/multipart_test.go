@@ -0,0 +1,135 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func md5Hex(data []byte) string {
+	digest := md5.Sum(data) //nolint:gosec
+
+	return hex.EncodeToString(digest[:])
+}
+
+func TestMultipartETag(t *testing.T) {
+	t.Run("compute the ETag of the parts", func(t *testing.T) {
+		a, b := md5Hex([]byte("a")), md5Hex([]byte("b"))
+		digestA, _ := hex.DecodeString(a)
+		digestB, _ := hex.DecodeString(b)
+
+		etag, err := multipartETag([]string{"\"" + a + "\"", b})
+		assert.Nil(t, err)
+		assert.Equal(t, md5Hex(append(digestA, digestB...))+"-2", etag)
+	})
+
+	t.Run("return error without parts", func(t *testing.T) {
+		_, err := multipartETag(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for invalid part ETag", func(t *testing.T) {
+		_, err := multipartETag([]string{"not-a-digest"})
+		assert.Error(t, err)
+	})
+}
+
+func TestMultipartCreate(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), int(s3manager.MinUploadPartSize)+1)
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		_, initiate := r.URL.Query()["uploads"]
+		switch {
+		case r.Method == http.MethodPost && initiate:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", "\""+md5Hex(body)+"\"")
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"composite-2"</ETag></CompleteMultipartUploadResult>`)
+		}
+	}, map[string]string{"partSize": fmt.Sprint(s3manager.MinUploadPartSize), "autoScalePartSize": "false"})
+
+	r := bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      data,
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+	}
+	resp, err := s.Invoke(&r)
+	assert.Nil(t, err)
+
+	var created createResponse
+	assert.Nil(t, json.Unmarshal(resp.Data, &created))
+	assert.Equal(t, "\"composite-2\"", created.ETag)
+	assert.Equal(t, []string{
+		"\"" + md5Hex(data[:s3manager.MinUploadPartSize]) + "\"",
+		"\"" + md5Hex(data[s3manager.MinUploadPartSize:]) + "\"",
+	}, created.PartETags)
+}
+
+func TestVerifyMultipartETag(t *testing.T) {
+	parts := []string{md5Hex([]byte("a")), md5Hex([]byte("b"))}
+	expected, _ := multipartETag(parts)
+
+	t.Run("match the ETag of the object", func(t *testing.T) {
+		client := &mockS3Client{
+			headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+				return &s3.HeadObjectOutput{ETag: aws.String("\"" + expected + "\"")}, nil
+			},
+		}
+		binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+		payload, _ := json.Marshal(verifyMultipartETagPayload{PartETags: parts})
+		resp, err := binding.verifyMultipartETag(context.Background(), &bindings.InvokeRequest{
+			Data:     payload,
+			Metadata: map[string]string{"key": "foo"},
+		})
+		assert.Nil(t, err)
+
+		var verified verifyMultipartETagResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &verified))
+		assert.Equal(t, verifyMultipartETagResponse{ETag: expected, ExpectedETag: expected, Match: true}, verified)
+	})
+
+	t.Run("report a different ETag", func(t *testing.T) {
+		client := &mockS3Client{
+			headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+				return &s3.HeadObjectOutput{ETag: aws.String("\"other-2\"")}, nil
+			},
+		}
+		binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+		payload, _ := json.Marshal(verifyMultipartETagPayload{PartETags: parts})
+		resp, err := binding.verifyMultipartETag(context.Background(), &bindings.InvokeRequest{
+			Data:     payload,
+			Metadata: map[string]string{"key": "foo"},
+		})
+		assert.Nil(t, err)
+
+		var verified verifyMultipartETagResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &verified))
+		assert.False(t, verified.Match)
+	})
+
+	t.Run("return error if key is missing", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: &mockS3Client{}}
+		_, err := binding.verifyMultipartETag(context.Background(), &bindings.InvokeRequest{Data: []byte(`{}`)})
+		assert.Error(t, err)
+	})
+}
@@ -7,21 +7,119 @@ package s3
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	aws_auth "github.com/dapr/components-contrib/authentication/aws"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
 	"github.com/dapr/kit/logger"
-	"github.com/google/uuid"
 )
 
+const (
+	metadataKeyKey = "key"
+	// Content type of the object, also used to expand the {ext} token of keyTemplate
+	metadataKeyContentType = "contentType"
+	// Content headers of the object written by create
+	metadataKeyContentEncoding = "contentEncoding"
+	metadataKeyCacheControl    = "cacheControl"
+	// Maximum size of the names and values of the user metadata of an object
+	maxMetadataSize = 2 * 1024
+	// ETag and last modified time of the object returned by preview, in the HTTP date format, with
+	// its content type and objectstorage.MetadataKeyContentLength
+	metadataKeyETag         = "etag"
+	metadataKeyLastModified = "lastModified"
+	// Overrides the addressing style of forcePathStyle for a single request. When true the bucket is
+	// addressed as endpoint/bucket (path-style), when false as bucket.endpoint (virtual-hosted style).
+	metadataKeyForcePathStyle = "forcePathStyle"
+	// Maximum number of objects fetched concurrently by batch operations
+	defaultBatchConcurrency = 16
+	// Defines if the multipart part size is increased so that large objects fit in the 10,000 parts limit
+	metadataKeyAutoScalePartSize = "autoScalePartSize"
+	// Largest part size accepted by S3 for multipart uploads
+	maxUploadPartSize = 5 * 1024 * 1024 * 1024
+
+	// Upload ID of the abortMultipartUpload operation
+	metadataKeyUploadID = "uploadId"
+	// Conditions of the create operation, the object is only written if its ETag matches, or
+	// doesn't match ("*" to only create new objects)
+	metadataKeyIfMatch     = "ifMatch"
+	metadataKeyIfNoneMatch = "ifNoneMatch"
+	// Storage class of the object written by create, e.g. STANDARD_IA or GLACIER. The default of the
+	// bucket, usually STANDARD, applies if not set.
+	metadataKeyStorageClass = "storageClass"
+	// Defines if create only validates the request and the access to the bucket, without uploading
+	metadataKeyValidateOnly = "validateOnly"
+	// Provider of objectstorage.CanonicalResponse and of the metrics
+	canonicalResponseProvider = "aws.s3"
+
+	batchHeadOperation            bindings.OperationKind = "batchHead"
+	listBucketsOperation          bindings.OperationKind = "listBuckets"
+	listMultipartUploadsOperation bindings.OperationKind = "listMultipartUploads"
+	abortMultipartUploadOperation bindings.OperationKind = "abortMultipartUpload"
+	previewOperation              bindings.OperationKind = "preview"
+	touchOperation                bindings.OperationKind = "touch"
+)
+
+// createRequestKeys are the keys of the create request metadata that aren't stored as user metadata
+// of the object. blobName, offset, count and data are keys of the Azure binding, so that requests
+// written for it don't leave them on the objects.
+var createRequestKeys = map[string]bool{
+	metadataKeyKey:                             true,
+	metadataKeyContentType:                     true,
+	metadataKeyContentEncoding:                 true,
+	metadataKeyCacheControl:                    true,
+	metadataKeyForcePathStyle:                  true,
+	metadataKeyIfMatch:                         true,
+	metadataKeyIfNoneMatch:                     true,
+	metadataKeyValidateOnly:                    true,
+	metadataKeyStorageClass:                    true,
+	objectstorage.MetadataKeyVerifyTier:        true,
+	metadataKeyExpectedSize:                    true,
+	objectstorage.MetadataKeyReturnSignedURL:   true,
+	objectstorage.MetadataKeySignedURLExpiry:   true,
+	objectstorage.MetadataKeyGenerateThumbnail: true,
+	objectstorage.MetadataKeyThumbnailSize:     true,
+	"blobName":                                 true,
+	objectstorage.MetadataKeyOffset:            true,
+	"count":                                    true,
+	"data":                                     true,
+}
+
+// ErrPreconditionFailed is returned when the conditions of a write are not met.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
 // AWSS3 is a binding for an AWS S3 storage bucket
 type AWSS3 struct {
-	metadata *s3Metadata
-	uploader *s3manager.Uploader
-	logger   logger.Logger
+	metadata              *s3Metadata
+	client                s3iface.S3API
+	uploader              *s3manager.Uploader
+	pathStyleUploader     *s3manager.Uploader
+	virtualHostedUploader *s3manager.Uploader
+	uploadSessions        *objectstorage.UploadSessions
+	sqsClient             sqsiface.SQSAPI
+	metrics               bindings.Metrics
+	logger                logger.Logger
 }
 
 type s3Metadata struct {
@@ -31,11 +129,144 @@ type s3Metadata struct {
 	SecretKey    string `json:"secretKey"`
 	SessionToken string `json:"sessionToken"`
 	Bucket       string `json:"bucket"`
+	// When true, the bucket is addressed as endpoint/bucket instead of bucket.endpoint, as required
+	// by most S3-compatible stores such as MinIO and Ceph
+	ForcePathStyle bool `json:"forcePathStyle,string"`
+	// When true, the requests are sent over plain HTTP to endpoints set without a scheme
+	DisableSSL bool `json:"disableSSL,string"`
+	// ARN of an IAM role assumed with the credentials above, or those of the environment, e.g. to
+	// access a bucket of another account
+	AssumeRoleArn string `json:"assumeRoleArn"`
+	// Name of the sessions of the assumed role, generated by the SDK if not set
+	SessionName string `json:"sessionName"`
+	// Server-side encryption of the uploaded objects, AES256 (SSE-S3) or aws:kms (SSE-KMS). The
+	// default encryption of the bucket applies if not set.
+	Encryption string `json:"encryption"`
+	// ID or ARN of the KMS key of aws:kms encryption, the AWS managed key of S3 is used if not set
+	KMSKeyID string `json:"kmsKeyId"`
+	// When true, objects encrypted with SSE-KMS use an S3 Bucket Key, which reduces the number of
+	// requests made to AWS KMS.
+	BucketKeyEnabled bool `json:"bucketKeyEnabled,string"`
+	// Size in bytes of the parts of multipart uploads, defaults to 5 MiB
+	PartSize int64 `json:"partSize,string"`
+	// Parsed from metadataKeyAutoScalePartSize, defaults to true
+	AutoScalePartSize bool `json:"-"`
+	// Template for the keys of objects created without a key, see objectstorage.GenerateKey
+	KeyTemplate string `json:"keyTemplate"`
+	// When true, the extension for the contentType of the request is appended to keys that don't have one
+	AppendExtensionFromContentType bool `json:"appendExtensionFromContentType,string"`
+	// When true, generated keys are prefixed with a hash of the key, see objectstorage.HashPrefix.
+	// Keys set in the request are not changed.
+	HashPrefix bool `json:"hashPrefix,string"`
+	// Number of hex characters of the hash prefix, defaults to objectstorage.DefaultHashPrefixLength
+	HashPrefixLength int `json:"hashPrefixLength,string"`
+	// When true, Init sends a HeadBucket request and fails if the bucket can't be accessed
+	ValidateOnInit bool `json:"validateOnInit,string"`
+	// When true, the user metadata of objects over maxMetadataSize is truncated instead of rejected,
+	// see objectstorage.FitMetadata
+	TruncateMetadata bool `json:"truncateMetadata,string"`
+	// When true, the responses report the number of requests sent to the service in
+	// objectstorage.MetadataKeyBilledRequests
+	ReturnBilledRequests bool `json:"returnBilledRequests,string"`
+	// URL of the SQS queue receiving the event notifications of the bucket, required by Read
+	SQSQueueURL string `json:"sqsQueueUrl"`
+	// When true, Read delivers the contents of the created objects instead of the events
+	FetchContent bool `json:"fetchContent,string"`
+	// When true, the contents delivered with fetchContent are checked against the MD5 digest of the
+	// object, see getObjectContent
+	ValidateMD5 bool `json:"validateMD5,string"`
+	// Maximum number of retries of all the requests of a batch operation, unlimited if 0
+	RetryBudget int `json:"retryBudget,string"`
+	// Parsed from objectstorage.MetadataKeyOperationTimeout, MetadataKeyReadTimeout and MetadataKeyWriteTimeout
+	Timeouts objectstorage.Timeouts `json:"-"`
+	// When true, the conditions of create are checked with a HeadObject request before the upload, for
+	// endpoints that don't support conditional writes. The check is not atomic: a concurrent write
+	// between the check and the upload is overwritten.
+	EmulateConditionalWrites bool `json:"emulateConditionalWrites,string"`
+	// Parsed from objectstorage.MetadataKeyAllowedWriteWindow, nil if writes are always allowed
+	WriteWindow *objectstorage.WriteWindow `json:"-"`
+	// Parsed from objectstorage.MetadataKeyProgressLogInterval
+	ProgressLogInterval time.Duration `json:"-"`
+	// Parsed from objectstorage.MetadataKeyUploadSessionTTL
+	UploadSessionTTL time.Duration `json:"-"`
+	// Parsed from objectstorage.MetadataKeyAllowEmpty, defaults to true
+	AllowEmpty bool `json:"-"`
+	// When true, create returns an objectstorage.CanonicalResponse instead of createResponse
+	CanonicalResponse bool `json:"canonicalResponse,string"`
+	// When true, keys are rejected if they could resolve outside of their path, see objectstorage.ValidateKey
+	StrictKeyValidation bool `json:"strictKeyValidation,string"`
+	// ID of the AWS account expected to own the bucket. Requests fail with 403 Forbidden when the
+	// bucket is owned by another account, e.g. after the bucket was deleted and its name claimed by
+	// someone else, so data is never written to or read from a bucket outside of the account.
+	ExpectedBucketOwner string `json:"expectedBucketOwner"`
+}
+
+type createResponse struct {
+	Bucket    string  `json:"bucket"`
+	Key       string  `json:"key"`
+	Location  string  `json:"location"`
+	VersionID *string `json:"versionID,omitempty"`
+	// Hex encoded SHA-256 digest of the uploaded data
+	SHA256 string `json:"sha256"`
+	ETag   string `json:"etag,omitempty"`
+	// ETags of the parts of multipart uploads in part number order, see verifyMultipartETag
+	PartETags []string `json:"partETags,omitempty"`
+	// URL of the thumbnail object, set when generateThumbnail is set for an image
+	ThumbnailURL string `json:"thumbnailURL,omitempty"`
+	// Set when returnSignedURL is set
+	*objectstorage.ObjectURLs
+}
+
+type bucketItem struct {
+	Name         string     `json:"name"`
+	CreationDate *time.Time `json:"creationDate,omitempty"`
+}
+
+type listMultipartUploadsPayload struct {
+	Prefix string `json:"prefix"`
+}
+
+type multipartUploadItem struct {
+	Key       string     `json:"key"`
+	UploadID  string     `json:"uploadId"`
+	Initiated *time.Time `json:"initiated,omitempty"`
+}
+
+type validateOnlyResponse struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+}
+
+type touchResponse struct {
+	ETag         string     `json:"etag"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	VersionID    *string    `json:"versionID,omitempty"`
+}
+
+type batchHeadResult struct {
+	Size         int64      `json:"size"`
+	ContentType  string     `json:"contentType,omitempty"`
+	ETag         string     `json:"etag,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	// Cross-region replication status, one of PENDING, COMPLETE, FAILED or REPLICA
+	ReplicationStatus string `json:"replicationStatus,omitempty"`
+	Error             string `json:"error,omitempty"`
 }
 
 // NewAWSS3 returns a new AWSS3 instance
 func NewAWSS3(logger logger.Logger) *AWSS3 {
-	return &AWSS3{logger: logger}
+	return NewAWSS3WithMetrics(logger, bindings.NoopMetrics)
+}
+
+// NewAWSS3WithMetrics returns a new AWSS3 instance reporting the metrics of its operations to
+// metrics.
+func NewAWSS3WithMetrics(logger logger.Logger, metrics bindings.Metrics) *AWSS3 {
+	if metrics == nil {
+		metrics = bindings.NoopMetrics
+	}
+
+	return &AWSS3{metrics: metrics, logger: logger}
 }
 
 // Init does metadata parsing and connection creation
@@ -44,37 +275,810 @@ func (s *AWSS3) Init(metadata bindings.Metadata) error {
 	if err != nil {
 		return err
 	}
-	uploader, err := s.getClient(m)
+	sess, err := s.getSession(m)
 	if err != nil {
 		return err
 	}
+	// The send handlers run on every try of the requests of the clients of the session
+	sess.Handlers.Send.PushFront(func(r *request.Request) {
+		objectstorage.CountRequest(r.Context())
+	})
 	s.metadata = m
-	s.uploader = uploader
+	s.uploadSessions = objectstorage.NewUploadSessions(m.UploadSessionTTL)
+	s.client = s3.New(sess)
+	s.uploader = s3manager.NewUploader(sess)
+	s.pathStyleUploader = s3manager.NewUploaderWithClient(s3.New(sess, aws.NewConfig().WithS3ForcePathStyle(true)))
+	s.virtualHostedUploader = s3manager.NewUploaderWithClient(s3.New(sess, aws.NewConfig().WithS3ForcePathStyle(false)))
+	if m.SQSQueueURL != "" {
+		s.sqsClient = sqs.New(sess)
+	}
+
+	if m.ValidateOnInit {
+		_, err = s.client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(m.Bucket), ExpectedBucketOwner: s.expectedBucketOwner()})
+		if err != nil {
+			return fmt.Errorf("error validating access to bucket %s: %w", m.Bucket, err)
+		}
+	}
 
 	return nil
 }
 
 func (s *AWSS3) Operations() []bindings.OperationKind {
-	return []bindings.OperationKind{bindings.CreateOperation}
+	return []bindings.OperationKind{
+		bindings.CreateOperation,
+		bindings.ListOperation,
+		batchHeadOperation,
+		listBucketsOperation,
+		listMultipartUploadsOperation,
+		abortMultipartUploadOperation,
+		previewOperation,
+		touchOperation,
+		verifyMultipartETagOperation,
+		initUploadOperation,
+		uploadChunkOperation,
+		finishUploadOperation,
+		getUploadStatusOperation,
+	}
 }
 
+// Invoke runs the operation of the request without a parent context, see InvokeWithContext.
 func (s *AWSS3) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	return s.InvokeWithContext(context.Background(), req)
+}
+
+// InvokeWithContext runs the operation of the request and reports its metrics. The requests to the
+// service are cancelled with ctx. The request metadata keys with objectstorage.EchoMetadataPrefix
+// are copied to the metadata of the response.
+func (s *AWSS3) InvokeWithContext(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	start := time.Now()
+	operation, written := req.Operation, int64(len(req.Data))
+	echo := objectstorage.ExtractEchoMetadata(req.Metadata)
+	var counter *objectstorage.RequestCounter
+	if s.metadata.ReturnBilledRequests {
+		ctx, counter = objectstorage.WithRequestCounter(ctx)
+	}
+	resp, err := s.invoke(ctx, req)
+	objectstorage.ObserveOperation(s.metrics, canonicalResponseProvider, operation, start, transferredBytes(operation, written, resp), err)
+	if err != nil {
+		return nil, err
+	}
+
+	return objectstorage.WithEchoMetadata(objectstorage.WithBilledRequests(resp, counter), echo), nil
+}
+
+// transferredBytes returns the size of the data written or read by an operation for the metrics, or
+// -1 for the operations that don't transfer object data. written is the size of the request data.
+func transferredBytes(operation bindings.OperationKind, written int64, resp *bindings.InvokeResponse) int64 {
+	switch operation {
+	case bindings.CreateOperation, uploadChunkOperation:
+		return written
+	case previewOperation:
+		if resp == nil {
+			return 0
+		}
+
+		return int64(len(resp.Data))
+	default:
+		return -1
+	}
+}
+
+// isWriteOperation returns true for the operations that write objects, which are rejected outside
+// of allowedWriteWindow.
+func isWriteOperation(operation bindings.OperationKind) bool {
+	switch operation {
+	case bindings.CreateOperation, abortMultipartUploadOperation, touchOperation, initUploadOperation,
+		uploadChunkOperation, finishUploadOperation:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *AWSS3) invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if err := s.validateKeys(req.Metadata[metadataKeyKey]); err != nil {
+		return nil, err
+	}
+	if isWriteOperation(req.Operation) {
+		if err := s.metadata.WriteWindow.Check(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	switch req.Operation {
+	case bindings.CreateOperation:
+		return s.create(ctx, req)
+	case bindings.ListOperation:
+		return s.list(ctx, req)
+	case batchHeadOperation:
+		return s.batchHead(ctx, req)
+	case listBucketsOperation:
+		return s.listBuckets(ctx, req)
+	case listMultipartUploadsOperation:
+		return s.listMultipartUploads(ctx, req)
+	case abortMultipartUploadOperation:
+		return s.abortMultipartUpload(ctx, req)
+	case previewOperation:
+		return s.preview(ctx, req)
+	case touchOperation:
+		return s.touch(ctx, req)
+	case verifyMultipartETagOperation:
+		return s.verifyMultipartETag(ctx, req)
+	case initUploadOperation:
+		return s.initUpload(ctx, req)
+	case uploadChunkOperation:
+		return s.uploadChunk(ctx, req)
+	case finishUploadOperation:
+		return s.finishUpload(ctx, req)
+	case getUploadStatusOperation:
+		return s.getUploadStatus(ctx, req)
+	default:
+		return nil, fmt.Errorf("unsupported operation %s", req.Operation)
+	}
+}
+
+// validateKeys validates the keys of a request when strictKeyValidation is enabled. Empty keys are ignored.
+func (s *AWSS3) validateKeys(keys ...string) error {
+	if !s.metadata.StrictKeyValidation {
+		return nil
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := objectstorage.ValidateKey(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectKey returns the key of the object written by the request, generated from keyTemplate if
+// the request has none.
+func (s *AWSS3) objectKey(req *bindings.InvokeRequest) string {
 	key := ""
-	if val, ok := req.Metadata["key"]; ok && val != "" {
+	generated := false
+	if val, ok := req.Metadata[metadataKeyKey]; ok && val != "" {
 		key = val
 	} else {
-		key = uuid.New().String()
+		key = objectstorage.GenerateKey(s.metadata.KeyTemplate, req.Metadata[metadataKeyContentType], time.Now())
+		generated = true
+	}
+	if s.metadata.AppendExtensionFromContentType {
+		key = objectstorage.AppendExtension(key, req.Metadata[metadataKeyContentType])
+	}
+	if generated {
+		if s.metadata.HashPrefix {
+			key = objectstorage.HashPrefix(key, s.metadata.HashPrefixLength)
+		}
 		s.logger.Debugf("key not found. generating key %s", key)
 	}
 
-	r := bytes.NewReader(req.Data)
-	_, err := s.uploader.Upload(&s3manager.UploadInput{
-		Bucket: aws.String(s.metadata.Bucket),
-		Key:    aws.String(key),
-		Body:   r,
+	return key
+}
+
+func (s *AWSS3) create(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if len(req.Data) == 0 && !s.metadata.AllowEmpty {
+		return nil, objectstorage.ErrEmptyData
+	}
+	key := s.objectKey(req)
+
+	client, uploader, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	partSize, err := s.partSizeFor(int64(len(req.Data)))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+
+	validateOnly, err := req.GetMetadataAsBool(metadataKeyValidateOnly)
+	if err != nil {
+		return nil, err
+	}
+	signedURLExpiry, err := objectstorage.ParseSignedURLExpiry(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	thumbnailSize, err := objectstorage.ParseThumbnailSize(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	storageClass, err := parseStorageClass(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	verifyTier, err := req.GetMetadataAsBool(objectstorage.MetadataKeyVerifyTier)
+	if err != nil {
+		return nil, err
+	}
+	if verifyTier && storageClass == nil {
+		return nil, fmt.Errorf("%s requires %s", objectstorage.MetadataKeyVerifyTier, metadataKeyStorageClass)
+	}
+	userMetadata, err := s.userMetadata(key, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if validateOnly {
+		return s.validateCreate(ctx, client, key, int64(len(req.Data)))
+	}
+
+	// Generated before the upload, so that a corrupted image fails the request without writing the object
+	var thumbnail []byte
+	if thumbnailSize != nil {
+		thumbnail, err = objectstorage.Thumbnail(req.Data, req.Metadata[metadataKeyContentType], *thumbnailSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ifMatch, ifNoneMatch := req.Metadata[metadataKeyIfMatch], req.Metadata[metadataKeyIfNoneMatch]
+	var requestOptions []request.Option
+	if ifMatch != "" || ifNoneMatch != "" {
+		if s.metadata.EmulateConditionalWrites {
+			if err = s.checkWriteConditions(ctx, client, key, ifMatch, ifNoneMatch); err != nil {
+				return nil, err
+			}
+		} else {
+			requestOptions = append(requestOptions, conditionalWriteOption(ifMatch, ifNoneMatch))
+		}
+	}
+
+	parts := newPartETags()
+	requestOptions = append(requestOptions, parts.option())
+
+	// Not seekable, so the uploader reads the body once and the digest covers each byte once
+	progress := objectstorage.NewProgressLogger(s.logger, "upload", key, int64(len(req.Data)), s.metadata.ProgressLogInterval)
+	r := objectstorage.NewHashingReader(objectstorage.NewProgressReader(bytes.NewReader(req.Data), progress))
+	out, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:               aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner:  s.expectedBucketOwner(),
+		Key:                  aws.String(key),
+		Body:                 r,
+		BucketKeyEnabled:     s.bucketKeyEnabled(),
+		ServerSideEncryption: s.serverSideEncryption(),
+		SSEKMSKeyId:          s.kmsKeyID(),
+		StorageClass:         storageClass,
+		ContentType:          optionalString(req.Metadata[metadataKeyContentType]),
+		ContentEncoding:      optionalString(req.Metadata[metadataKeyContentEncoding]),
+		CacheControl:         optionalString(req.Metadata[metadataKeyCacheControl]),
+		Metadata:             userMetadata,
+	}, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+	}, s3manager.WithUploaderRequestOptions(requestOptions...))
+	if err != nil {
+		if isPreconditionFailedError(err) {
+			return nil, ErrPreconditionFailed
+		}
+
+		return nil, err
+	}
+
+	created := createResponse{
+		Bucket:    s.metadata.Bucket,
+		Key:       key,
+		Location:  out.Location,
+		VersionID: out.VersionID,
+		SHA256:    r.SHA256(),
+		ETag:      aws.StringValue(out.ETag),
+	}
+	if out.UploadID != "" {
+		created.PartETags = parts.list()
+	}
+	var metadata map[string]string
+	if verifyTier {
+		status, err := s.verifyStorageClass(ctx, client, key, aws.StringValue(storageClass))
+		if err != nil {
+			return nil, err
+		}
+		metadata = map[string]string{objectstorage.MetadataKeyTierStatus: status}
+	}
+	if thumbnail != nil {
+		created.ThumbnailURL, err = s.uploadThumbnail(ctx, uploader, key, thumbnail)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if signedURLExpiry > 0 {
+		created.ObjectURLs = s.objectURLs(client, key, out.Location, signedURLExpiry)
+	}
+	var resp interface{} = created
+	if s.metadata.CanonicalResponse {
+		resp = objectstorage.CanonicalResponse{
+			Provider:     canonicalResponseProvider,
+			Bucket:       s.metadata.Bucket,
+			Key:          key,
+			URL:          out.Location,
+			ETag:         aws.StringValue(out.ETag),
+			VersionID:    aws.StringValue(out.VersionID),
+			Size:         int64(len(req.Data)),
+			ContentType:  req.Metadata[metadataKeyContentType],
+			ThumbnailURL: created.ThumbnailURL,
+			ObjectURLs:   created.ObjectURLs,
+		}
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling create response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     b,
+		Metadata: metadata,
+	}, nil
+}
+
+// verifyStorageClass reads the storage class of the object back with HeadObject, which doesn't
+// return it for STANDARD objects. Uploads are written in their storage class, so it is never pending.
+func (s *AWSS3) verifyStorageClass(ctx context.Context, client s3iface.S3API, key, storageClass string) (string, error) {
+	out, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error verifying storage class of object %s: %w", key, err)
+	}
+	effective := aws.StringValue(out.StorageClass)
+	if effective == "" {
+		effective = s3.StorageClassStandard
+	}
+
+	return objectstorage.VerifyTier(storageClass, effective, false)
+}
+
+// userMetadata returns the user metadata of the object written by create, the request metadata
+// without createRequestKeys. It checks that the metadata fits in maxMetadataSize, or truncates it
+// with truncateMetadata, so that the request isn't rejected by the service.
+func (s *AWSS3) userMetadata(key string, metadata map[string]string) (map[string]*string, error) {
+	user := map[string]string{}
+	for k, v := range metadata {
+		if !createRequestKeys[k] {
+			user[k] = v
+		}
+	}
+	affected, err := objectstorage.FitMetadata(user, maxMetadataSize, s.metadata.TruncateMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of object %s: %w", key, err)
+	}
+	if len(affected) > 0 {
+		s.logger.Warnf("metadata of object %s truncated to %d bytes, changed or removed: %s", key, maxMetadataSize, strings.Join(affected, ", "))
+	}
+	if len(user) == 0 {
+		return nil, nil
+	}
+
+	return aws.StringMap(user), nil
+}
+
+// uploadThumbnail writes the thumbnail of key next to it and returns its URL.
+func (s *AWSS3) uploadThumbnail(ctx context.Context, uploader *s3manager.Uploader, key string, thumbnail []byte) (string, error) {
+	thumbnailKey := objectstorage.ThumbnailKey(key)
+	out, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:               aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner:  s.expectedBucketOwner(),
+		Key:                  aws.String(thumbnailKey),
+		Body:                 bytes.NewReader(thumbnail),
+		ContentType:          aws.String(objectstorage.ThumbnailContentType),
+		BucketKeyEnabled:     s.bucketKeyEnabled(),
+		ServerSideEncryption: s.serverSideEncryption(),
+		SSEKMSKeyId:          s.kmsKeyID(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading thumbnail %s of s3 object %s: %w", thumbnailKey, key, err)
+	}
+
+	return out.Location, nil
+}
+
+// validateCreate checks that the credentials can access the bucket with the addressing style of the
+// upload, after the request was validated, without transferring the data. Access is checked with
+// HeadBucket, so write permissions aren't verified.
+func (s *AWSS3) validateCreate(ctx context.Context, client s3iface.S3API, key string, size int64) (*bindings.InvokeResponse, error) {
+	_, err := client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.metadata.Bucket), ExpectedBucketOwner: s.expectedBucketOwner()})
+	if err != nil {
+		return nil, fmt.Errorf("error validating access to bucket %s: %w", s.metadata.Bucket, err)
+	}
+
+	b, err := json.Marshal(validateOnlyResponse{
+		Bucket: s.metadata.Bucket,
+		Key:    key,
+		Size:   size,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling create response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
 
-	return nil, err
+// conditionalWriteOption sets the If-Match and If-None-Match headers on the requests that write the
+// object: PutObject for single part uploads and CompleteMultipartUpload for multipart uploads.
+func conditionalWriteOption(ifMatch, ifNoneMatch string) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Build.PushBack(func(r *request.Request) {
+			if r.Operation.Name != "PutObject" && r.Operation.Name != "CompleteMultipartUpload" {
+				return
+			}
+			if ifMatch != "" {
+				r.HTTPRequest.Header.Set("If-Match", ifMatch)
+			}
+			if ifNoneMatch != "" {
+				r.HTTPRequest.Header.Set("If-None-Match", ifNoneMatch)
+			}
+		})
+	}
+}
+
+// checkWriteConditions compares the ETag of the object with the conditions of a write before the
+// upload, for endpoints that don't support conditional writes. client is the client of the uploader,
+// so the object is addressed in the same style as the upload.
+func (s *AWSS3) checkWriteConditions(ctx context.Context, client s3iface.S3API, key, ifMatch, ifNoneMatch string) error {
+	out, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+	})
+	exists := true
+	if err != nil {
+		if reqErr, ok := err.(awserr.RequestFailure); !ok || reqErr.StatusCode() != http.StatusNotFound {
+			return fmt.Errorf("error checking the write conditions of %s: %w", key, err)
+		}
+		exists = false
+	}
+
+	etag := ""
+	if exists {
+		etag = aws.StringValue(out.ETag)
+	}
+	if ifMatch != "" && (!exists || (ifMatch != "*" && ifMatch != etag)) {
+		return ErrPreconditionFailed
+	}
+	if ifNoneMatch != "" && exists && (ifNoneMatch == "*" || ifNoneMatch == etag) {
+		return ErrPreconditionFailed
+	}
+
+	return nil
+}
+
+// isPreconditionFailedError returns true for 412 responses, also when the uploader wrapped the error
+// of the request.
+func isPreconditionFailedError(err error) bool {
+	for err != nil {
+		if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusPreconditionFailed {
+			return true
+		}
+		aerr, ok := err.(awserr.Error)
+		if !ok {
+			return false
+		}
+		err = aerr.OrigErr()
+	}
+
+	return false
+}
+
+func (s *AWSS3) batchHead(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var keys []string
+	err := json.Unmarshal(req.Data, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing batchHead payload, expected a json array of keys: %w", err)
+	}
+	if err = s.validateKeys(keys...); err != nil {
+		return nil, err
+	}
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	retryBudget := retryBudgetOption(objectstorage.NewRetryBudget(s.metadata.RetryBudget))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]batchHeadResult, len(keys))
+	sem := make(chan struct{}, defaultBatchConcurrency)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := s.headObject(ctx, client, key, retryBudget)
+			mu.Lock()
+			results[key] = result
+			mu.Unlock()
+		}(key)
+	}
+	wg.Wait()
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling batchHead response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+func (s *AWSS3) headObject(ctx context.Context, client s3iface.S3API, key string, opts ...request.Option) batchHeadResult {
+	out, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+	}, opts...)
+	if err != nil {
+		return batchHeadResult{Error: err.Error()}
+	}
+
+	return batchHeadResult{
+		Size:              aws.Int64Value(out.ContentLength),
+		ContentType:       aws.StringValue(out.ContentType),
+		ETag:              aws.StringValue(out.ETag),
+		LastModified:      out.LastModified,
+		ReplicationStatus: aws.StringValue(out.ReplicationStatus),
+	}
+}
+
+// listBuckets returns the buckets owned by the account of the credentials.
+func (s *AWSS3) listBuckets(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	out, err := s.client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing buckets: %w", err)
+	}
+
+	buckets := make([]bucketItem, 0, len(out.Buckets))
+	for _, bucket := range out.Buckets {
+		buckets = append(buckets, bucketItem{
+			Name:         aws.StringValue(bucket.Name),
+			CreationDate: bucket.CreationDate,
+		})
+	}
+
+	b, err := json.Marshal(buckets)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling listBuckets response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// listMultipartUploads returns the multipart uploads of the bucket that were started but not
+// completed or aborted yet, including the ones started by other clients.
+func (s *AWSS3) listMultipartUploads(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var payload listMultipartUploadsPayload
+	if len(req.Data) > 0 {
+		err := json.Unmarshal(req.Data, &payload)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing listMultipartUploads payload: %w", err)
+		}
+	}
+
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.ListMultipartUploadsInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+	}
+	if payload.Prefix != "" {
+		input.Prefix = aws.String(payload.Prefix)
+	}
+
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+
+	uploads := []multipartUploadItem{}
+	err = client.ListMultipartUploadsPagesWithContext(ctx, input, func(page *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, upload := range page.Uploads {
+			uploads = append(uploads, multipartUploadItem{
+				Key:       aws.StringValue(upload.Key),
+				UploadID:  aws.StringValue(upload.UploadId),
+				Initiated: upload.Initiated,
+			})
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing multipart uploads: %w", err)
+	}
+
+	b, err := json.Marshal(uploads)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling listMultipartUploads response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+func (s *AWSS3) abortMultipartUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKeyKey]
+	if key == "" {
+		return nil, fmt.Errorf("%s is a required attribute", metadataKeyKey)
+	}
+	uploadID := req.Metadata[metadataKeyUploadID]
+	if uploadID == "" {
+		return nil, fmt.Errorf("%s is a required attribute", metadataKeyUploadID)
+	}
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	_, err = client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+		UploadId:            aws.String(uploadID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error aborting multipart upload %s of %s: %w", uploadID, key, err)
+	}
+
+	return nil, nil
+}
+
+// preview gets the first previewBytes bytes of the object with a ranged request, whatever its size,
+// and returns the size of the whole object in the contentLength metadata, with the properties of
+// the object.
+func (s *AWSS3) preview(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKeyKey]
+	if key == "" {
+		return nil, fmt.Errorf("%s is a required attribute", metadataKeyKey)
+	}
+	previewBytes, err := objectstorage.ParsePreviewBytes(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	out, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+		Range:               aws.String(fmt.Sprintf("bytes=0-%d", previewBytes-1)),
+	})
+	if err != nil {
+		// Ranged reads of empty objects fail as the range can't be satisfied
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidRange" {
+			return &bindings.InvokeResponse{
+				Data:     []byte{},
+				Metadata: map[string]string{objectstorage.MetadataKeyContentLength: "0"},
+			}, nil
+		}
+
+		return nil, fmt.Errorf("error getting object %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(out.Body, previewBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error reading object %s: %w", key, err)
+	}
+
+	size, ok := objectstorage.ContentRangeSize(aws.StringValue(out.ContentRange))
+	if !ok {
+		size = aws.Int64Value(out.ContentLength)
+	}
+
+	metadata := map[string]string{
+		objectstorage.MetadataKeyContentLength: strconv.FormatInt(size, 10),
+		metadataKeyETag:                        aws.StringValue(out.ETag),
+		metadataKeyContentType:                 aws.StringValue(out.ContentType),
+	}
+	if out.LastModified != nil {
+		metadata[metadataKeyLastModified] = out.LastModified.UTC().Format(http.TimeFormat)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     data,
+		Metadata: metadata,
+	}, nil
+}
+
+// touch updates the last modified time of the object without changing its contents, by copying the
+// object onto itself. S3 rejects copies to the same key that keep the metadata, so the metadata,
+// content headers, storage class and encryption read with HeadObject are set again. The copy
+// creates a new version on versioned buckets, resets the ACL of the object to the default and is
+// limited to objects of up to 5 GB.
+func (s *AWSS3) touch(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKeyKey]
+	if key == "" {
+		return nil, fmt.Errorf("%s is a required attribute", metadataKeyKey)
+	}
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	head, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting object %s: %w", key, err)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+		CopySource:          aws.String(url.PathEscape(s.metadata.Bucket + "/" + key)),
+		// The source is the object itself, so it belongs to the same account
+		ExpectedSourceBucketOwner: s.expectedBucketOwner(),
+		// Fails if the object was replaced since HeadObject, instead of reverting its metadata
+		CopySourceIfMatch:  head.ETag,
+		MetadataDirective:  aws.String(s3.MetadataDirectiveReplace),
+		Metadata:           head.Metadata,
+		ContentType:        head.ContentType,
+		ContentEncoding:    head.ContentEncoding,
+		ContentLanguage:    head.ContentLanguage,
+		ContentDisposition: head.ContentDisposition,
+		CacheControl:       head.CacheControl,
+		StorageClass:       head.StorageClass,
+		BucketKeyEnabled:   head.BucketKeyEnabled,
+	}
+	if expires, err := http.ParseTime(aws.StringValue(head.Expires)); err == nil {
+		input.Expires = aws.Time(expires)
+	}
+	if aws.StringValue(head.ServerSideEncryption) != "" {
+		input.ServerSideEncryption = head.ServerSideEncryption
+		input.SSEKMSKeyId = head.SSEKMSKeyId
+	}
+	out, err := client.CopyObjectWithContext(ctx, input)
+	if err != nil {
+		if isPreconditionFailedError(err) {
+			return nil, ErrPreconditionFailed
+		}
+
+		return nil, fmt.Errorf("error touching object %s: %w", key, err)
+	}
+
+	resp := touchResponse{VersionID: out.VersionId}
+	if out.CopyObjectResult != nil {
+		resp.ETag = aws.StringValue(out.CopyObjectResult.ETag)
+		resp.LastModified = out.CopyObjectResult.LastModified
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling touch response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
 }
 
 func (s *AWSS3) parseMetadata(metadata bindings.Metadata) (*s3Metadata, error) {
@@ -89,16 +1093,242 @@ func (s *AWSS3) parseMetadata(metadata bindings.Metadata) (*s3Metadata, error) {
 		return nil, err
 	}
 
+	if m.AssumeRoleArn != "" && !strings.HasPrefix(m.AssumeRoleArn, "arn:") {
+		return nil, fmt.Errorf("invalid assumeRoleArn %q, expected the ARN of an IAM role", m.AssumeRoleArn)
+	}
+	if m.SessionName != "" && m.AssumeRoleArn == "" {
+		return nil, fmt.Errorf("sessionName requires assumeRoleArn")
+	}
+
+	switch m.Encryption {
+	case "", s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms:
+	default:
+		return nil, fmt.Errorf("invalid encryption %q, expected %s or %s", m.Encryption, s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms)
+	}
+	if m.KMSKeyID != "" && m.Encryption != s3.ServerSideEncryptionAwsKms {
+		return nil, fmt.Errorf("kmsKeyId requires encryption %s", s3.ServerSideEncryptionAwsKms)
+	}
+
+	if m.PartSize == 0 {
+		m.PartSize = s3manager.DefaultUploadPartSize
+	}
+	if m.PartSize < s3manager.MinUploadPartSize || m.PartSize > maxUploadPartSize {
+		return nil, fmt.Errorf("invalid partSize %d, must be between %d and %d bytes", m.PartSize, s3manager.MinUploadPartSize, maxUploadPartSize)
+	}
+
+	m.Timeouts, err = objectstorage.ParseTimeouts(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	m.WriteWindow, err = objectstorage.ParseWriteWindow(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ProgressLogInterval, err = objectstorage.ParseProgressLogInterval(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	m.UploadSessionTTL, err = objectstorage.ParseUploadSessionTTL(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	m.AllowEmpty, err = objectstorage.ParseAllowEmpty(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	m.AutoScalePartSize = true
+	if val, ok := metadata.Properties[metadataKeyAutoScalePartSize]; ok && val != "" {
+		m.AutoScalePartSize, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", metadataKeyAutoScalePartSize, err)
+		}
+	}
+
+	if m.HashPrefixLength == 0 {
+		m.HashPrefixLength = objectstorage.DefaultHashPrefixLength
+	}
+	if m.HashPrefixLength < 0 || m.HashPrefixLength > objectstorage.MaxHashPrefixLength {
+		return nil, fmt.Errorf("invalid hashPrefixLength %d, must be between 1 and %d", m.HashPrefixLength, objectstorage.MaxHashPrefixLength)
+	}
+
+	validateName := true
+	if val, ok := metadata.Properties[metadataKeyValidateBucketName]; ok && val != "" {
+		validateName, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", metadataKeyValidateBucketName, err)
+		}
+	}
+	if validateName && m.Bucket != "" {
+		if err = validateBucketName(m.Bucket); err != nil {
+			return nil, err
+		}
+	}
+
 	return &m, nil
 }
 
-func (s *AWSS3) getClient(metadata *s3Metadata) (*s3manager.Uploader, error) {
+// partSizeFor returns the part size to upload an object of the given size with. When the configured part
+// size would need more than the 10,000 parts S3 allows, the part size is scaled up if auto-scaling is enabled.
+func (s *AWSS3) partSizeFor(size int64) (int64, error) {
+	partSize := s.metadata.PartSize
+	if size <= partSize*s3manager.MaxUploadParts {
+		return partSize, nil
+	}
+
+	if !s.metadata.AutoScalePartSize {
+		return 0, fmt.Errorf("object of %d bytes needs more than %d parts of %d bytes, increase partSize or enable %s",
+			size, s3manager.MaxUploadParts, partSize, metadataKeyAutoScalePartSize)
+	}
+
+	partSize = (size + s3manager.MaxUploadParts - 1) / s3manager.MaxUploadParts
+	if partSize > maxUploadPartSize {
+		return 0, fmt.Errorf("object of %d bytes exceeds the maximum size of a multipart upload", size)
+	}
+
+	return partSize, nil
+}
+
+// selectClient returns the client and the uploader matching the addressing style requested via
+// metadata, falling back to the binding's default ones, addressed as set by forcePathStyle, when the
+// request doesn't specify one. All the requests of an operation are sent with them, so that the
+// bucket is addressed the same way for the whole operation.
+func (s *AWSS3) selectClient(req *bindings.InvokeRequest) (s3iface.S3API, *s3manager.Uploader, error) {
+	if _, ok := req.Metadata[metadataKeyForcePathStyle]; !ok {
+		return s.client, s.uploader, nil
+	}
+
+	forcePathStyle, err := req.GetMetadataAsBool(metadataKeyForcePathStyle)
+	if err != nil {
+		return nil, nil, err
+	}
+	if forcePathStyle {
+		return s.pathStyleUploader.S3, s.pathStyleUploader, nil
+	}
+
+	return s.virtualHostedUploader.S3, s.virtualHostedUploader, nil
+}
+
+// optionalString returns nil for empty values, so that the inputs keep the defaults of the service.
+func optionalString(val string) *string {
+	if val == "" {
+		return nil
+	}
+
+	return aws.String(val)
+}
+
+// parseStorageClass returns the storage class of the request metadata, or nil if it isn't set.
+func parseStorageClass(metadata map[string]string) (*string, error) {
+	val := metadata[metadataKeyStorageClass]
+	if val == "" {
+		return nil, nil
+	}
+	if !isValidStorageClass(val) {
+		return nil, fmt.Errorf("invalid storage class: %s; allowed: %s", val, s3.StorageClass_Values())
+	}
+
+	return aws.String(val), nil
+}
+
+func isValidStorageClass(storageClass string) bool {
+	for _, item := range s3.StorageClass_Values() {
+		if item == storageClass {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryBudgetOption makes the requests it's applied to share the retry budget. A request that would
+// be retried once the budget is exhausted fails with objectstorage.ErrRetryBudgetExceeded instead.
+func retryBudgetOption(budget *objectstorage.RetryBudget) request.Option {
+	return func(r *request.Request) {
+		if budget == nil {
+			return
+		}
+
+		// Runs before the default handler, which keeps the retry decision made here
+		r.Handlers.AfterRetry.PushFront(func(r *request.Request) {
+			if r.Retryable == nil {
+				r.Retryable = aws.Bool(r.ShouldRetry(r))
+			}
+			if r.WillRetry() && !budget.Take() {
+				r.Retryable = aws.Bool(false)
+				r.Error = awserr.New("RetryBudgetExceeded", objectstorage.ErrRetryBudgetExceeded.Error(), r.Error)
+			}
+		})
+	}
+}
+
+// bucketKeyEnabled returns the value for the BucketKeyEnabled upload input, leaving it unset when
+// the feature is off so the bucket default applies.
+func (s *AWSS3) bucketKeyEnabled() *bool {
+	if !s.metadata.BucketKeyEnabled {
+		return nil
+	}
+
+	return aws.Bool(true)
+}
+
+// serverSideEncryption returns the value for the ServerSideEncryption upload inputs, unset when
+// encryption isn't set so the bucket default applies.
+func (s *AWSS3) serverSideEncryption() *string {
+	if s.metadata.Encryption == "" {
+		return nil
+	}
+
+	return aws.String(s.metadata.Encryption)
+}
+
+// kmsKeyID returns the value for the SSEKMSKeyId upload inputs, unset to use the AWS managed key.
+func (s *AWSS3) kmsKeyID() *string {
+	if s.metadata.KMSKeyID == "" {
+		return nil
+	}
+
+	return aws.String(s.metadata.KMSKeyID)
+}
+
+// expectedBucketOwner returns the value for the ExpectedBucketOwner inputs, unset when the owner of
+// the bucket isn't checked.
+func (s *AWSS3) expectedBucketOwner() *string {
+	if s.metadata.ExpectedBucketOwner == "" {
+		return nil
+	}
+
+	return aws.String(s.metadata.ExpectedBucketOwner)
+}
+
+func (s *AWSS3) getSession(metadata *s3Metadata) (*session.Session, error) {
 	sess, err := aws_auth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	uploader := s3manager.NewUploader(sess)
+	// Only set when enabled, so that the defaults of the SDK and of the environment apply otherwise
+	config := aws.NewConfig()
+	if metadata.ForcePathStyle {
+		config = config.WithS3ForcePathStyle(true)
+	}
+	if metadata.DisableSSL {
+		config = config.WithDisableSSL(true)
+	}
+	if metadata.AssumeRoleArn != "" {
+		// The credentials of the role are requested from STS with the base credentials of the
+		// session, and refreshed before they expire
+		creds := stscreds.NewCredentials(sess, metadata.AssumeRoleArn, func(p *stscreds.AssumeRoleProvider) {
+			if metadata.SessionName != "" {
+				p.RoleSessionName = metadata.SessionName
+			}
+		})
+		config = config.WithCredentials(creds)
+	}
 
-	return uploader, nil
+	return sess.Copy(config), nil
 }
@@ -6,9 +6,32 @@
 package s3
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+	"github.com/dapr/kit/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +39,7 @@ func TestParseMetadata(t *testing.T) {
 	m := bindings.Metadata{}
 	m.Properties = map[string]string{
 		"AccessKey": "key", "Region": "region", "SecretKey": "secret", "Bucket": "test", "Endpoint": "endpoint", "SessionToken": "token",
+		"BucketKeyEnabled": "true",
 	}
 	s3 := AWSS3{}
 	meta, err := s3.parseMetadata(m)
@@ -26,4 +50,1084 @@ func TestParseMetadata(t *testing.T) {
 	assert.Equal(t, "test", meta.Bucket)
 	assert.Equal(t, "endpoint", meta.Endpoint)
 	assert.Equal(t, "token", meta.SessionToken)
+	assert.Equal(t, true, meta.BucketKeyEnabled)
+	assert.Equal(t, int64(s3manager.DefaultUploadPartSize), meta.PartSize)
+	assert.Equal(t, true, meta.AutoScalePartSize)
+}
+
+func TestAssumeRole(t *testing.T) {
+	var form url.Values
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		form, authorization = r.PostForm, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials>` +
+			`<AccessKeyId>assumed</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+			`<Expiration>2099-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`))
+	}))
+	defer server.Close()
+
+	s := AWSS3{}
+	meta := &s3Metadata{Region: "us-east-1", Endpoint: server.URL, AccessKey: "key", SecretKey: "secret"}
+
+	t.Run("use the static credentials without assumeRoleArn", func(t *testing.T) {
+		sess, err := s.getSession(meta)
+		assert.Nil(t, err)
+		creds, err := sess.Config.Credentials.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, "key", creds.AccessKeyID)
+		assert.Nil(t, form)
+	})
+
+	t.Run("assume the role with the static credentials", func(t *testing.T) {
+		meta.AssumeRoleArn = "arn:aws:iam::111122223333:role/reader"
+		meta.SessionName = "dapr"
+		sess, err := s.getSession(meta)
+		assert.Nil(t, err)
+		creds, err := sess.Config.Credentials.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, "assumed", creds.AccessKeyID)
+		assert.Equal(t, "token", creds.SessionToken)
+		assert.Equal(t, "AssumeRole", form.Get("Action"))
+		assert.Equal(t, "arn:aws:iam::111122223333:role/reader", form.Get("RoleArn"))
+		assert.Equal(t, "dapr", form.Get("RoleSessionName"))
+		assert.Contains(t, authorization, "Credential=key/")
+	})
+
+	t.Run("return error for invalid metadata", func(t *testing.T) {
+		for _, properties := range []map[string]string{
+			{"assumeRoleArn": "reader"},
+			{"sessionName": "dapr"},
+		} {
+			_, err := s.parseMetadata(bindings.Metadata{Properties: properties})
+			assert.Error(t, err, properties)
+		}
+	})
+}
+
+func TestForcePathStyle(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s := NewAWSS3(logger.NewLogger("test"))
+	assert.Nil(t, s.Init(bindings.Metadata{Properties: map[string]string{
+		"region":         "us-east-1",
+		"endpoint":       strings.TrimPrefix(server.URL, "http://"),
+		"accessKey":      "key",
+		"secretKey":      "secret",
+		"bucket":         "test",
+		"forcePathStyle": "true",
+		"disableSSL":     "true",
+	}}))
+
+	_, err := s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "/test/foo", path)
+
+	sess, err := s.getSession(&s3Metadata{Region: "us-east-1"})
+	assert.Nil(t, err)
+	assert.Nil(t, sess.Config.S3ForcePathStyle)
+	assert.Nil(t, sess.Config.DisableSSL)
+}
+
+func TestCreateMetadata(t *testing.T) {
+	var header http.Header
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.WriteHeader(http.StatusOK)
+	}, nil)
+
+	t.Run("set the content headers and the user metadata", func(t *testing.T) {
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata: map[string]string{
+				"key":             "foo",
+				"forcePathStyle":  "true",
+				"contentType":     "text/plain",
+				"contentEncoding": "gzip",
+				"cacheControl":    "no-cache",
+				"blobName":        "foo",
+				"owner":           "dapr",
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "text/plain", header.Get("Content-Type"))
+		assert.Equal(t, "gzip", header.Get("Content-Encoding"))
+		assert.Equal(t, "no-cache", header.Get("Cache-Control"))
+		assert.Equal(t, "dapr", header.Get("X-Amz-Meta-Owner"))
+		for name := range header {
+			if strings.HasPrefix(name, "X-Amz-Meta-") {
+				assert.Equal(t, "X-Amz-Meta-Owner", name)
+			}
+		}
+	})
+
+	t.Run("return error for metadata over the limit", func(t *testing.T) {
+		header = nil
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "owner": strings.Repeat("x", maxMetadataSize)},
+		})
+		assert.True(t, errors.Is(err, objectstorage.ErrMetadataTooLarge), err)
+		assert.Nil(t, header)
+	})
+
+	t.Run("truncate metadata over the limit with truncateMetadata", func(t *testing.T) {
+		s.metadata.TruncateMetadata = true
+		defer func() { s.metadata.TruncateMetadata = false }()
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "owner": strings.Repeat("x", maxMetadataSize)},
+		})
+		assert.Nil(t, err)
+		assert.Len(t, header.Get("X-Amz-Meta-Owner"), maxMetadataSize-len("owner"))
+	})
+}
+
+func TestStorageClass(t *testing.T) {
+	var header http.Header
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.WriteHeader(http.StatusOK)
+	}, nil)
+
+	t.Run("set the storage class of the object", func(t *testing.T) {
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "storageClass": "GLACIER"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "GLACIER", header.Get("X-Amz-Storage-Class"))
+	})
+
+	t.Run("return error for an unknown storage class", func(t *testing.T) {
+		header = nil
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "storageClass": "COLD"},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "STANDARD_IA")
+		assert.Nil(t, header)
+	})
+
+	t.Run("verify the storage class of the object", func(t *testing.T) {
+		for effective, applied := range map[string]bool{"GLACIER": true, "": false} {
+			s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead && effective != "" {
+					w.Header().Set("x-amz-storage-class", effective)
+				}
+				w.WriteHeader(http.StatusOK)
+			}, nil)
+
+			resp, err := s.Invoke(&bindings.InvokeRequest{
+				Operation: bindings.CreateOperation,
+				Data:      []byte("data"),
+				Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "storageClass": "GLACIER", "verifyTier": "true"},
+			})
+			if applied {
+				assert.Nil(t, err)
+				assert.Equal(t, objectstorage.TierStatusApplied, resp.Metadata[objectstorage.MetadataKeyTierStatus])
+			} else {
+				assert.True(t, errors.Is(err, objectstorage.ErrTierNotApplied))
+				assert.Contains(t, err.Error(), "STANDARD")
+			}
+		}
+	})
+
+	t.Run("return error when verifying without a storage class", func(t *testing.T) {
+		header = nil
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "verifyTier": "true"},
+		})
+		assert.Error(t, err)
+		assert.Nil(t, header)
+	})
+}
+
+func TestParseEncryptionMetadata(t *testing.T) {
+	s3 := AWSS3{}
+
+	t.Run("parse the encryption of the uploads", func(t *testing.T) {
+		meta, err := s3.parseMetadata(bindings.Metadata{Properties: map[string]string{"encryption": "aws:kms", "kmsKeyId": "key"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "aws:kms", meta.Encryption)
+		assert.Equal(t, "key", meta.KMSKeyID)
+	})
+
+	t.Run("return error for invalid combinations", func(t *testing.T) {
+		for _, properties := range []map[string]string{
+			{"encryption": "aes256"},
+			{"kmsKeyId": "key"},
+			{"encryption": "AES256", "kmsKeyId": "key"},
+		} {
+			_, err := s3.parseMetadata(bindings.Metadata{Properties: properties})
+			assert.Error(t, err, properties)
+		}
+	})
+}
+
+func TestServerSideEncryption(t *testing.T) {
+	var header http.Header
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.WriteHeader(http.StatusOK)
+	}, map[string]string{"encryption": "aws:kms", "kmsKeyId": "key"})
+
+	_, err := s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "aws:kms", header.Get("X-Amz-Server-Side-Encryption"))
+	assert.Equal(t, "key", header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+
+	s.metadata.Encryption, s.metadata.KMSKeyID = "", ""
+	_, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, header.Get("X-Amz-Server-Side-Encryption"))
+}
+
+func TestParsePartSizeMetadata(t *testing.T) {
+	s3 := AWSS3{}
+
+	t.Run("parse partSize and autoScalePartSize", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{"partSize": "10485760", "autoScalePartSize": "false"}
+		meta, err := s3.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10485760), meta.PartSize)
+		assert.Equal(t, false, meta.AutoScalePartSize)
+	})
+
+	t.Run("return error for partSize below the minimum", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{"partSize": "1024"}
+		_, err := s3.parseMetadata(m)
+		assert.Error(t, err)
+	})
+}
+
+func TestPartSizeFor(t *testing.T) {
+	partSize := int64(s3manager.MinUploadPartSize)
+	limit := partSize * s3manager.MaxUploadParts
+
+	t.Run("keep part size at the parts limit", func(t *testing.T) {
+		s3 := AWSS3{metadata: &s3Metadata{PartSize: partSize, AutoScalePartSize: true}}
+		size, err := s3.partSizeFor(limit)
+		assert.Nil(t, err)
+		assert.Equal(t, partSize, size)
+	})
+
+	t.Run("scale part size past the parts limit", func(t *testing.T) {
+		s3 := AWSS3{metadata: &s3Metadata{PartSize: partSize, AutoScalePartSize: true}}
+		size, err := s3.partSizeFor(limit + 1)
+		assert.Nil(t, err)
+		assert.Equal(t, partSize+1, size)
+		assert.LessOrEqual(t, (limit+size)/size, int64(s3manager.MaxUploadParts))
+	})
+
+	t.Run("return error past the parts limit without auto-scaling", func(t *testing.T) {
+		s3 := AWSS3{metadata: &s3Metadata{PartSize: partSize}}
+		_, err := s3.partSizeFor(limit + 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error past the maximum object size", func(t *testing.T) {
+		s3 := AWSS3{metadata: &s3Metadata{PartSize: partSize, AutoScalePartSize: true}}
+		_, err := s3.partSizeFor(maxUploadPartSize*s3manager.MaxUploadParts + 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestSelectClient(t *testing.T) {
+	s3 := AWSS3{
+		client:                &mockS3Client{},
+		uploader:              &s3manager.Uploader{},
+		pathStyleUploader:     &s3manager.Uploader{S3: &mockS3Client{}},
+		virtualHostedUploader: &s3manager.Uploader{S3: &mockS3Client{}},
+	}
+
+	t.Run("default client and uploader when not set", func(t *testing.T) {
+		c, u, err := s3.selectClient(&bindings.InvokeRequest{})
+		assert.Nil(t, err)
+		assert.Same(t, s3.client, c)
+		assert.Same(t, s3.uploader, u)
+	})
+
+	t.Run("path-style client and uploader when forcePathStyle is true", func(t *testing.T) {
+		c, u, err := s3.selectClient(&bindings.InvokeRequest{Metadata: map[string]string{"forcePathStyle": "true"}})
+		assert.Nil(t, err)
+		assert.Same(t, s3.pathStyleUploader.S3, c)
+		assert.Same(t, s3.pathStyleUploader, u)
+	})
+
+	t.Run("virtual-hosted client and uploader when forcePathStyle is false", func(t *testing.T) {
+		c, u, err := s3.selectClient(&bindings.InvokeRequest{Metadata: map[string]string{"forcePathStyle": "false"}})
+		assert.Nil(t, err)
+		assert.Same(t, s3.virtualHostedUploader.S3, c)
+		assert.Same(t, s3.virtualHostedUploader, u)
+	})
+
+	t.Run("error for invalid forcePathStyle", func(t *testing.T) {
+		_, _, err := s3.selectClient(&bindings.InvokeRequest{Metadata: map[string]string{"forcePathStyle": "maybe"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("address the bucket of every operation as requested", func(t *testing.T) {
+		var path string
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Header().Set("Content-Range", "bytes 0-4/5")
+			w.Write([]byte("hello"))
+		}, nil)
+
+		resp, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: previewOperation,
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", string(resp.Data))
+		assert.Equal(t, "/test/foo", path)
+	})
+}
+
+type mockS3Client struct {
+	s3iface.S3API
+	headObject  func(*s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+	listBuckets func(*s3.ListBucketsInput) (*s3.ListBucketsOutput, error)
+	getObject   func(*s3.GetObjectInput) (*s3.GetObjectOutput, error)
+
+	listMultipartUploadsPages func(*s3.ListMultipartUploadsInput, func(*s3.ListMultipartUploadsOutput, bool) bool) error
+	abortMultipartUpload      func(*s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error)
+	copyObject                func(*s3.CopyObjectInput) (*s3.CopyObjectOutput, error)
+	listPartsPages            func(*s3.ListPartsInput, func(*s3.ListPartsOutput, bool) bool) error
+	listObjectsV2             func(*s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
+}
+
+func (m *mockS3Client) ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error) {
+	return m.listObjectsV2(input)
+}
+
+func (m *mockS3Client) ListPartsPagesWithContext(ctx aws.Context, input *s3.ListPartsInput, fn func(*s3.ListPartsOutput, bool) bool, opts ...request.Option) error {
+	return m.listPartsPages(input, fn)
+}
+
+func (m *mockS3Client) CopyObjectWithContext(ctx aws.Context, input *s3.CopyObjectInput, opts ...request.Option) (*s3.CopyObjectOutput, error) {
+	return m.copyObject(input)
+}
+
+func (m *mockS3Client) ListMultipartUploadsPagesWithContext(ctx aws.Context, input *s3.ListMultipartUploadsInput, fn func(*s3.ListMultipartUploadsOutput, bool) bool, opts ...request.Option) error {
+	return m.listMultipartUploadsPages(input, fn)
+}
+
+func (m *mockS3Client) AbortMultipartUploadWithContext(ctx aws.Context, input *s3.AbortMultipartUploadInput, opts ...request.Option) (*s3.AbortMultipartUploadOutput, error) {
+	return m.abortMultipartUpload(input)
+}
+
+func (m *mockS3Client) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	return m.getObject(input)
+}
+
+func (m *mockS3Client) ListBucketsWithContext(ctx aws.Context, input *s3.ListBucketsInput, opts ...request.Option) (*s3.ListBucketsOutput, error) {
+	return m.listBuckets(input)
+}
+
+func (m *mockS3Client) HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error) {
+	return m.headObject(input)
+}
+
+func TestBatchHead(t *testing.T) {
+	client := &mockS3Client{
+		headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+			if *input.Key == "missing" {
+				return nil, errors.New("NotFound")
+			}
+
+			return &s3.HeadObjectOutput{
+				ContentLength:     aws.Int64(42),
+				ContentType:       aws.String("text/plain"),
+				ETag:              aws.String("\"etag\""),
+				ReplicationStatus: aws.String(s3.ReplicationStatusComplete),
+			}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+	t.Run("report per-key results", func(t *testing.T) {
+		r := bindings.InvokeRequest{Data: []byte(`["found", "missing"]`)}
+		resp, err := binding.batchHead(context.Background(), &r)
+		assert.Nil(t, err)
+
+		var results map[string]batchHeadResult
+		assert.Nil(t, json.Unmarshal(resp.Data, &results))
+		assert.Equal(t, int64(42), results["found"].Size)
+		assert.Equal(t, "text/plain", results["found"].ContentType)
+		assert.Equal(t, "COMPLETE", results["found"].ReplicationStatus)
+		assert.Empty(t, results["found"].Error)
+		assert.Equal(t, "NotFound", results["missing"].Error)
+	})
+
+	t.Run("return error for invalid payload", func(t *testing.T) {
+		r := bindings.InvokeRequest{Data: []byte(`{}`)}
+		_, err := binding.batchHead(context.Background(), &r)
+		assert.Error(t, err)
+	})
+}
+
+func TestBatchHeadRetryBudget(t *testing.T) {
+	var lock sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests++
+		lock.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(server.URL),
+		Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+		SleepDelay:       func(time.Duration) {},
+	}))
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test", RetryBudget: 1}, client: s3.New(sess)}
+
+	resp, err := binding.batchHead(context.Background(), &bindings.InvokeRequest{Data: []byte(`["a", "b"]`)})
+	assert.Nil(t, err)
+
+	var results map[string]batchHeadResult
+	assert.Nil(t, json.Unmarshal(resp.Data, &results))
+	assert.Contains(t, results["a"].Error+results["b"].Error, "retry budget exceeded")
+	// One request per key and the single retry of the budget
+	assert.Equal(t, 3, requests)
+}
+
+// newTestAWSS3 returns a binding initialized against a fake S3 endpoint served by handler.
+func newTestAWSS3(t *testing.T, handler http.HandlerFunc, properties map[string]string) *AWSS3 {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	m := bindings.Metadata{Properties: map[string]string{
+		"region":    "us-east-1",
+		"endpoint":  server.URL,
+		"accessKey": "key",
+		"secretKey": "secret",
+		"bucket":    "test",
+	}}
+	for k, v := range properties {
+		m.Properties[k] = v
+	}
+
+	s := NewAWSS3(logger.NewLogger("test"))
+	err := s.Init(m)
+	assert.Nil(t, err)
+
+	return s
+}
+
+func TestCreate(t *testing.T) {
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusOK)
+	}, nil)
+
+	t.Run("return the provided key", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		}
+		resp, err := s.Invoke(&r)
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, "foo", created.Key)
+		assert.Equal(t, "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7", created.SHA256)
+		assert.Equal(t, "test", created.Bucket)
+		assert.Contains(t, created.Location, "/test/foo")
+	})
+
+	t.Run("return the generated key", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"forcePathStyle": "true"},
+		}
+		resp, err := s.Invoke(&r)
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.NotEmpty(t, created.Key)
+	})
+
+	t.Run("append the extension from the content type", func(t *testing.T) {
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}, map[string]string{"appendExtensionFromContentType": "true"})
+
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "contentType": "image/png", "forcePathStyle": "true"},
+		}
+		resp, err := s.Invoke(&r)
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, "foo.png", created.Key)
+	})
+
+	t.Run("send the expected bucket owner", func(t *testing.T) {
+		var owners []string
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			owners = append(owners, r.Header.Get("x-amz-expected-bucket-owner"))
+			w.WriteHeader(http.StatusOK)
+		}, map[string]string{"expectedBucketOwner": "111122223333"})
+
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		}
+		_, err := s.Invoke(&r)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"111122223333"}, owners)
+	})
+
+	t.Run("validate without uploading", func(t *testing.T) {
+		var methods []string
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			w.WriteHeader(http.StatusOK)
+		}, nil)
+
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "validateOnly": "true", "forcePathStyle": "true"},
+		}
+		resp, err := s.Invoke(&r)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{http.MethodHead}, methods)
+
+		var validated validateOnlyResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &validated))
+		assert.Equal(t, validateOnlyResponse{Bucket: "test", Key: "foo", Size: 4}, validated)
+	})
+
+	t.Run("return the canonical response", func(t *testing.T) {
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", "\"etag\"")
+			w.Header().Set("x-amz-version-id", "v1")
+			w.WriteHeader(http.StatusOK)
+		}, map[string]string{"canonicalResponse": "true"})
+
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "contentType": "text/plain", "forcePathStyle": "true"},
+		}
+		resp, err := s.Invoke(&r)
+		assert.Nil(t, err)
+
+		var created objectstorage.CanonicalResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, "aws.s3", created.Provider)
+		assert.Equal(t, "test", created.Bucket)
+		assert.Equal(t, "foo", created.Key)
+		assert.Contains(t, created.URL, "/test/foo")
+		assert.Equal(t, "\"etag\"", created.ETag)
+		assert.Equal(t, "v1", created.VersionID)
+		assert.Equal(t, int64(4), created.Size)
+		assert.Equal(t, "text/plain", created.ContentType)
+	})
+}
+
+func TestCreateThumbnail(t *testing.T) {
+	var img bytes.Buffer
+	assert.Nil(t, png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 20, 40))))
+
+	uploads := map[string]string{}
+	var thumbnail []byte
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		uploads[r.URL.Path] = r.Header.Get("Content-Type")
+		if strings.HasSuffix(r.URL.Path, ".thumb.jpg") {
+			thumbnail, _ = ioutil.ReadAll(r.Body)
+		}
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusOK)
+	}, nil)
+	create := func(data []byte, contentType string) (createResponse, error) {
+		var created createResponse
+		resp, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      data,
+			Metadata: map[string]string{
+				"key":               "foo",
+				"contentType":       contentType,
+				"generateThumbnail": "true",
+				"thumbnailSize":     "10x10",
+				"forcePathStyle":    "true",
+			},
+		})
+		if err != nil {
+			return created, err
+		}
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+
+		return created, nil
+	}
+
+	t.Run("upload the thumbnail of an image", func(t *testing.T) {
+		created, err := create(img.Bytes(), "image/png")
+		assert.Nil(t, err)
+		assert.Contains(t, uploads, "/test/foo")
+		assert.Equal(t, "image/jpeg", uploads["/test/foo.thumb.jpg"])
+		assert.Contains(t, created.ThumbnailURL, "/test/foo.thumb.jpg")
+
+		config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+		assert.Nil(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, 5, config.Width)
+		assert.Equal(t, 10, config.Height)
+	})
+
+	t.Run("skip content that isn't an image", func(t *testing.T) {
+		uploads = map[string]string{}
+		created, err := create([]byte("data"), "text/plain")
+		assert.Nil(t, err)
+		assert.Len(t, uploads, 1)
+		assert.Empty(t, created.ThumbnailURL)
+	})
+
+	t.Run("return error for a corrupted image without uploading it", func(t *testing.T) {
+		uploads = map[string]string{}
+		_, err := create(img.Bytes()[:img.Len()/2], "image/png")
+		assert.Error(t, err)
+		assert.Empty(t, uploads)
+	})
+}
+
+func TestBilledRequests(t *testing.T) {
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusOK)
+	}, map[string]string{"returnBilledRequests": "true"})
+
+	resp, err := s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo", "ifMatch": "\"etag\"", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", resp.Metadata["billedRequests"])
+
+	s.metadata.EmulateConditionalWrites = true
+	resp, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo", "ifMatch": "\"etag\"", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "2", resp.Metadata["billedRequests"])
+
+	s.metadata.ReturnBilledRequests = false
+	resp, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	assert.NotContains(t, resp.Metadata, "billedRequests")
+}
+
+func TestConditionalCreate(t *testing.T) {
+	t.Run("send conditions and return ErrPreconditionFailed on 412", func(t *testing.T) {
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "*", r.Header.Get("If-None-Match"))
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}, nil)
+
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "ifNoneMatch": "*", "forcePathStyle": "true"},
+		})
+		assert.Equal(t, ErrPreconditionFailed, err)
+	})
+
+	t.Run("check conditions with a head request when emulated", func(t *testing.T) {
+		var methods []string
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			assert.Empty(t, r.Header.Get("If-Match"))
+			w.Header().Set("ETag", "\"etag\"")
+			w.WriteHeader(http.StatusOK)
+		}, map[string]string{"emulateConditionalWrites": "true"})
+
+		r := bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "ifMatch": "\"other\"", "forcePathStyle": "true"},
+		}
+		_, err := s.Invoke(&r)
+		assert.Equal(t, ErrPreconditionFailed, err)
+		assert.Equal(t, []string{http.MethodHead}, methods)
+
+		r.Metadata["ifMatch"] = "\"etag\""
+		_, err = s.Invoke(&r)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{http.MethodHead, http.MethodHead, http.MethodPut}, methods)
+	})
+}
+
+func TestCreateEmpty(t *testing.T) {
+	t.Run("create a zero-byte object by default", func(t *testing.T) {
+		var size int64 = -1
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			size = r.ContentLength
+			w.Header().Set("ETag", "\"etag\"")
+			w.WriteHeader(http.StatusOK)
+		}, nil)
+
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), size)
+	})
+
+	t.Run("return ErrEmptyData if allowEmpty is false", func(t *testing.T) {
+		s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "unexpected request")
+		}, map[string]string{"allowEmpty": "false"})
+
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		})
+		assert.Equal(t, objectstorage.ErrEmptyData, err)
+	})
+}
+
+type fakeMetrics struct {
+	ops   []bindings.OperationLabels
+	bytes []int64
+}
+
+func (f *fakeMetrics) IncrementOp(labels bindings.OperationLabels) {
+	f.ops = append(f.ops, labels)
+}
+
+func (f *fakeMetrics) ObserveLatency(labels bindings.OperationLabels, latency time.Duration) {}
+
+func (f *fakeMetrics) ObserveBytes(labels bindings.OperationLabels, bytes int64) {
+	f.bytes = append(f.bytes, bytes)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &fakeMetrics{}
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write([]byte("da"))
+		}
+	}, nil)
+	s.metrics = metrics
+
+	_, err := s.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	_, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: "preview",
+		Metadata:  map[string]string{"key": "foo", "previewBytes": "2", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	_, err = s.Invoke(&bindings.InvokeRequest{Operation: "unsupported"})
+	assert.Error(t, err)
+
+	assert.Equal(t, []bindings.OperationLabels{
+		{Operation: "create", Provider: "aws.s3", Outcome: bindings.OutcomeSuccess},
+		{Operation: "preview", Provider: "aws.s3", Outcome: bindings.OutcomeSuccess},
+		{Operation: "unsupported", Provider: "aws.s3", Outcome: bindings.OutcomeError},
+	}, metrics.ops)
+	assert.Equal(t, []int64{4, 2}, metrics.bytes)
+}
+
+func TestHashPrefix(t *testing.T) {
+	var paths []string
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusOK)
+	}, map[string]string{"hashPrefix": "true", "hashPrefixLength": "6", "keyTemplate": "{date}/{uuid}"})
+
+	t.Run("prefix generated keys", func(t *testing.T) {
+		resp, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"forcePathStyle": "true"},
+		})
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Regexp(t, "^[0-9a-f]{6}/[0-9]{4}-[0-9]{2}-[0-9]{2}/", created.Key)
+		assert.Equal(t, objectstorage.HashPrefix(created.Key[7:], 6), created.Key)
+		assert.Equal(t, "/test/"+created.Key, paths[len(paths)-1])
+	})
+
+	t.Run("keep keys of the request", func(t *testing.T) {
+		resp, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		})
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, "foo", created.Key)
+	})
+
+	t.Run("return error for invalid hashPrefixLength", func(t *testing.T) {
+		_, err := (&AWSS3{}).parseMetadata(bindings.Metadata{Properties: map[string]string{"hashPrefix": "true", "hashPrefixLength": "17"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestListBuckets(t *testing.T) {
+	client := &mockS3Client{
+		listBuckets: func(input *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+			return &s3.ListBucketsOutput{
+				Buckets: []*s3.Bucket{{Name: aws.String("a")}, {Name: aws.String("b")}},
+			}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{}, client: client}
+
+	resp, err := binding.listBuckets(context.Background(), &bindings.InvokeRequest{})
+	assert.Nil(t, err)
+
+	var buckets []bucketItem
+	assert.Nil(t, json.Unmarshal(resp.Data, &buckets))
+	assert.Len(t, buckets, 2)
+	assert.Equal(t, "a", buckets[0].Name)
+}
+
+func TestAllowedWriteWindow(t *testing.T) {
+	now := time.Now().UTC()
+	window, err := objectstorage.ParseWriteWindow(map[string]string{
+		"allowedWriteWindow": now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04"),
+	})
+	assert.Nil(t, err)
+	client := &mockS3Client{
+		listBuckets: func(input *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+			return &s3.ListBucketsOutput{}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{WriteWindow: window}, client: client}
+
+	_, err = binding.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"key": "a"},
+	})
+	assert.True(t, errors.Is(err, objectstorage.ErrOutsideWriteWindow))
+
+	_, err = binding.Invoke(&bindings.InvokeRequest{Operation: listBucketsOperation})
+	assert.Nil(t, err)
+}
+
+func TestListMultipartUploads(t *testing.T) {
+	client := &mockS3Client{
+		listMultipartUploadsPages: func(input *s3.ListMultipartUploadsInput, fn func(*s3.ListMultipartUploadsOutput, bool) bool) error {
+			assert.Equal(t, "logs/", aws.StringValue(input.Prefix))
+			fn(&s3.ListMultipartUploadsOutput{
+				Uploads: []*s3.MultipartUpload{{Key: aws.String("logs/a"), UploadId: aws.String("1")}},
+			}, false)
+			fn(&s3.ListMultipartUploadsOutput{
+				Uploads: []*s3.MultipartUpload{{Key: aws.String("logs/b"), UploadId: aws.String("2")}},
+			}, true)
+
+			return nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+	resp, err := binding.listMultipartUploads(context.Background(), &bindings.InvokeRequest{Data: []byte(`{"prefix": "logs/"}`)})
+	assert.Nil(t, err)
+
+	var uploads []multipartUploadItem
+	assert.Nil(t, json.Unmarshal(resp.Data, &uploads))
+	assert.Equal(t, []multipartUploadItem{{Key: "logs/a", UploadID: "1"}, {Key: "logs/b", UploadID: "2"}}, uploads)
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	var aborted *s3.AbortMultipartUploadInput
+	client := &mockS3Client{
+		abortMultipartUpload: func(input *s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error) {
+			aborted = input
+
+			return &s3.AbortMultipartUploadOutput{}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+	t.Run("abort the upload", func(t *testing.T) {
+		_, err := binding.abortMultipartUpload(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"key": "logs/a", "uploadId": "1"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "logs/a", aws.StringValue(aborted.Key))
+		assert.Equal(t, "1", aws.StringValue(aborted.UploadId))
+	})
+
+	t.Run("return error if uploadId is missing", func(t *testing.T) {
+		_, err := binding.abortMultipartUpload(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"key": "logs/a"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestPreview(t *testing.T) {
+	client := &mockS3Client{
+		getObject: func(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+			assert.Equal(t, "bytes=0-4", aws.StringValue(input.Range))
+
+			return &s3.GetObjectOutput{
+				Body:          ioutil.NopCloser(strings.NewReader("hello")),
+				ContentLength: aws.Int64(5),
+				ContentRange:  aws.String("bytes 0-4/1234"),
+				ContentType:   aws.String("text/plain"),
+				ETag:          aws.String("\"etag\""),
+				LastModified:  aws.Time(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)),
+			}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{}, client: client}
+
+	t.Run("return the first bytes and the object size", func(t *testing.T) {
+		resp, err := binding.preview(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"key": "foo", "previewBytes": "5"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", string(resp.Data))
+		assert.Equal(t, "1234", resp.Metadata["contentLength"])
+	})
+
+	t.Run("return the properties of the object", func(t *testing.T) {
+		resp, err := binding.preview(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"key": "foo", "previewBytes": "5"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "text/plain", resp.Metadata["contentType"])
+		assert.Equal(t, "\"etag\"", resp.Metadata["etag"])
+		assert.Equal(t, "Thu, 04 Mar 2021 05:06:07 GMT", resp.Metadata["lastModified"])
+	})
+
+	t.Run("return error if key is missing", func(t *testing.T) {
+		_, err := binding.preview(context.Background(), &bindings.InvokeRequest{})
+		assert.Error(t, err)
+	})
+
+	t.Run("return the error of a missing object", func(t *testing.T) {
+		binding := AWSS3{metadata: &s3Metadata{}, client: &mockS3Client{
+			getObject: func(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+				return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+			},
+		}}
+		resp, err := binding.preview(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"key": "foo"},
+		})
+		assert.Nil(t, resp)
+		var aerr awserr.Error
+		if assert.True(t, errors.As(err, &aerr), err) {
+			assert.Equal(t, s3.ErrCodeNoSuchKey, aerr.Code())
+		}
+	})
+}
+
+func TestStrictKeyValidation(t *testing.T) {
+	binding := AWSS3{metadata: &s3Metadata{StrictKeyValidation: true}}
+
+	t.Run("reject keys escaping their path", func(t *testing.T) {
+		_, err := binding.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Metadata:  map[string]string{"key": "dir/../../foo"},
+		})
+		assert.ErrorIs(t, err, objectstorage.ErrInvalidKey)
+	})
+
+	t.Run("reject batch payloads with invalid keys", func(t *testing.T) {
+		_, err := binding.batchHead(context.Background(), &bindings.InvokeRequest{Data: []byte(`["foo", "/bar"]`)})
+		assert.ErrorIs(t, err, objectstorage.ErrInvalidKey)
+	})
+}
+
+func TestTouch(t *testing.T) {
+	var copied *s3.CopyObjectInput
+	client := &mockS3Client{
+		headObject: func(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+			return &s3.HeadObjectOutput{
+				ETag:         aws.String("\"etag\""),
+				ContentType:  aws.String("text/plain"),
+				Metadata:     map[string]*string{"Owner": aws.String("me")},
+				StorageClass: aws.String(s3.StorageClassStandardIa),
+			}, nil
+		},
+		copyObject: func(input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+			copied = input
+
+			return &s3.CopyObjectOutput{CopyObjectResult: &s3.CopyObjectResult{ETag: aws.String("\"new\"")}}, nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client}
+
+	resp, err := binding.touch(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{"key": "dir/a b"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "test%2Fdir%2Fa%20b", aws.StringValue(copied.CopySource))
+	assert.Equal(t, "\"etag\"", aws.StringValue(copied.CopySourceIfMatch))
+	assert.Equal(t, s3.MetadataDirectiveReplace, aws.StringValue(copied.MetadataDirective))
+	assert.Equal(t, "me", aws.StringValue(copied.Metadata["Owner"]))
+	assert.Equal(t, "text/plain", aws.StringValue(copied.ContentType))
+	assert.Equal(t, s3.StorageClassStandardIa, aws.StringValue(copied.StorageClass))
+
+	var touched touchResponse
+	assert.Nil(t, json.Unmarshal(resp.Data, &touched))
+	assert.Equal(t, "\"new\"", touched.ETag)
 }
@@ -0,0 +1,38 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// objectURLs returns the public URL of the object and a presigned GET URL valid for expiry. client
+// is the client of the upload, so that both URLs use its addressing style. The signed URL is left
+// out if the credentials can't be retrieved to sign it.
+func (s *AWSS3) objectURLs(client s3iface.S3API, key, location string, expiry time.Duration) *objectstorage.ObjectURLs {
+	urls := &objectstorage.ObjectURLs{PublicURL: objectstorage.PublicURL(location)}
+
+	req, _ := client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.metadata.Bucket),
+		Key:    aws.String(key),
+	})
+	expiresOn := time.Now().UTC().Add(expiry).Truncate(time.Second)
+	signedURL, err := req.Presign(expiry)
+	if err != nil {
+		s.logger.Debugf("not returning a signed URL for %s: %v", key, err)
+
+		return urls
+	}
+	urls.SignedURL = signedURL
+	urls.SignedURLExpiresOn = &expiresOn
+
+	return urls
+}
@@ -0,0 +1,64 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSignedURL(t *testing.T) {
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusOK)
+	}, nil)
+
+	t.Run("return a presigned GET URL", func(t *testing.T) {
+		resp, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "returnSignedURL": "true", "signedURLExpiry": "1h"},
+		})
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.NotNil(t, created.ObjectURLs)
+		assert.Equal(t, created.Location, created.PublicURL)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *created.SignedURLExpiresOn, time.Minute)
+
+		signed, err := url.Parse(created.SignedURL)
+		assert.Nil(t, err)
+		assert.Equal(t, created.PublicURL, signed.Scheme+"://"+signed.Host+signed.Path)
+		assert.Equal(t, "3600", signed.Query().Get("X-Amz-Expires"))
+		assert.NotEmpty(t, signed.Query().Get("X-Amz-Signature"))
+	})
+
+	t.Run("return no URLs by default", func(t *testing.T) {
+		resp, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+		})
+		assert.Nil(t, err)
+		assert.NotContains(t, string(resp.Data), "publicURL")
+	})
+
+	t.Run("return error for invalid signedURLExpiry", func(t *testing.T) {
+		_, err := s.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"key": "foo", "returnSignedURL": "true", "signedURLExpiry": "1000h"},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,340 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// Upload sessions stream an object of unknown size with a multipart upload, see
+// objectstorage.UploadSessions. All the parts but the last one must be at least 5 MiB, so chunks
+// are buffered until partSize bytes were received and sent as parts of that size: the binding
+// holds less than partSize bytes per session between two chunks. A multipart upload has at most
+// 10,000 parts, so a session can upload 10,000 times its part size, about 48.8 GiB with the default
+// 5 MiB: chunks that would go over the limit are rejected. expectedSize on initUpload scales the
+// part size of the session up like for create, when autoScalePartSize is enabled. The multipart
+// uploads of sessions that are never finished can be found with listMultipartUploads and removed
+// with abortMultipartUpload, or with a lifecycle rule of the bucket.
+
+const (
+	initUploadOperation   bindings.OperationKind = "initUpload"
+	uploadChunkOperation  bindings.OperationKind = "uploadChunk"
+	finishUploadOperation bindings.OperationKind = "finishUpload"
+	// Returns the parts uploaded so far for a session or a multipart upload, see uploadStatusResponse
+	getUploadStatusOperation bindings.OperationKind = "getUploadStatus"
+
+	// Offset from which the next chunk of an upload session is expected
+	metadataKeyNextOffset = "nextOffset"
+	// Expected size in bytes of the object of an upload session, to pick its part size
+	metadataKeyExpectedSize = "expectedSize"
+)
+
+// objectUploadSession is the state of an upload session. client is the client of the uploader
+// selected by initUpload, so all the requests of a session use the same addressing style.
+type objectUploadSession struct {
+	client   s3iface.S3API
+	uploadID string
+	partSize int64
+	parts    []*s3.CompletedPart
+	buffer   []byte
+}
+
+type initUploadResponse struct {
+	SessionToken string `json:"sessionToken"`
+	Key          string `json:"key"`
+	UploadID     string `json:"uploadId"`
+	PartSize     int64  `json:"partSize"`
+}
+
+// uploadStatusResponse is the response of getUploadStatus. The data of a session buffered by the
+// binding until a part is complete is not counted.
+type uploadStatusResponse struct {
+	Key        string `json:"key"`
+	UploadID   string `json:"uploadId"`
+	PartCount  int    `json:"partCount"`
+	TotalBytes int64  `json:"totalBytes"`
+}
+
+// initUpload starts a multipart upload for key, generated like the key of create if not set. The
+// object gets the headers, storage class and user metadata of the request, as with create.
+func (s *AWSS3) initUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := s.objectKey(req)
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+	partSize := s.metadata.PartSize
+	if val := req.Metadata[metadataKeyExpectedSize]; val != "" {
+		size, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a number of bytes", metadataKeyExpectedSize, val)
+		}
+		partSize, err = s.partSizeFor(size)
+		if err != nil {
+			return nil, err
+		}
+	}
+	storageClass, err := parseStorageClass(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	userMetadata, err := s.userMetadata(key, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner:  s.expectedBucketOwner(),
+		Key:                  aws.String(key),
+		BucketKeyEnabled:     s.bucketKeyEnabled(),
+		ServerSideEncryption: s.serverSideEncryption(),
+		SSEKMSKeyId:          s.kmsKeyID(),
+		StorageClass:         storageClass,
+		ContentType:          optionalString(req.Metadata[metadataKeyContentType]),
+		ContentEncoding:      optionalString(req.Metadata[metadataKeyContentEncoding]),
+		CacheControl:         optionalString(req.Metadata[metadataKeyCacheControl]),
+		Metadata:             userMetadata,
+	}
+
+	ctx, cancel := s.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	out, err := client.CreateMultipartUploadWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error starting multipart upload of %s: %w", key, err)
+	}
+
+	uploadID := aws.StringValue(out.UploadId)
+	token, err := s.uploadSessions.Start(key, &objectUploadSession{
+		client:   client,
+		uploadID: uploadID,
+		partSize: partSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(initUploadResponse{
+		SessionToken: token,
+		Key:          key,
+		UploadID:     uploadID,
+		PartSize:     partSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling initUpload response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// uploadChunk adds the data of the request at offset of the session, and uploads the parts of
+// the part size of the session that are complete. The session is only updated once all of them were
+// uploaded: the parts of a failed chunk are uploaded again with the same part numbers when it is
+// retried. Chunks that would need more than s3manager.MaxUploadParts parts are rejected before any
+// upload.
+func (s *AWSS3) uploadChunk(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	token := req.Metadata[objectstorage.MetadataKeySessionToken]
+	if token == "" {
+		return nil, fmt.Errorf("%s is a required attribute", objectstorage.MetadataKeySessionToken)
+	}
+	offset, err := objectstorage.ParseOffset(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Data) == 0 {
+		return nil, fmt.Errorf("the data of a chunk can't be empty")
+	}
+
+	session, err := s.uploadSessions.Acquire(token)
+	if err != nil {
+		return nil, err
+	}
+	defer s.uploadSessions.Release(session)
+	if err = session.CheckOffset(offset); err != nil {
+		return nil, err
+	}
+	state := session.State.(*objectUploadSession)
+
+	ctx, cancel := s.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+
+	buffer := make([]byte, 0, len(state.buffer)+len(req.Data))
+	buffer = append(append(buffer, state.buffer...), req.Data...)
+	// The data left in the buffer is uploaded as another part, by a later chunk or by finishUpload
+	needed := (int64(len(buffer)) + state.partSize - 1) / state.partSize
+	if int64(len(state.parts))+needed > s3manager.MaxUploadParts {
+		return nil, fmt.Errorf("upload session of %s would exceed the limit of %d parts of %d bytes, start the session with %s",
+			session.Name, s3manager.MaxUploadParts, state.partSize, metadataKeyExpectedSize)
+	}
+	parts := state.parts
+	for int64(len(buffer)) >= state.partSize {
+		part, err := s.uploadPart(ctx, session.Name, state, len(parts)+1, buffer[:state.partSize])
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		buffer = buffer[state.partSize:]
+	}
+	state.parts = parts
+	state.buffer = append([]byte(nil), buffer...)
+	session.Advance(req.Data)
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			metadataKeyNextOffset: strconv.FormatInt(session.Offset, 10),
+		},
+	}, nil
+}
+
+func (s *AWSS3) uploadPart(ctx aws.Context, key string, state *objectUploadSession, partNumber int, data []byte) (*s3.CompletedPart, error) {
+	out, err := state.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(key),
+		UploadId:            aws.String(state.uploadID),
+		PartNumber:          aws.Int64(int64(partNumber)),
+		Body:                bytes.NewReader(data),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error uploading part %d of %s: %w", partNumber, key, err)
+	}
+
+	return &s3.CompletedPart{ETag: out.ETag, PartNumber: aws.Int64(int64(partNumber))}, nil
+}
+
+// finishUpload uploads the buffered data as the last part and completes the multipart upload. The
+// session is kept if this fails, so that it can be retried.
+func (s *AWSS3) finishUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	token := req.Metadata[objectstorage.MetadataKeySessionToken]
+	if token == "" {
+		return nil, fmt.Errorf("%s is a required attribute", objectstorage.MetadataKeySessionToken)
+	}
+
+	session, err := s.uploadSessions.Acquire(token)
+	if err != nil {
+		return nil, err
+	}
+	state := session.State.(*objectUploadSession)
+	if session.Offset == 0 && !s.metadata.AllowEmpty {
+		s.uploadSessions.Release(session)
+
+		return nil, objectstorage.ErrEmptyData
+	}
+
+	ctx, cancel := s.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+
+	// A multipart upload needs at least one part, which can be empty when it is the only one
+	if len(state.buffer) > 0 || len(state.parts) == 0 {
+		part, err := s.uploadPart(ctx, session.Name, state, len(state.parts)+1, state.buffer)
+		if err != nil {
+			s.uploadSessions.Release(session)
+
+			return nil, err
+		}
+		state.parts = append(state.parts, part)
+		state.buffer = nil
+	}
+
+	out, err := state.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(session.Name),
+		UploadId:            aws.String(state.uploadID),
+		MultipartUpload:     &s3.CompletedMultipartUpload{Parts: state.parts},
+	})
+	if err != nil {
+		s.uploadSessions.Release(session)
+
+		return nil, fmt.Errorf("error completing multipart upload of %s: %w", session.Name, err)
+	}
+	s.uploadSessions.Finish(token, session)
+
+	partETags := make([]string, len(state.parts))
+	for i, part := range state.parts {
+		partETags[i] = aws.StringValue(part.ETag)
+	}
+	b, err := json.Marshal(createResponse{
+		Bucket:    s.metadata.Bucket,
+		Key:       session.Name,
+		Location:  aws.StringValue(out.Location),
+		VersionID: out.VersionId,
+		SHA256:    session.SHA256(),
+		ETag:      aws.StringValue(out.ETag),
+		PartETags: partETags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling create response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// getUploadStatus returns the size of the parts uploaded for the multipart upload of a session, or of
+// key and uploadId, read with ListParts. It doesn't wait for the chunk of the session in progress, if any.
+func (s *AWSS3) getUploadStatus(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	client, _, err := s.selectClient(req)
+	if err != nil {
+		return nil, err
+	}
+	status := uploadStatusResponse{}
+	if token := req.Metadata[objectstorage.MetadataKeySessionToken]; token != "" {
+		session, err := s.uploadSessions.Lookup(token)
+		if err != nil {
+			return nil, err
+		}
+		state := session.State.(*objectUploadSession)
+		client, status.Key, status.UploadID = state.client, session.Name, state.uploadID
+	} else {
+		status.Key, status.UploadID = req.Metadata[metadataKeyKey], req.Metadata[metadataKeyUploadID]
+		if status.Key == "" || status.UploadID == "" {
+			return nil, fmt.Errorf("%s, or %s and %s are required attributes", objectstorage.MetadataKeySessionToken, metadataKeyKey, metadataKeyUploadID)
+		}
+	}
+
+	ctx, cancel := s.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	err = client.ListPartsPagesWithContext(ctx, &s3.ListPartsInput{
+		Bucket:              aws.String(s.metadata.Bucket),
+		ExpectedBucketOwner: s.expectedBucketOwner(),
+		Key:                 aws.String(status.Key),
+		UploadId:            aws.String(status.UploadID),
+	}, func(page *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range page.Parts {
+			status.PartCount++
+			status.TotalBytes += aws.Int64Value(part.Size)
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing parts of multipart upload %s of %s: %w", status.UploadID, status.Key, err)
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling getUploadStatus response for s3: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
@@ -0,0 +1,241 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadSession(t *testing.T) {
+	var lock sync.Mutex
+	parts := map[int][]byte{}
+	completed := false
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		_, initiate := r.URL.Query()["uploads"]
+		switch {
+		case r.Method == http.MethodPost && initiate:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut:
+			n, _ := strconv.Atoi(r.URL.Query().Get("partNumber"))
+			parts[n], _ = ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", fmt.Sprintf("\"part-%d\"", n))
+		case r.Method == http.MethodPost:
+			completed = true
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"composite-2"</ETag></CompleteMultipartUploadResult>`)
+		}
+	}, map[string]string{"partSize": fmt.Sprint(s3manager.MinUploadPartSize)})
+
+	half := int(s3manager.MinUploadPartSize / 2)
+	data := bytes.Repeat([]byte("x"), 2*half+half)
+
+	resp, err := s.Invoke(&bindings.InvokeRequest{
+		Operation: initUploadOperation,
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true"},
+	})
+	assert.Nil(t, err)
+	var started initUploadResponse
+	assert.Nil(t, json.Unmarshal(resp.Data, &started))
+	assert.Equal(t, initUploadResponse{SessionToken: started.SessionToken, Key: "foo", UploadID: "upload", PartSize: s3manager.MinUploadPartSize}, started)
+
+	for offset := 0; offset < len(data); offset += half {
+		resp, err = s.Invoke(&bindings.InvokeRequest{
+			Operation: uploadChunkOperation,
+			Data:      data[offset : offset+half],
+			Metadata:  map[string]string{"sessionToken": started.SessionToken, "offset": strconv.Itoa(offset)},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, strconv.Itoa(offset+half), resp.Metadata["nextOffset"])
+	}
+	// Only the complete part was uploaded, the rest is buffered
+	assert.Len(t, parts, 1)
+	assert.Len(t, parts[1], int(s3manager.MinUploadPartSize))
+
+	_, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: uploadChunkOperation,
+		Data:      []byte("y"),
+		Metadata:  map[string]string{"sessionToken": started.SessionToken, "offset": "0"},
+	})
+	assert.Error(t, err)
+
+	resp, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: finishUploadOperation,
+		Metadata:  map[string]string{"sessionToken": started.SessionToken},
+	})
+	assert.Nil(t, err)
+	assert.True(t, completed)
+	assert.Equal(t, data, append(parts[1], parts[2]...))
+
+	var created createResponse
+	assert.Nil(t, json.Unmarshal(resp.Data, &created))
+	assert.Equal(t, "foo", created.Key)
+	assert.Equal(t, objectstorage.SHA256(data), created.SHA256)
+	assert.Equal(t, []string{"\"part-1\"", "\"part-2\""}, created.PartETags)
+
+	_, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: finishUploadOperation,
+		Metadata:  map[string]string{"sessionToken": started.SessionToken},
+	})
+	assert.Equal(t, objectstorage.ErrUnknownUploadSession, err)
+}
+
+func TestUploadSessionPartSize(t *testing.T) {
+	var puts int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			puts++
+		}
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+	}
+	start := func(t *testing.T, s *AWSS3, metadata map[string]string) (initUploadResponse, error) {
+		metadata["key"] = "foo"
+		metadata["forcePathStyle"] = "true"
+		resp, err := s.Invoke(&bindings.InvokeRequest{Operation: initUploadOperation, Metadata: metadata})
+		var started initUploadResponse
+		if err == nil {
+			assert.Nil(t, json.Unmarshal(resp.Data, &started))
+		}
+
+		return started, err
+	}
+
+	t.Run("scale the part size up for expectedSize", func(t *testing.T) {
+		s := newTestAWSS3(t, handler, nil)
+		started, err := start(t, s, map[string]string{"expectedSize": strconv.FormatInt(s3manager.DefaultUploadPartSize*s3manager.MaxUploadParts+1, 10)})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(s3manager.DefaultUploadPartSize+1), started.PartSize)
+
+		started, err = start(t, s, map[string]string{"expectedSize": "100"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(s3manager.DefaultUploadPartSize), started.PartSize)
+	})
+
+	t.Run("return error for an invalid expectedSize", func(t *testing.T) {
+		s := newTestAWSS3(t, handler, map[string]string{"autoScalePartSize": "false"})
+		for _, val := range []string{"-1", "big", strconv.FormatInt(s3manager.DefaultUploadPartSize*s3manager.MaxUploadParts+1, 10)} {
+			_, err := start(t, s, map[string]string{"expectedSize": val})
+			assert.Error(t, err, val)
+		}
+	})
+
+	t.Run("reject chunks over the part limit", func(t *testing.T) {
+		s := newTestAWSS3(t, handler, nil)
+		started, err := start(t, s, map[string]string{})
+		assert.Nil(t, err)
+
+		session, err := s.uploadSessions.Acquire(started.SessionToken)
+		assert.Nil(t, err)
+		session.State.(*objectUploadSession).parts = make([]*s3.CompletedPart, s3manager.MaxUploadParts)
+		s.uploadSessions.Release(session)
+
+		puts = 0
+		_, err = s.Invoke(&bindings.InvokeRequest{
+			Operation: uploadChunkOperation,
+			Data:      []byte("x"),
+			Metadata:  map[string]string{"sessionToken": started.SessionToken, "offset": "0"},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "10000 parts")
+		assert.Zero(t, puts)
+	})
+}
+
+func TestInitUploadMetadata(t *testing.T) {
+	var header http.Header
+	s := newTestAWSS3(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+	}, nil)
+
+	_, err := s.Invoke(&bindings.InvokeRequest{
+		Operation: initUploadOperation,
+		Metadata: map[string]string{
+			"key":             "foo",
+			"forcePathStyle":  "true",
+			"contentType":     "text/plain",
+			"contentEncoding": "gzip",
+			"cacheControl":    "no-cache",
+			"storageClass":    "STANDARD_IA",
+			"owner":           "dapr",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain", header.Get("Content-Type"))
+	assert.Equal(t, "gzip", header.Get("Content-Encoding"))
+	assert.Equal(t, "no-cache", header.Get("Cache-Control"))
+	assert.Equal(t, "STANDARD_IA", header.Get("X-Amz-Storage-Class"))
+	assert.Equal(t, "dapr", header.Get("X-Amz-Meta-Owner"))
+	assert.Empty(t, header.Get("X-Amz-Meta-Key"))
+	assert.Empty(t, header.Get("X-Amz-Meta-Forcepathstyle"))
+
+	_, err = s.Invoke(&bindings.InvokeRequest{
+		Operation: initUploadOperation,
+		Metadata:  map[string]string{"key": "foo", "forcePathStyle": "true", "storageClass": "COLD"},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetUploadStatus(t *testing.T) {
+	client := &mockS3Client{
+		listPartsPages: func(input *s3.ListPartsInput, fn func(*s3.ListPartsOutput, bool) bool) error {
+			assert.Equal(t, "foo", aws.StringValue(input.Key))
+			assert.Equal(t, "upload", aws.StringValue(input.UploadId))
+			if !fn(&s3.ListPartsOutput{Parts: []*s3.Part{{Size: aws.Int64(10)}, {Size: aws.Int64(10)}}}, false) {
+				return nil
+			}
+			fn(&s3.ListPartsOutput{Parts: []*s3.Part{{Size: aws.Int64(3)}}}, true)
+
+			return nil
+		},
+	}
+	binding := AWSS3{metadata: &s3Metadata{Bucket: "test"}, client: client, uploadSessions: objectstorage.NewUploadSessions(time.Hour)}
+	want := uploadStatusResponse{Key: "foo", UploadID: "upload", PartCount: 3, TotalBytes: 23}
+
+	t.Run("return the parts of a multipart upload", func(t *testing.T) {
+		resp, err := binding.getUploadStatus(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{"key": "foo", "uploadId": "upload"}})
+		assert.Nil(t, err)
+		var status uploadStatusResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &status))
+		assert.Equal(t, want, status)
+	})
+
+	t.Run("return the parts of the multipart upload of a session", func(t *testing.T) {
+		token, err := binding.uploadSessions.Start("foo", &objectUploadSession{client: client, uploadID: "upload"})
+		assert.Nil(t, err)
+
+		resp, err := binding.getUploadStatus(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{"sessionToken": token}})
+		assert.Nil(t, err)
+		var status uploadStatusResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &status))
+		assert.Equal(t, want, status)
+	})
+
+	t.Run("return error without session or upload", func(t *testing.T) {
+		_, err := binding.getUploadStatus(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{"key": "foo"}})
+		assert.Error(t, err)
+
+		_, err = binding.getUploadStatus(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{"sessionToken": "unknown"}})
+		assert.Equal(t, objectstorage.ErrUnknownUploadSession, err)
+	})
+}
@@ -0,0 +1,122 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// Append blobs are made of blocks that can only be added at the end of the blob, e.g. for log
+// lines. They are written with the append operation and read like block blobs.
+// See: https://docs.microsoft.com/en-us/rest/api/storageservices/understanding-block-blobs--append-blobs--and-page-blobs
+
+const (
+	appendOperation bindings.OperationKind = "append"
+
+	// Maximum size in bytes of the append blob, an append that would make it larger fails
+	metadataKeyMaxSize = "maxSize"
+	// Response metadata of append
+	metadataKeyAppendOffset        = "appendOffset"
+	metadataKeyCommittedBlockCount = "committedBlockCount"
+	metadataKeyBlobSize            = "blobSize"
+)
+
+// ErrMaxSizeExceeded is returned when an append would make the blob larger than maxSize.
+var ErrMaxSizeExceeded = errors.New("the append would exceed the maximum size of the blob")
+
+// append adds the data of the request as a block at the end of the append blob, which is created
+// with the content headers and metadata of the request if it doesn't exist. A block holds at most
+// 4 MiB and an append blob at most 50,000 blocks.
+func (a *AzureBlobStorage) append(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+	delete(req.Metadata, metadataKeyBlobName)
+	blobURL := a.containerURL.NewAppendBlobURL(blobName)
+
+	var conditions azblob.AppendBlobAccessConditions
+	if val, ok := req.Metadata[metadataKeyMaxSize]; ok && val != "" {
+		maxSize, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || maxSize <= 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a positive number of bytes", metadataKeyMaxSize, val)
+		}
+		conditions.AppendPositionAccessConditions.IfMaxSizeLessThanOrEqual = maxSize
+		delete(req.Metadata, metadataKeyMaxSize)
+	}
+
+	enc, err := parseRequestEncryption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	blobHTTPHeaders, err := parseBlobHTTPHeaders(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := a.decodeData(blobName, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > azblob.AppendBlobMaxAppendBlockBytes {
+		return nil, fmt.Errorf("the data (%d bytes) exceeds the maximum size of an append block (%d bytes)", len(data), azblob.AppendBlobMaxAppendBlockBytes)
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, enc)
+	resp, err := blobURL.AppendBlock(ctx, bytes.NewReader(data), conditions, nil)
+	if err != nil && isNotFoundError(err) {
+		if a.metadata.PreserveMetadataCase {
+			req.Metadata = withMetadataCase(req.Metadata)
+		}
+		if err = a.fitMetadata(blobName, req.Metadata); err != nil {
+			return nil, err
+		}
+		// The condition keeps a blob created concurrently from being replaced, the append is retried either way
+		_, err = blobURL.Create(ctx, blobHTTPHeaders, req.Metadata, azblob.BlobAccessConditions{
+			ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny},
+		})
+		if err != nil && !isPreconditionFailedError(err) {
+			return nil, fmt.Errorf("error creating append blob: %w", err)
+		}
+		resp, err = blobURL.AppendBlock(ctx, bytes.NewReader(data), conditions, nil)
+	}
+	if err != nil {
+		if isMaxSizeConditionNotMetError(err) {
+			return nil, ErrMaxSizeExceeded
+		}
+
+		return nil, fmt.Errorf("error appending to az blob: %w", err)
+	}
+
+	offset, err := strconv.ParseInt(resp.BlobAppendOffset(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing append offset %q of az blob: %w", resp.BlobAppendOffset(), err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			metadataKeyETag:                string(resp.ETag()),
+			metadataKeyAppendOffset:        strconv.FormatInt(offset, 10),
+			metadataKeyCommittedBlockCount: strconv.FormatInt(int64(resp.BlobCommittedBlockCount()), 10),
+			metadataKeyBlobSize:            strconv.FormatInt(offset+int64(len(data)), 10),
+		},
+	}, nil
+}
+
+func isMaxSizeConditionNotMetError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+
+	return ok && azureError.ServiceCode() == azblob.ServiceCodeMaxBlobSizeConditionNotMet
+}
@@ -0,0 +1,75 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppend(t *testing.T) {
+	t.Run("create the blob and append the block", func(t *testing.T) {
+		var requests []string
+		exists := false
+		blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			comp := r.URL.Query().Get("comp")
+			requests = append(requests, comp+r.Header.Get("x-ms-blob-type"))
+			switch {
+			case comp == "appendblock" && !exists:
+				w.Header().Set("x-ms-error-code", "BlobNotFound")
+				w.WriteHeader(http.StatusNotFound)
+			case comp == "appendblock":
+				w.Header().Set("ETag", "\"etag\"")
+				w.Header().Set("x-ms-blob-append-offset", "0")
+				w.Header().Set("x-ms-blob-committed-block-count", "1")
+				w.WriteHeader(http.StatusCreated)
+			default:
+				exists = true
+				w.WriteHeader(http.StatusCreated)
+			}
+		}))
+
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: appendOperation,
+			Data:      []byte("line\n"),
+			Metadata:  map[string]string{"blobName": "log.txt"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"appendblock", "AppendBlob", "appendblock"}, requests)
+		assert.Equal(t, map[string]string{
+			"etag":                "\"etag\"",
+			"appendOffset":        "0",
+			"committedBlockCount": "1",
+			"blobSize":            "5",
+		}, resp.Metadata)
+	})
+
+	t.Run("return error when the blob would exceed maxSize", func(t *testing.T) {
+		blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "4", r.Header.Get("x-ms-blob-condition-maxsize"))
+			w.Header().Set("x-ms-error-code", "MaxBlobSizeConditionNotMet")
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}))
+
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: appendOperation,
+			Data:      []byte("line\n"),
+			Metadata:  map[string]string{"blobName": "log.txt", "maxSize": "4"},
+		})
+		assert.Equal(t, ErrMaxSizeExceeded, err)
+	})
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+		_, err := blobStorage.append(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{}})
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+}
@@ -0,0 +1,312 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+const (
+	batchGetOperation    bindings.OperationKind = "batchGet"
+	batchCreateOperation bindings.OperationKind = "batchCreate"
+	deleteBatchOperation bindings.OperationKind = "deleteBatch"
+
+	// Maximum aggregate size in bytes of the blobs returned by a batchGet
+	metadataKeyBatchGetMaxSize = "batchGetMaxSize"
+	defaultBatchGetMaxSize     = 64 * 1024 * 1024
+	// Defines if batchCreate stops uploading the remaining items after the first error
+	metadataKeyFailFast = "failFast"
+	// Maximum number of blobs deleted concurrently by a deleteBatch, defaults to defaultBatchConcurrency
+	metadataKeyConcurrency = "concurrency"
+)
+
+// batchGetResult is the result for a blob of batchGet. Data is base64 encoded in the JSON response.
+type batchGetResult struct {
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// batchCreateItem is a blob to upload with batchCreate. Data is base64 encoded in the JSON payload.
+type batchCreateItem struct {
+	Name        string            `json:"name"`
+	Data        []byte            `json:"data"`
+	ContentType string            `json:"contentType"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// batchCreateResult is the result for an item of batchCreate, in the order of the payload.
+type batchCreateResult struct {
+	Name    string `json:"name"`
+	BlobURL string `json:"blobURL,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// batchDeleteResult is the result for a blob of deleteBatch, in the order of the payload. The blobs
+// with an error can be deleted again with another deleteBatch.
+type batchDeleteResult struct {
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+	// Also set for deleted blobs that consistentDelete couldn't confirm
+	Error string `json:"error,omitempty"`
+}
+
+// sizeBudget bounds the aggregate size of the blobs downloaded concurrently by a batch operation.
+type sizeBudget struct {
+	lock      sync.Mutex
+	remaining int64
+}
+
+// reserve takes size bytes from the budget, returning false if they don't fit.
+func (b *sizeBudget) reserve(size int64) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if size > b.remaining {
+		return false
+	}
+	b.remaining -= size
+
+	return true
+}
+
+func (a *AzureBlobStorage) batchGet(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobNames []string
+	err := json.Unmarshal(req.Data, &blobNames)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing batchGet payload, expected a json array of blob names: %w", err)
+	}
+	if err = a.validateNames(blobNames...); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	ctx = withRetryBudget(ctx, objectstorage.NewRetryBudget(a.metadata.RetryBudget))
+
+	budget := &sizeBudget{remaining: a.metadata.BatchGetMaxSize}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]batchGetResult, len(blobNames))
+	sem := make(chan struct{}, defaultBatchConcurrency)
+	for _, name := range blobNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := a.getBlobData(ctx, name, budget)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling batchGet response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// getBlobData downloads a blob if its size fits in the remaining budget.
+func (a *AzureBlobStorage) getBlobData(ctx context.Context, name string, budget *sizeBudget) batchGetResult {
+	resp, err := a.getBlobURL(name).Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		if isNotFoundError(err) {
+			return batchGetResult{Error: ErrBlobNotFound.Error()}
+		}
+
+		return batchGetResult{Error: err.Error()}
+	}
+
+	body := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: a.metadata.GetBlobRetryCount})
+	defer body.Close()
+
+	if !budget.reserve(resp.ContentLength()) {
+		return batchGetResult{Error: fmt.Sprintf("blob of %d bytes exceeds the remaining %s of the batch", resp.ContentLength(), metadataKeyBatchGetMaxSize)}
+	}
+
+	b := bytes.Buffer{}
+	_, err = b.ReadFrom(body)
+	if err != nil {
+		return batchGetResult{Error: fmt.Sprintf("error reading az blob body: %v", err)}
+	}
+
+	return batchGetResult{Data: b.Bytes()}
+}
+
+// batchCreate uploads the items concurrently. Uploads are not transactional: with failFast the items
+// that were not started when an upload failed are skipped, but the ones already uploaded are kept.
+func (a *AzureBlobStorage) batchCreate(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var items []batchCreateItem
+	err := json.Unmarshal(req.Data, &items)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing batchCreate payload, expected a json array of items: %w", err)
+	}
+	for i, item := range items {
+		if item.Name == "" {
+			return nil, fmt.Errorf("name is required for item %d of batchCreate", i)
+		}
+		if err = a.validateNames(item.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	failFast, err := req.GetMetadataAsBool(metadataKeyFailFast)
+	if err != nil {
+		return nil, err
+	}
+
+	writeCtx, cancelWrite := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancelWrite()
+	ctx, cancel := context.WithCancel(withRetryBudget(writeCtx, objectstorage.NewRetryBudget(a.metadata.RetryBudget)))
+	defer cancel()
+
+	var wg sync.WaitGroup
+	results := make([]batchCreateResult, len(items))
+	sem := make(chan struct{}, defaultBatchConcurrency)
+	for i, item := range items {
+		results[i].Name = item.Name
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			results[i].Error = "skipped after a previous error"
+
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *batchCreateResult, item batchCreateItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			metadata := item.Metadata
+			if a.metadata.PreserveMetadataCase {
+				metadata = withMetadataCase(metadata)
+			}
+			blobURL := a.getBlobURL(item.Name)
+			_, err := azblob.UploadBufferToBlockBlob(ctx, item.Data, blobURL, azblob.UploadToBlockBlobOptions{
+				Metadata:        metadata,
+				BlobHTTPHeaders: azblob.BlobHTTPHeaders{ContentType: item.ContentType},
+			})
+			if err != nil {
+				result.Error = fmt.Sprintf("error uploading az blob: %v", err)
+				if failFast {
+					cancel()
+				}
+
+				return
+			}
+			result.BlobURL = blobURL.String()
+		}(&results[i], item)
+	}
+	wg.Wait()
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling batchCreate response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// deleteBatch deletes the blobs concurrently. A failed delete doesn't stop the others, its error is
+// reported in the result of the blob.
+func (a *AzureBlobStorage) deleteBatch(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobNames []string
+	err := json.Unmarshal(req.Data, &blobNames)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing deleteBatch payload, expected a json array of blob names: %w", err)
+	}
+	for i, name := range blobNames {
+		if name == "" {
+			return nil, fmt.Errorf("blob name %d of deleteBatch is empty", i)
+		}
+	}
+	if err = a.validateNames(blobNames...); err != nil {
+		return nil, err
+	}
+
+	deleteSnapshotsOptions, err := a.parseDeleteSnapshotsOption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := defaultBatchConcurrency
+	if val, ok := req.Metadata[metadataKeyConcurrency]; ok && val != "" {
+		concurrency, err = strconv.Atoi(val)
+		if err != nil || concurrency <= 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a positive number", metadataKeyConcurrency, val)
+		}
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	ctx = withRetryBudget(ctx, objectstorage.NewRetryBudget(a.metadata.RetryBudget))
+
+	var wg sync.WaitGroup
+	results := make([]batchDeleteResult, len(blobNames))
+	sem := make(chan struct{}, concurrency)
+	for i, name := range blobNames {
+		results[i].Name = name
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(result *batchDeleteResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			_, err := a.getBlobURL(result.Name).Delete(ctx, deleteSnapshotsOptions, azblob.BlobAccessConditions{})
+			if err != nil {
+				if isNotFoundError(err) {
+					result.Error = ErrBlobNotFound.Error()
+				} else {
+					result.Error = fmt.Sprintf("error deleting az blob: %v", err)
+				}
+
+				return
+			}
+			result.Deleted = true
+			if deleteSnapshotsOptions == azblob.DeleteSnapshotsOptionOnly {
+				return
+			}
+			// The blob was deleted, but callers listing the container may still see it
+			if err = a.metadata.DeleteConsistency.WaitUntilGone(ctx, blobGone(a.getBlobURL(result.Name))); err != nil {
+				result.Error = err.Error()
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling deleteBatch response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
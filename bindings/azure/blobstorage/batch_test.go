@@ -0,0 +1,142 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeBudget(t *testing.T) {
+	budget := &sizeBudget{remaining: 10}
+
+	assert.True(t, budget.reserve(6))
+	assert.False(t, budget.reserve(5))
+	assert.True(t, budget.reserve(4))
+	assert.False(t, budget.reserve(1))
+}
+
+func TestBatchGetOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{}
+
+	t.Run("return error for invalid payload", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data: []byte(`{"blobName": "foo"}`),
+		}
+		_, err := blobStorage.batchGet(context.Background(), &r)
+		assert.Error(t, err)
+	})
+}
+
+func TestBatchCreateOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{}
+
+	t.Run("return error for invalid payload", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data: []byte(`{"name": "foo"}`),
+		}
+		_, err := blobStorage.batchCreate(context.Background(), &r)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for item without name", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data: []byte(`[{"name": "foo", "data": "ZGF0YQ=="}, {"data": "ZGF0YQ=="}]`),
+		}
+		_, err := blobStorage.batchCreate(context.Background(), &r)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for invalid failFast", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data:     []byte(`[{"name": "foo", "data": "ZGF0YQ=="}]`),
+			Metadata: map[string]string{"failFast": "maybe"},
+		}
+		_, err := blobStorage.batchCreate(context.Background(), &r)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteBatch(t *testing.T) {
+	t.Run("delete the blobs and report the failures", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+
+			assert.Equal(t, http.MethodDelete, r.Method)
+			assert.Equal(t, "include", r.Header.Get("x-ms-delete-snapshots"))
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/missing"):
+				w.Header().Set("x-ms-error-code", "BlobNotFound")
+				w.WriteHeader(http.StatusNotFound)
+			case strings.HasSuffix(r.URL.Path, "/leased"):
+				w.Header().Set("x-ms-error-code", "LeaseIdMissing")
+				w.WriteHeader(http.StatusPreconditionFailed)
+			default:
+				w.WriteHeader(http.StatusAccepted)
+			}
+		}))
+
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: deleteBatchOperation,
+			Data:      []byte(`["foo", "missing", "leased", "bar"]`),
+			Metadata:  map[string]string{"deleteSnapshots": "include", "concurrency": "2"},
+		})
+		assert.Nil(t, err)
+
+		var results []batchDeleteResult
+		assert.Nil(t, json.Unmarshal(resp.Data, &results))
+		assert.Len(t, results, 4)
+		assert.Equal(t, batchDeleteResult{Name: "foo", Deleted: true}, results[0])
+		assert.Equal(t, batchDeleteResult{Name: "missing", Error: ErrBlobNotFound.Error()}, results[1])
+		assert.Equal(t, "leased", results[2].Name)
+		assert.False(t, results[2].Deleted)
+		assert.Contains(t, results[2].Error, "LeaseIdMissing")
+		assert.Equal(t, batchDeleteResult{Name: "bar", Deleted: true}, results[3])
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+	})
+
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{}
+
+	t.Run("return error for invalid payload", func(t *testing.T) {
+		_, err := blobStorage.deleteBatch(context.Background(), &bindings.InvokeRequest{Data: []byte(`{"blobName": "foo"}`)})
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for invalid concurrency", func(t *testing.T) {
+		_, err := blobStorage.deleteBatch(context.Background(), &bindings.InvokeRequest{
+			Data:     []byte(`["foo"]`),
+			Metadata: map[string]string{"concurrency": "0"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for invalid deleteSnapshots", func(t *testing.T) {
+		_, err := blobStorage.deleteBatch(context.Background(), &bindings.InvokeRequest{
+			Data:     []byte(`["foo"]`),
+			Metadata: map[string]string{"deleteSnapshots": "invalid"},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -12,13 +12,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	azauth "github.com/dapr/components-contrib/authentication/azure"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
 	"github.com/dapr/kit/logger"
-	"github.com/google/uuid"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
 )
 
 const (
@@ -33,6 +44,13 @@ const (
 	metadataKeyNumber = "number"
 	// Defines if the user defined metadata should be returned in the get operation
 	metadataKeyIncludeMetadata = "includeMetadata"
+	// Defines how the get operation handles a missing blob, either "error" (default) or "empty". With "empty"
+	// the operation returns no data and sets the notFound response metadata to "true".
+	metadataKeyMissingObjectBehavior = "missingObjectBehavior"
+	metadataKeyNotFound              = "notFound"
+	// IANA name of the text encoding the blob is stored with, e.g. "ISO-8859-1" or "UTF-16LE". When set, the get
+	// operation transcodes the blob content to UTF-8.
+	metadataKeySourceEncoding = "sourceEncoding"
 	// Defines the delete snapshots option for the delete operation.
 	// See: https://docs.microsoft.com/en-us/rest/api/storageservices/delete-blob#request-headers
 	metadataKeyDeleteSnapshots = "deleteSnapshots"
@@ -44,13 +62,67 @@ const (
 	metadataKeyContentLanguage    = "contentLanguage"
 	metadataKeyContentDisposition = "contentDisposition"
 	meatdataKeyCacheControl       = "cacheControl"
+	// Content-Range style value ("bytes <start>-<end>/<total>") used to upload a blob in several create calls.
+	metadataKeyContentRange = "contentRange"
+	// Defines if create only validates the request and the access to the container, without uploading.
+	metadataKeyValidateOnly = "validateOnly"
+	// Offset from which a resumable upload continues, returned in the create response metadata.
+	metadataKeyNextOffset = "nextOffset"
+	// How long the state of an incomplete resumable upload is kept after its last range, e.g. "30m".
+	metadataKeyResumableUploadTTL = "resumableUploadTTL"
+	// Maximum time a download attempt may go without receiving data before it is abandoned and retried, e.g. "30s".
+	// Retries are bounded by getBlobRetryCount.
+	metadataKeyDownloadTryTimeout = "downloadTryTimeout"
+	// Conditions of the get operation, the blob is only downloaded if it was modified after the
+	// date (RFC 1123 or RFC 3339) or if its ETag doesn't match
+	metadataKeyIfModifiedSince = "ifModifiedSince"
+	metadataKeyIfNoneMatch     = "ifNoneMatch"
+	// Conditions of the create operation, the blob is only written if it holds the lease, if its
+	// ETag matches (or doesn't match, "*" to only create new blobs) and if it wasn't modified since
+	// the date (RFC 1123 or RFC 3339)
+	metadataKeyLeaseID           = "leaseId"
+	metadataKeyIfMatch           = "ifMatch"
+	metadataKeyIfUnmodifiedSince = "ifUnmodifiedSince"
+	// ETag of the blob returned by get, to use as ifNoneMatch on the next request
+	metadataKeyETag = "etag"
+	// Last modified time of the blob returned by get, in the HTTP date format. The content type,
+	// content MD5 (when the blob has one) and objectstorage.MetadataKeyContentLength are returned as
+	// well; these keys of the get response take precedence over user metadata with the same names.
+	metadataKeyLastModified = "lastModified"
+	// JSON object of headers set on every request sent to the storage account
+	metadataKeyRequestHeaders = "requestHeaders"
+	// JSON object of the monthly storage cost per GiB of each access tier, e.g. {"Hot": 0.0184}, used to
+	// estimate the cost of the blobs listed with groupByTier. Rates depend on the region and contract.
+	metadataKeyTierCostPerGB = "tierCostPerGB"
+	// Defines if create fails when decodeBase64 is enabled and the data isn't valid base64. When false
+	// the data is stored as-is.
+	metadataKeyStrictBase64 = "strictBase64"
+	// Defines if Init creates the container when it doesn't exist. When false, Init doesn't try to
+	// create the container and fails if it doesn't exist, for credentials that can't create containers.
+	metadataKeyCreateContainer = "createContainer"
+	// Alias of createContainer. Init fails if both are set to different values.
+	metadataKeyCreateContainerIfNotExists = "createContainerIfNotExists"
 	// Specifies the maximum number of HTTP GET requests that will be made while reading from a RetryReader. A value
 	// of zero means that no additional HTTP GET requests will be made
 	defaultGetBlobRetryCount = 10
+	// Default time an incomplete resumable upload is tracked for
+	defaultResumableUploadTTL = time.Hour
 	// Specifies the maximum number of blobs to return, including all BlobPrefix elements. If the request does not
 	// specify maxresults the server will return up to 5,000 items.
 	// See: https://docs.microsoft.com/en-us/rest/api/storageservices/list-blobs#uri-parameters
 	maxResults = 5000
+	// Maximum number of blobs processed concurrently by batch operations
+	defaultBatchConcurrency = 16
+	// Default number of blocks uploaded concurrently by create
+	defaultUploadParallelism = 16
+	// Maximum size of the names and values of the metadata of a blob
+	maxMetadataSize = 8 * 1024
+	// Prefix of the headers of the object replication properties of a blob
+	objectReplicationHeaderPrefix = "x-ms-or-"
+	// Header of the version of a blob created in an account with versioning enabled
+	versionIDHeader = "x-ms-version-id"
+	// Provider of objectstorage.CanonicalResponse and of the metrics
+	canonicalResponseProvider = "azure.blobstorage"
 
 	// TODO: remove the pascal case support when the component moves to GA
 	// See: https://github.com/dapr/components-contrib/pull/999#issuecomment-876890210
@@ -63,27 +135,140 @@ const (
 	metadataKeyDeleteSnapshotOptionsBC = "DeleteSnapshotOptions"
 )
 
-var ErrMissingBlobName = errors.New("blobName is a required attribute")
+const (
+	batchHeadOperation      bindings.OperationKind = "batchHead"
+	listContainersOperation bindings.OperationKind = "listContainers"
+	previewOperation        bindings.OperationKind = "preview"
+	touchOperation          bindings.OperationKind = "touch"
+
+	missingObjectBehaviorError = "error"
+	missingObjectBehaviorEmpty = "empty"
+)
+
+// Metadata names must be C# identifiers.
+// See: https://docs.microsoft.com/en-us/rest/api/storageservices/naming-and-referencing-containers--blobs--and-metadata#metadata-names
+var metadataNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var (
+	ErrMissingBlobName = errors.New("blobName is a required attribute")
+	ErrBlobNotFound    = errors.New("blob not found")
+	ErrNotModified     = errors.New("blob not modified")
+	// ErrPreconditionFailed is returned when the access conditions of a write are not met
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrBlobAlreadyExists is returned by writes with ifNoneMatch "*" when the blob exists. It wraps
+	// ErrPreconditionFailed.
+	ErrBlobAlreadyExists = fmt.Errorf("blob already exists: %w", ErrPreconditionFailed)
+)
 
 // AzureBlobStorage allows saving blobs to an Azure Blob Storage account
 type AzureBlobStorage struct {
-	metadata     *blobStorageMetadata
-	containerURL azblob.ContainerURL
+	metadata       *blobStorageMetadata
+	containerURL   azblob.ContainerURL
+	pipeline       pipeline.Pipeline
+	partialUploads *partialUploads
+	uploadSessions *objectstorage.UploadSessions
+	// Set for shared key credentials, which can sign SAS URLs
+	sharedKeyCredential *azblob.SharedKeyCredential
+	metrics             bindings.Metrics
+	// Sends the requests of the pipeline, the default HTTP client if nil
+	httpSender pipeline.Factory
+
+	// Cached result of the hierarchical namespace detection
+	hnsLock    sync.Mutex
+	hnsEnabled *bool
 
 	logger logger.Logger
 }
 
 type blobStorageMetadata struct {
-	StorageAccount    string                  `json:"storageAccount"`
-	StorageAccessKey  string                  `json:"storageAccessKey"`
+	StorageAccount   string `json:"storageAccount"`
+	StorageAccessKey string `json:"storageAccessKey"`
+	// Blob service endpoint, e.g. "http://127.0.0.1:10000" for Azurite. The container is addressed
+	// as <endpoint>/<storageAccount>/<container>. Defaults to the endpoint of azureEnvironment.
+	Endpoint string `json:"endpoint"`
+	// Data Lake Storage endpoint of the directory operations, addressed like endpoint. It is
+	// derived from the blob endpoint when its host has a "blob" label, e.g. account.blob.core.windows.net,
+	// and must be set otherwise, e.g. for Azurite or private endpoints.
+	DfsEndpoint       string                  `json:"dfsEndpoint"`
 	Container         string                  `json:"container"`
 	GetBlobRetryCount int                     `json:"getBlobRetryCount,string"`
 	DecodeBase64      bool                    `json:"decodeBase64,string"`
 	PublicAccessLevel azblob.PublicAccessType `json:"publicAccessLevel"`
+	// Template for the names of blobs created without a blobName, see objectstorage.GenerateKey
+	KeyTemplate string `json:"keyTemplate"`
+	// When true, the extension for the contentType of the request is appended to blob names that don't have one
+	AppendExtensionFromContentType bool `json:"appendExtensionFromContentType,string"`
+	// When true, Init reads the container properties and fails if the container can't be accessed
+	ValidateOnInit bool `json:"validateOnInit,string"`
+	// When true, the responses report the number of requests sent to the service in
+	// objectstorage.MetadataKeyBilledRequests
+	ReturnBilledRequests bool `json:"returnBilledRequests,string"`
+	// Parsed from metadataKeyResumableUploadTTL
+	ResumableUploadTTL time.Duration `json:"-"`
+	// Parsed from metadataKeyDownloadTryTimeout
+	DownloadTryTimeout time.Duration `json:"-"`
+	// Parsed from metadataKeyMaxTries, metadataKeyTryTimeout, metadataKeyRetryDelay and metadataKeyMaxRetryDelay
+	Retry azblob.RetryOptions `json:"-"`
+	// Parsed from metadataKeyStrictBase64, defaults to true
+	StrictBase64 bool `json:"-"`
+	// Parsed from metadataKeyCreateContainer or metadataKeyCreateContainerIfNotExists, defaults to true
+	CreateContainer bool `json:"-"`
+	// Parsed from objectstorage.MetadataKeyAllowEmpty, defaults to true
+	AllowEmpty bool `json:"-"`
+	// Parsed from objectstorage.MetadataKeyOperationTimeout, MetadataKeyReadTimeout and MetadataKeyWriteTimeout
+	Timeouts objectstorage.Timeouts `json:"-"`
+	// Parsed from objectstorage.MetadataKeyAllowedWriteWindow, nil if writes are always allowed
+	WriteWindow *objectstorage.WriteWindow `json:"-"`
+	// Parsed from objectstorage.MetadataKeyConsistentDelete, nil if deletes aren't checked
+	DeleteConsistency *objectstorage.DeleteConsistency `json:"-"`
+	// Parsed from objectstorage.MetadataKeyProgressLogInterval
+	ProgressLogInterval time.Duration `json:"-"`
+	// Parsed from objectstorage.MetadataKeyUploadSessionTTL
+	UploadSessionTTL time.Duration `json:"-"`
+	// When true, create returns an objectstorage.CanonicalResponse instead of createResponse
+	CanonicalResponse bool `json:"canonicalResponse,string"`
+	// When true, blob and directory names are rejected if they could resolve outside of their path,
+	// see objectstorage.ValidateKey
+	StrictKeyValidation bool `json:"strictKeyValidation,string"`
+	// When true, the case of metadata names is restored when reading the metadata of blobs written
+	// by the binding, see withMetadataCase
+	PreserveMetadataCase bool `json:"preserveMetadataCase,string"`
+	// Parsed from metadataKeyRequestHeaders
+	RequestHeaders map[string]string `json:"-"`
+	// Parsed from metadataKeyTierCostPerGB
+	TierCostPerGB map[string]float64 `json:"-"`
+	// Maximum number of retries of all the requests of a batch operation, unlimited if 0
+	RetryBudget int `json:"retryBudget,string"`
+	// Maximum aggregate size in bytes of the blobs returned by batchGet
+	BatchGetMaxSize int64 `json:"batchGetMaxSize,string"`
+	// When true, the metadata of blobs over maxMetadataSize is truncated instead of rejected, see
+	// objectstorage.FitMetadata
+	TruncateMetadata bool `json:"truncateMetadata,string"`
+	// Number of blocks uploaded concurrently by create, defaults to defaultUploadParallelism
+	UploadParallelism uint16 `json:"uploadParallelism,string"`
+	// Size in bytes of the blocks uploaded by create, chosen by the SDK if 0. Data that fits in a
+	// single request (up to azblob.BlockBlobMaxUploadBlobBytes) is uploaded without blocks.
+	BlockSize int64 `json:"blockSize,string"`
 }
 
 type createResponse struct {
 	BlobURL string `json:"blobURL"`
+	// Hex encoded SHA-256 digest of the uploaded data, not set for range uploads
+	SHA256 string `json:"sha256,omitempty"`
+	// URL of the thumbnail blob, set when generateThumbnail is set for an image
+	ThumbnailURL string `json:"thumbnailURL,omitempty"`
+	// Set when returnSignedURL is set
+	*objectstorage.ObjectURLs
+}
+
+type touchResponse struct {
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+type validateOnlyResponse struct {
+	BlobURL string `json:"blobURL"`
+	Size    int64  `json:"size"`
 }
 
 type listInclude struct {
@@ -99,11 +284,65 @@ type listPayload struct {
 	Prefix     string      `json:"prefix"`
 	MaxResults int32       `json:"maxResults"`
 	Include    listInclude `json:"include"`
+	// When true, a single segment of at most maxResults blobs is returned. The next segment is requested
+	// by passing the returned marker, which is empty once the listing is complete.
+	StreamPages bool `json:"streamPages"`
+	// When true, the response is a summary of the number of blobs and bytes per access tier instead
+	// of the blobs
+	GroupByTier bool `json:"groupByTier"`
+	// When set, the blobs are listed as a hierarchy, see listHierarchy
+	Delimiter string `json:"delimiter"`
+	// Number of levels of virtual directories listed with a delimiter, 1 by default. maxResults caps
+	// the items of all the levels, see expandPrefixes.
+	MaxDepth int `json:"maxDepth"`
+	// Cursor returned by a previous list to resume from, see objectstorage.ListCursor. The prefix
+	// and maxResults of the cursor are used when they are not set.
+	Cursor string `json:"cursor"`
+}
+
+type tierSummary struct {
+	Count int64 `json:"count"`
+	Bytes int64 `json:"bytes"`
+	// Monthly storage cost of the blobs, only set for the tiers of tierCostPerGB
+	EstimatedMonthlyCost *float64 `json:"estimatedMonthlyCost,omitempty"`
+}
+
+type containerItem struct {
+	Name         string    `json:"name"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+type batchHeadResult struct {
+	Size         int64      `json:"size"`
+	ContentType  string     `json:"contentType,omitempty"`
+	ETag         string     `json:"etag,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	CreationTime *time.Time `json:"creationTime,omitempty"`
+	// Last time the access tier of the blob was changed, not set if it never changed
+	AccessTierChangeTime *time.Time `json:"accessTierChangeTime,omitempty"`
+	// Object replication properties, "policy-id" on destination blobs and "<policy id>_<rule id>" with
+	// the replication status on source blobs
+	ObjectReplication map[string]string `json:"objectReplication,omitempty"`
+	Error             string            `json:"error,omitempty"`
 }
 
 // NewAzureBlobStorage returns a new Azure Blob Storage instance
 func NewAzureBlobStorage(logger logger.Logger) *AzureBlobStorage {
-	return &AzureBlobStorage{logger: logger}
+	return NewAzureBlobStorageWithMetrics(logger, bindings.NoopMetrics)
+}
+
+// NewAzureBlobStorageWithMetrics returns a new Azure Blob Storage instance reporting the metrics of
+// its operations to metrics.
+func NewAzureBlobStorageWithMetrics(logger logger.Logger, metrics bindings.Metrics) *AzureBlobStorage {
+	if metrics == nil {
+		metrics = bindings.NoopMetrics
+	}
+
+	return &AzureBlobStorage{
+		partialUploads: newPartialUploads(),
+		metrics:        metrics,
+		logger:         logger,
+	}
 }
 
 // Init performs metadata parsing
@@ -113,27 +352,75 @@ func (a *AzureBlobStorage) Init(metadata bindings.Metadata) error {
 		return err
 	}
 	a.metadata = m
+	a.uploadSessions = objectstorage.NewUploadSessions(m.UploadSessionTTL)
 
-	credential, err := azblob.NewSharedKeyCredential(m.StorageAccount, m.StorageAccessKey)
+	// Shared key credentials are used when storageAccessKey is set, Azure AD otherwise
+	credential, env, err := azauth.GetAzureStorageCredentials(a.logger, m.StorageAccount, storageCredentialProperties(metadata.Properties, m.StorageAccessKey))
 	if err != nil {
 		return fmt.Errorf("invalid credentials with error: %w", err)
 	}
-	p := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+	a.sharedKeyCredential, _ = credential.(*azblob.SharedKeyCredential)
+	p := newPipeline(credential, azblob.PipelineOptions{Retry: m.Retry, HTTPSender: a.httpSender},
+		newRequestEncryptionPolicyFactory(), newRequestHeadersPolicyFactory(m.RequestHeaders), newRetryBudgetPolicyFactory(),
+		newRehydratePriorityPolicyFactory(), newRequestCountPolicyFactory())
 
 	containerName := a.metadata.Container
-	URL, _ := url.Parse(
-		fmt.Sprintf("https://%s.blob.core.windows.net/%s", m.StorageAccount, containerName))
+	rawURL := fmt.Sprintf("https://%s.blob.%s/%s", m.StorageAccount, env.StorageEndpointSuffix, containerName)
+	if m.Endpoint != "" {
+		rawURL = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(m.Endpoint, "/"), m.StorageAccount, containerName)
+	}
+	URL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid container URL %q: %w", rawURL, err)
+	}
 	containerURL := azblob.NewContainerURL(*URL, p)
+	a.pipeline = p
 
 	ctx := context.Background()
-	_, err = containerURL.Create(ctx, azblob.Metadata{}, m.PublicAccessLevel)
-	// Don't return error, container might already exist
-	a.logger.Debugf("error creating container: %w", err)
+	if m.CreateContainer {
+		_, err = containerURL.Create(ctx, azblob.Metadata{}, m.PublicAccessLevel)
+		if err != nil {
+			// The container may already exist, or be created concurrently by another instance
+			if !isContainerAlreadyExistsError(err) {
+				return fmt.Errorf("error creating container %s: %w", containerName, err)
+			}
+			a.logger.Debugf("container %s already exists", containerName)
+		}
+	} else {
+		_, err = containerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+		if err != nil {
+			if isNotFoundResponse(err, azblob.ServiceCodeContainerNotFound) {
+				return fmt.Errorf("container %s does not exist and %s is false", containerName, metadataKeyCreateContainer)
+			}
+
+			return fmt.Errorf("error validating access to container %s: %w", containerName, err)
+		}
+	}
 	a.containerURL = containerURL
 
+	// Already validated when the container isn't created
+	if m.ValidateOnInit && m.CreateContainer {
+		_, err = containerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+		if err != nil {
+			return fmt.Errorf("error validating access to container %s: %w", containerName, err)
+		}
+	}
+
 	return nil
 }
 
+// storageCredentialProperties returns the properties for azauth.GetAzureStorageCredentials, which
+// reads the account key from accountKey rather than storageAccessKey.
+func storageCredentialProperties(properties map[string]string, accessKey string) map[string]string {
+	result := make(map[string]string, len(properties)+1)
+	for k, v := range properties {
+		result[k] = v
+	}
+	result["accountKey"] = accessKey
+
+	return result
+}
+
 func (a *AzureBlobStorage) parseMetadata(metadata bindings.Metadata) (*blobStorageMetadata, error) {
 	connInfo := metadata.Properties
 	b, err := json.Marshal(connInfo)
@@ -151,6 +438,103 @@ func (a *AzureBlobStorage) parseMetadata(metadata bindings.Metadata) (*blobStora
 		m.GetBlobRetryCount = defaultGetBlobRetryCount
 	}
 
+	if m.BatchGetMaxSize == 0 {
+		m.BatchGetMaxSize = defaultBatchGetMaxSize
+	}
+
+	if m.UploadParallelism == 0 {
+		m.UploadParallelism = defaultUploadParallelism
+	}
+
+	if m.BlockSize < 0 || m.BlockSize > azblob.BlockBlobMaxStageBlockBytes {
+		return nil, fmt.Errorf("invalid blockSize %d, expected a size between 1 and %d bytes, or 0 for the default of the SDK", m.BlockSize, azblob.BlockBlobMaxStageBlockBytes)
+	}
+
+	m.ResumableUploadTTL, err = objectstorage.ParseDuration(connInfo, metadataKeyResumableUploadTTL, defaultResumableUploadTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	m.DownloadTryTimeout, err = objectstorage.ParseDuration(connInfo, metadataKeyDownloadTryTimeout, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Retry, err = parseRetryOptions(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Timeouts, err = objectstorage.ParseTimeouts(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	m.WriteWindow, err = objectstorage.ParseWriteWindow(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	m.DeleteConsistency, err = objectstorage.ParseDeleteConsistency(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ProgressLogInterval, err = objectstorage.ParseProgressLogInterval(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	m.UploadSessionTTL, err = objectstorage.ParseUploadSessionTTL(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	m.AllowEmpty, err = objectstorage.ParseAllowEmpty(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := connInfo[metadataKeyRequestHeaders]; ok && val != "" {
+		err = json.Unmarshal([]byte(val), &m.RequestHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s, expected a json object of header names and values: %w", metadataKeyRequestHeaders, err)
+		}
+	}
+
+	if val, ok := connInfo[metadataKeyTierCostPerGB]; ok && val != "" {
+		err = json.Unmarshal([]byte(val), &m.TierCostPerGB)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s, expected a json object of access tiers and costs: %w", metadataKeyTierCostPerGB, err)
+		}
+	}
+
+	m.StrictBase64 = true
+	if val, ok := connInfo[metadataKeyStrictBase64]; ok && val != "" {
+		m.StrictBase64, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", metadataKeyStrictBase64, err)
+		}
+	}
+
+	m.CreateContainer, err = parseCreateContainer(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	validateName := true
+	if val, ok := connInfo[metadataKeyValidateContainerName]; ok && val != "" {
+		validateName, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", metadataKeyValidateContainerName, err)
+		}
+	}
+	if validateName && m.Container != "" {
+		if err = validateContainerName(m.Container); err != nil {
+			return nil, err
+		}
+	}
+
 	if !a.isValidPublicAccessType(m.PublicAccessLevel) {
 		return nil, fmt.Errorf("invalid public access level: %s; allowed: %s",
 			m.PublicAccessLevel, azblob.PossiblePublicAccessTypeValues())
@@ -165,76 +549,175 @@ func (a *AzureBlobStorage) Operations() []bindings.OperationKind {
 		bindings.GetOperation,
 		bindings.DeleteOperation,
 		bindings.ListOperation,
+		previewOperation,
+		touchOperation,
+		setMetadataOperation,
+		existsOperation,
+		setTierOperation,
+		appendOperation,
+		initUploadOperation,
+		uploadChunkOperation,
+		finishUploadOperation,
+		getUploadStatusOperation,
+		copyOperation,
+		createSASURLOperation,
+		batchHeadOperation,
+		batchGetOperation,
+		batchCreateOperation,
+		deleteBatchOperation,
+		listContainersOperation,
+		readChangeFeedOperation,
+		createDirectoryOperation,
+		deleteDirectoryOperation,
+		renameDirectoryOperation,
+		listDirectoryOperation,
 	}
 }
 
-func (a *AzureBlobStorage) create(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
-	var blobHTTPHeaders azblob.BlobHTTPHeaders
-	var blobURL azblob.BlockBlobURL
+func (a *AzureBlobStorage) create(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobName string
 	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
-		blobURL = a.getBlobURL(val)
+		blobName = val
 		delete(req.Metadata, metadataKeyBlobName)
-	} else {
-		blobURL = a.getBlobURL(uuid.New().String())
 	}
 
-	if val, ok := req.Metadata[metadataKeyContentType]; ok && val != "" {
-		blobHTTPHeaders.ContentType = val
-		delete(req.Metadata, metadataKeyContentType)
+	rangeVal, isRangeUpload := req.Metadata[metadataKeyContentRange]
+	delete(req.Metadata, metadataKeyContentRange)
+	if isRangeUpload && blobName == "" {
+		return nil, ErrMissingBlobName
 	}
-	if val, ok := req.Metadata[metadataKeyContentMD5]; ok && val != "" {
-		sDec, err := b64.StdEncoding.DecodeString(val)
-		if err != nil || len(sDec) != 16 {
-			return nil, fmt.Errorf("the MD5 value specified in Content MD5 is invalid, MD5 value must be 128 bits and base64 encoded")
-		}
-		blobHTTPHeaders.ContentMD5 = sDec
-		delete(req.Metadata, metadataKeyContentMD5)
+
+	validateOnly, err := req.GetMetadataAsBool(metadataKeyValidateOnly)
+	if err != nil {
+		return nil, err
 	}
-	if val, ok := req.Metadata[metadataKeyContentEncoding]; ok && val != "" {
-		blobHTTPHeaders.ContentEncoding = val
-		delete(req.Metadata, metadataKeyContentEncoding)
+	delete(req.Metadata, metadataKeyValidateOnly)
+
+	signedURLExpiry, err := objectstorage.ParseSignedURLExpiry(req.Metadata)
+	if err != nil {
+		return nil, err
 	}
-	if val, ok := req.Metadata[metadataKeyContentLanguage]; ok && val != "" {
-		blobHTTPHeaders.ContentLanguage = val
-		delete(req.Metadata, metadataKeyContentLanguage)
+	delete(req.Metadata, objectstorage.MetadataKeyReturnSignedURL)
+	delete(req.Metadata, objectstorage.MetadataKeySignedURLExpiry)
+
+	thumbnailSize, err := objectstorage.ParseThumbnailSize(req.Metadata)
+	if err != nil {
+		return nil, err
 	}
-	if val, ok := req.Metadata[metadataKeyContentDisposition]; ok && val != "" {
-		blobHTTPHeaders.ContentDisposition = val
-		delete(req.Metadata, metadataKeyContentDisposition)
+	delete(req.Metadata, objectstorage.MetadataKeyGenerateThumbnail)
+	delete(req.Metadata, objectstorage.MetadataKeyThumbnailSize)
+	if thumbnailSize != nil && isRangeUpload {
+		return nil, fmt.Errorf("%s is not supported for range uploads", objectstorage.MetadataKeyGenerateThumbnail)
 	}
-	if val, ok := req.Metadata[meatdataKeyCacheControl]; ok && val != "" {
-		blobHTTPHeaders.CacheControl = val
-		delete(req.Metadata, meatdataKeyCacheControl)
+
+	enc, err := parseRequestEncryption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := parseWriteAccessConditions(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, enc)
+
+	blobHTTPHeaders, err := parseBlobHTTPHeaders(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if blobName == "" {
+		blobName = objectstorage.GenerateKey(a.metadata.KeyTemplate, blobHTTPHeaders.ContentType, time.Now())
+	}
+	if a.metadata.AppendExtensionFromContentType {
+		blobName = objectstorage.AppendExtension(blobName, blobHTTPHeaders.ContentType)
 	}
+	blobURL := a.getBlobURL(blobName)
 
 	d, err := strconv.Unquote(string(req.Data))
 	if err == nil {
 		req.Data = []byte(d)
 	}
 
-	if a.metadata.DecodeBase64 {
-		decoded, decodeError := b64.StdEncoding.DecodeString(string(req.Data))
-		if decodeError != nil {
-			return nil, decodeError
+	var digest string
+	req.Data, digest, err = a.decodeAndHashData(blobName, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Data) == 0 && !a.metadata.AllowEmpty {
+		return nil, objectstorage.ErrEmptyData
+	}
+
+	if a.metadata.PreserveMetadataCase {
+		req.Metadata = withMetadataCase(req.Metadata)
+	}
+	if err = a.fitMetadata(blobName, req.Metadata); err != nil {
+		return nil, err
+	}
+
+	if validateOnly {
+		return a.validateCreate(ctx, blobURL, rangeVal, isRangeUpload, req)
+	}
+
+	if isRangeUpload {
+		return a.createRange(ctx, blobURL, blobName, rangeVal, req, blobHTTPHeaders, conditions)
+	}
+
+	// Generated before the upload, so that a corrupted image fails the request without writing the blob
+	var thumbnail []byte
+	if thumbnailSize != nil {
+		thumbnail, err = objectstorage.Thumbnail(req.Data, blobHTTPHeaders.ContentType, *thumbnailSize)
+		if err != nil {
+			return nil, err
 		}
-		req.Data = decoded
 	}
 
-	_, err = azblob.UploadBufferToBlockBlob(context.Background(), req.Data, blobURL, azblob.UploadToBlockBlobOptions{
-		Parallelism:     16,
-		Metadata:        req.Metadata,
-		BlobHTTPHeaders: blobHTTPHeaders,
-	})
+	progress := objectstorage.NewProgressLogger(a.logger, "upload", blobName, int64(len(req.Data)), a.metadata.ProgressLogInterval)
+	uploadOptions := azblob.UploadToBlockBlobOptions{
+		Parallelism:      a.metadata.UploadParallelism,
+		BlockSize:        a.metadata.BlockSize,
+		Metadata:         req.Metadata,
+		BlobHTTPHeaders:  blobHTTPHeaders,
+		AccessConditions: conditions,
+	}
+	if progress != nil {
+		uploadOptions.Progress = progress.Report
+	}
+	uploadResp, err := azblob.UploadBufferToBlockBlob(ctx, req.Data, blobURL, uploadOptions)
 	if err != nil {
+		if isPreconditionFailedError(err) {
+			return nil, preconditionFailedError(conditions)
+		}
+
 		return nil, fmt.Errorf("error uploading az blob: %w", err)
 	}
 
-	resp := createResponse{
-		BlobURL: blobURL.String(),
+	var thumbnailURL string
+	if thumbnail != nil {
+		thumbnailURL, err = a.uploadThumbnail(ctx, blobName, thumbnail)
+		if err != nil {
+			return nil, err
+		}
 	}
-	b, err := json.Marshal(resp)
+
+	var urls *objectstorage.ObjectURLs
+	if signedURLExpiry > 0 {
+		urls, err = a.objectURLs(blobURL, blobName, signedURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Data that isn't decoded is not read by the binding before the upload, which reads the blocks
+	// of the buffer in parallel and again on retries, so it can't be hashed through the upload
+	if digest == "" {
+		digest = objectstorage.SHA256(req.Data)
+	}
+	b, err := a.marshalCreateResponse(blobURL, blobName, uploadResp, int64(len(req.Data)), blobHTTPHeaders.ContentType, digest, thumbnailURL, urls)
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling create response for azure blob: %w", err)
+		return nil, err
 	}
 
 	return &bindings.InvokeResponse{
@@ -242,50 +725,168 @@ func (a *AzureBlobStorage) create(req *bindings.InvokeRequest) (*bindings.Invoke
 	}, nil
 }
 
-func (a *AzureBlobStorage) get(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
-	var blobURL azblob.BlockBlobURL
-	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
-		blobURL = a.getBlobURL(val)
-	} else {
-		return nil, ErrMissingBlobName
+// uploadThumbnail writes the thumbnail of blobName next to it, with the customer-provided key of
+// the request if any, and returns its URL.
+func (a *AzureBlobStorage) uploadThumbnail(ctx context.Context, blobName string, thumbnail []byte) (string, error) {
+	thumbnailName := objectstorage.ThumbnailKey(blobName)
+	thumbnailURL := a.getBlobURL(thumbnailName)
+	_, err := azblob.UploadBufferToBlockBlob(ctx, thumbnail, thumbnailURL, azblob.UploadToBlockBlobOptions{
+		Parallelism:     a.metadata.UploadParallelism,
+		BlobHTTPHeaders: azblob.BlobHTTPHeaders{ContentType: objectstorage.ThumbnailContentType},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading thumbnail %s of az blob %s: %w", thumbnailName, blobName, err)
 	}
 
-	ctx := context.TODO()
-	resp, err := blobURL.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
-	if err != nil {
-		return nil, fmt.Errorf("error downloading az blob: %w", err)
+	return thumbnailURL.String(), nil
+}
+
+// parseBlobHTTPHeaders reads the content headers of a blob from the request metadata and removes
+// them, so they are not stored as blob metadata.
+func parseBlobHTTPHeaders(metadata map[string]string) (azblob.BlobHTTPHeaders, error) {
+	var blobHTTPHeaders azblob.BlobHTTPHeaders
+	if val, ok := metadata[metadataKeyContentType]; ok && val != "" {
+		blobHTTPHeaders.ContentType = val
+		delete(metadata, metadataKeyContentType)
+	}
+	if val, ok := metadata[metadataKeyContentMD5]; ok && val != "" {
+		sDec, err := b64.StdEncoding.DecodeString(val)
+		if err != nil || len(sDec) != 16 {
+			return blobHTTPHeaders, fmt.Errorf("the MD5 value specified in Content MD5 is invalid, MD5 value must be 128 bits and base64 encoded")
+		}
+		blobHTTPHeaders.ContentMD5 = sDec
+		delete(metadata, metadataKeyContentMD5)
+	}
+	if val, ok := metadata[metadataKeyContentEncoding]; ok && val != "" {
+		blobHTTPHeaders.ContentEncoding = val
+		delete(metadata, metadataKeyContentEncoding)
+	}
+	if val, ok := metadata[metadataKeyContentLanguage]; ok && val != "" {
+		blobHTTPHeaders.ContentLanguage = val
+		delete(metadata, metadataKeyContentLanguage)
+	}
+	if val, ok := metadata[metadataKeyContentDisposition]; ok && val != "" {
+		blobHTTPHeaders.ContentDisposition = val
+		delete(metadata, metadataKeyContentDisposition)
+	}
+	if val, ok := metadata[meatdataKeyCacheControl]; ok && val != "" {
+		blobHTTPHeaders.CacheControl = val
+		delete(metadata, meatdataKeyCacheControl)
 	}
 
-	bodyStream := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: a.metadata.GetBlobRetryCount})
+	return blobHTTPHeaders, nil
+}
 
-	b := bytes.Buffer{}
-	_, err = b.ReadFrom(bodyStream)
-	if err != nil {
-		return nil, fmt.Errorf("error reading az blob body: %w", err)
+// decodeData decodes the data of a blob when decodeBase64 is enabled.
+func (a *AzureBlobStorage) decodeData(blobName string, data []byte) ([]byte, error) {
+	data, _, err := a.decodeAndHashData(blobName, data)
+
+	return data, err
+}
+
+// decodeAndHashData decodes the data of a blob like decodeData, and returns the hex encoded
+// SHA-256 digest of the decoded data computed while decoding it. The digest is empty when the data
+// isn't decoded.
+func (a *AzureBlobStorage) decodeAndHashData(blobName string, data []byte) ([]byte, string, error) {
+	if !a.metadata.DecodeBase64 {
+		return data, "", nil
 	}
 
-	var metadata map[string]string
-	fetchMetadata, err := req.GetMetadataAsBool(metadataKeyIncludeMetadata)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing metadata: %w", err)
+	r := objectstorage.NewHashingReader(b64.NewDecoder(b64.StdEncoding, bytes.NewReader(data)))
+	var decoded bytes.Buffer
+	decoded.Grow(b64.StdEncoding.DecodedLen(len(data)))
+	_, err := decoded.ReadFrom(r)
+	switch {
+	case err == nil:
+		return decoded.Bytes(), r.SHA256(), nil
+	case a.metadata.StrictBase64:
+		return nil, "", fmt.Errorf("error decoding data of blob %s as base64 (decodeBase64 is enabled): %w", blobName, err)
+	default:
+		a.logger.Warnf("data of blob %s is not valid base64, storing it as-is: %v", blobName, err)
+
+		return data, "", nil
 	}
+}
 
-	if fetchMetadata {
-		props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{})
+// validateCreate runs the checks that the service would do on the upload of a create request,
+// without transferring the data, and checks that the credentials can access the container. Access
+// is checked by reading the container properties, so write permissions aren't verified.
+func (a *AzureBlobStorage) validateCreate(ctx context.Context, blobURL azblob.BlockBlobURL, rangeVal string, isRangeUpload bool, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	size := int64(len(req.Data))
+	if isRangeUpload {
+		r, err := parseContentRange(rangeVal)
 		if err != nil {
-			return nil, fmt.Errorf("error reading blob metadata: %w", err)
+			return nil, err
+		}
+		if r.end-r.start+1 != size {
+			return nil, fmt.Errorf("the size of the data (%d bytes) doesn't match the %s %q", size, metadataKeyContentRange, rangeVal)
+		}
+		if size > azblob.BlockBlobMaxStageBlockBytes {
+			return nil, fmt.Errorf("range of %d bytes exceeds the maximum block size of %d bytes", size, azblob.BlockBlobMaxStageBlockBytes)
 		}
+	} else if size > azblob.BlockBlobMaxStageBlockBytes*azblob.BlockBlobMaxBlocks {
+		return nil, fmt.Errorf("blob of %d bytes exceeds the maximum block blob size of %d bytes", size, int64(azblob.BlockBlobMaxStageBlockBytes*azblob.BlockBlobMaxBlocks))
+	}
 
-		metadata = props.NewMetadata()
+	for name := range req.Metadata {
+		if !metadataNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid metadata name %q, metadata names must be valid C# identifiers", name)
+		}
+	}
+
+	_, err := a.containerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+	if err != nil {
+		return nil, fmt.Errorf("error validating access to container %s: %w", a.metadata.Container, err)
+	}
+
+	b, err := json.Marshal(validateOnlyResponse{
+		BlobURL: blobURL.String(),
+		Size:    size,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling create response for azure blob: %w", err)
 	}
 
 	return &bindings.InvokeResponse{
-		Data:     b.Bytes(),
-		Metadata: metadata,
+		Data: b,
 	}, nil
 }
 
-func (a *AzureBlobStorage) delete(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+// marshalCreateResponse returns the response of a completed upload of size bytes.
+func (a *AzureBlobStorage) marshalCreateResponse(blobURL azblob.BlockBlobURL, name string, uploadResp azblob.CommonResponse, size int64, contentType string, sha256 string, thumbnailURL string, urls *objectstorage.ObjectURLs) ([]byte, error) {
+	var resp interface{} = createResponse{
+		BlobURL:      blobURL.String(),
+		SHA256:       sha256,
+		ThumbnailURL: thumbnailURL,
+		ObjectURLs:   urls,
+	}
+	if a.metadata.CanonicalResponse {
+		canonical := objectstorage.CanonicalResponse{
+			Provider:     canonicalResponseProvider,
+			Bucket:       a.metadata.Container,
+			Key:          name,
+			URL:          blobURL.String(),
+			ETag:         string(uploadResp.ETag()),
+			Size:         size,
+			ContentType:  contentType,
+			ThumbnailURL: thumbnailURL,
+			ObjectURLs:   urls,
+		}
+		if httpResp := uploadResp.Response(); httpResp != nil {
+			canonical.VersionID = httpResp.Header.Get(versionIDHeader)
+		}
+		resp = canonical
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling create response for azure blob: %w", err)
+	}
+
+	return b, nil
+}
+
+func (a *AzureBlobStorage) get(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	var blobURL azblob.BlockBlobURL
 	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
 		blobURL = a.getBlobURL(val)
@@ -293,30 +894,329 @@ func (a *AzureBlobStorage) delete(req *bindings.InvokeRequest) (*bindings.Invoke
 		return nil, ErrMissingBlobName
 	}
 
-	deleteSnapshotsOptions := azblob.DeleteSnapshotsOptionNone
-	if val, ok := req.Metadata[metadataKeyDeleteSnapshots]; ok && val != "" {
-		deleteSnapshotsOptions = azblob.DeleteSnapshotsOptionType(val)
-		if !a.isValidDeleteSnapshotsOptionType(deleteSnapshotsOptions) {
-			return nil, fmt.Errorf("invalid delete snapshot option type: %s; allowed: %s",
-				deleteSnapshotsOptions, azblob.PossibleDeleteSnapshotsOptionTypeValues())
+	missingObjectBehavior := missingObjectBehaviorError
+	if val, ok := req.Metadata[metadataKeyMissingObjectBehavior]; ok && val != "" {
+		if val != missingObjectBehaviorError && val != missingObjectBehaviorEmpty {
+			return nil, fmt.Errorf("invalid %s: %s; allowed: %s, %s", metadataKeyMissingObjectBehavior, val,
+				missingObjectBehaviorError, missingObjectBehaviorEmpty)
 		}
+		missingObjectBehavior = val
 	}
 
-	_, err := blobURL.Delete(context.Background(), deleteSnapshotsOptions, azblob.BlobAccessConditions{})
-
-	return nil, err
-}
-
-func (a *AzureBlobStorage) list(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
-	options := azblob.ListBlobsSegmentOptions{}
+	var sourceEncoding encoding.Encoding
+	if val, ok := req.Metadata[metadataKeySourceEncoding]; ok && val != "" {
+		var err error
+		sourceEncoding, err = ianaindex.IANA.Encoding(val)
+		if err != nil || sourceEncoding == nil {
+			return nil, fmt.Errorf("invalid %s: %s is not a supported text encoding", metadataKeySourceEncoding, val)
+		}
+	}
 
-	var payload listPayload
-	err := json.Unmarshal(req.Data, &payload)
+	enc, err := parseRequestEncryption(req.Metadata)
 	if err != nil {
 		return nil, err
 	}
+	if enc != nil {
+		// The encryption scope only applies to writes
+		enc.scope = ""
+	}
 
-	options.Details.Copy = payload.Include.Copy
+	conditions, err := parseModifiedAccessConditions(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	reportRecordCount, err := req.GetMetadataAsBool(objectstorage.MetadataKeyReportRecordCount)
+	if err != nil {
+		return nil, err
+	}
+	recordFormat := req.Metadata[objectstorage.MetadataKeyRecordFormat]
+	csvHeader, err := req.GetMetadataAsBool(objectstorage.MetadataKeyCSVHeader)
+	if err != nil {
+		return nil, err
+	}
+	if reportRecordCount && recordFormat != objectstorage.RecordFormatNDJSON && recordFormat != objectstorage.RecordFormatCSV {
+		return nil, fmt.Errorf("invalid %s: %s; allowed: %s, %s", objectstorage.MetadataKeyRecordFormat, recordFormat,
+			objectstorage.RecordFormatNDJSON, objectstorage.RecordFormatCSV)
+	}
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, enc)
+	resp, err := blobURL.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{ModifiedAccessConditions: conditions}, false)
+	if err != nil {
+		if isNotModifiedError(err) {
+			return nil, ErrNotModified
+		}
+		if isEncryptionKeyRequiredError(err) {
+			return nil, ErrEncryptionKeyRequired
+		}
+		if isNotFoundError(err) {
+			if missingObjectBehavior == missingObjectBehaviorEmpty {
+				return &bindings.InvokeResponse{
+					Data:     []byte{},
+					Metadata: map[string]string{metadataKeyNotFound: "true"},
+				}, nil
+			}
+
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("error downloading az blob: %w", err)
+	}
+
+	bodyStream := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: a.metadata.GetBlobRetryCount})
+	if a.metadata.DownloadTryTimeout > 0 {
+		bodyStream = newStallTimeoutReader(bodyStream, a.metadata.DownloadTryTimeout)
+	}
+	progress := objectstorage.NewProgressLogger(a.logger, "download", req.Metadata[metadataKeyBlobName], resp.ContentLength(), a.metadata.ProgressLogInterval)
+
+	b := bytes.Buffer{}
+	_, err = b.ReadFrom(objectstorage.NewProgressReader(bodyStream, progress))
+	if err != nil {
+		return nil, fmt.Errorf("error reading az blob body: %w", err)
+	}
+
+	data := b.Bytes()
+	if sourceEncoding != nil {
+		data, err = sourceEncoding.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("error transcoding az blob body to utf-8: %w", err)
+		}
+	}
+
+	metadata := map[string]string{}
+	fetchMetadata, err := req.GetMetadataAsBool(metadataKeyIncludeMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %w", err)
+	}
+
+	if fetchMetadata {
+		props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{})
+		if err != nil {
+			return nil, fmt.Errorf("error reading blob metadata: %w", err)
+		}
+
+		metadata = props.NewMetadata()
+		if a.metadata.PreserveMetadataCase {
+			metadata = restoreMetadataCase(metadata)
+		}
+	}
+	metadata[metadataKeyETag] = string(resp.ETag())
+	metadata[metadataKeyLastModified] = resp.LastModified().UTC().Format(http.TimeFormat)
+	metadata[metadataKeyContentType] = resp.ContentType()
+	metadata[objectstorage.MetadataKeyContentLength] = strconv.FormatInt(resp.ContentLength(), 10)
+	if md5 := resp.ContentMD5(); len(md5) > 0 {
+		metadata[metadataKeyContentMD5] = b64.StdEncoding.EncodeToString(md5)
+	}
+	if reportRecordCount {
+		count, err := objectstorage.CountRecords(data, recordFormat, csvHeader)
+		if err != nil {
+			return nil, err
+		}
+		metadata[objectstorage.MetadataKeyRecordCount] = strconv.FormatInt(count, 10)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     data,
+		Metadata: metadata,
+	}, nil
+}
+
+// preview downloads the first previewBytes bytes of the blob, whatever its size, and returns the
+// size of the whole blob in the contentLength metadata.
+func (a *AzureBlobStorage) preview(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobURL azblob.BlockBlobURL
+	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
+		blobURL = a.getBlobURL(val)
+	} else {
+		return nil, ErrMissingBlobName
+	}
+
+	previewBytes, err := objectstorage.ParsePreviewBytes(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := parseRequestEncryption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if enc != nil {
+		enc.scope = ""
+	}
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, enc)
+	resp, err := blobURL.Download(ctx, 0, previewBytes, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		// Ranged reads of empty blobs fail as the range can't be satisfied
+		if azureError, ok := err.(azblob.StorageError); ok && azureError.ServiceCode() == azblob.ServiceCodeInvalidRange {
+			return &bindings.InvokeResponse{
+				Data:     []byte{},
+				Metadata: map[string]string{objectstorage.MetadataKeyContentLength: "0"},
+			}, nil
+		}
+		if isEncryptionKeyRequiredError(err) {
+			return nil, ErrEncryptionKeyRequired
+		}
+		if isNotFoundError(err) {
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("error downloading az blob: %w", err)
+	}
+
+	body := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: a.metadata.GetBlobRetryCount})
+	defer body.Close()
+
+	b := bytes.Buffer{}
+	_, err = b.ReadFrom(io.LimitReader(body, previewBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error reading az blob body: %w", err)
+	}
+
+	size, ok := objectstorage.ContentRangeSize(resp.ContentRange())
+	if !ok {
+		size = resp.ContentLength()
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b.Bytes(),
+		Metadata: map[string]string{
+			objectstorage.MetadataKeyContentLength: strconv.FormatInt(size, 10),
+			metadataKeyETag:                        string(resp.ETag()),
+			metadataKeyLastModified:                resp.LastModified().UTC().Format(http.TimeFormat),
+			metadataKeyContentType:                 resp.ContentType(),
+		},
+	}, nil
+}
+
+// touch updates the last modified time of the blob without changing its contents, by setting its
+// HTTP headers to the current values. The metadata isn't rewritten, so the case of its names is kept.
+// On accounts with blob versioning enabled this creates a new version.
+func (a *AzureBlobStorage) touch(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobURL azblob.BlockBlobURL
+	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
+		blobURL = a.getBlobURL(val)
+	} else {
+		return nil, ErrMissingBlobName
+	}
+
+	enc, err := parseRequestEncryption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	props, err := blobURL.GetProperties(withRequestEncryption(ctx, enc), azblob.BlobAccessConditions{})
+	if err != nil {
+		if isEncryptionKeyRequiredError(err) {
+			return nil, ErrEncryptionKeyRequired
+		}
+		if isNotFoundError(err) {
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("error reading az blob properties: %w", err)
+	}
+
+	// Setting the headers doesn't take the encryption key. The ETag condition keeps headers changed
+	// since GetProperties from being reverted.
+	resp, err := blobURL.SetHTTPHeaders(ctx, props.NewHTTPHeaders(), azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: props.ETag()},
+	})
+	if err != nil {
+		if isPreconditionFailedError(err) {
+			return nil, ErrPreconditionFailed
+		}
+
+		return nil, fmt.Errorf("error touching az blob: %w", err)
+	}
+
+	b, err := json.Marshal(touchResponse{
+		ETag:         string(resp.ETag()),
+		LastModified: resp.LastModified(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling touch response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+func (a *AzureBlobStorage) delete(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobURL azblob.BlockBlobURL
+	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
+		blobURL = a.getBlobURL(val)
+	} else {
+		return nil, ErrMissingBlobName
+	}
+
+	deleteSnapshotsOptions, err := a.parseDeleteSnapshotsOption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	_, err = blobURL.Delete(ctx, deleteSnapshotsOptions, azblob.BlobAccessConditions{})
+	if err != nil {
+		return nil, err
+	}
+	// Only deleting the snapshots keeps the blob
+	if deleteSnapshotsOptions == azblob.DeleteSnapshotsOptionOnly {
+		return nil, nil
+	}
+
+	return nil, a.metadata.DeleteConsistency.WaitUntilGone(ctx, blobGone(blobURL))
+}
+
+// blobGone returns the check of consistentDelete that a deleted blob is no longer visible.
+func blobGone(blobURL azblob.BlockBlobURL) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		_, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{})
+		switch {
+		case err == nil:
+			return false, nil
+		case isNotFoundResponse(err, azblob.ServiceCodeBlobNotFound):
+			return true, nil
+		case isEncryptionKeyRequiredError(err):
+			// The blob exists, but its properties can only be read with its key
+			return false, nil
+		default:
+			return false, fmt.Errorf("error checking deleted az blob: %w", err)
+		}
+	}
+}
+
+func (a *AzureBlobStorage) parseDeleteSnapshotsOption(metadata map[string]string) (azblob.DeleteSnapshotsOptionType, error) {
+	deleteSnapshotsOptions := azblob.DeleteSnapshotsOptionNone
+	if val, ok := metadata[metadataKeyDeleteSnapshots]; ok && val != "" {
+		deleteSnapshotsOptions = azblob.DeleteSnapshotsOptionType(val)
+		if !a.isValidDeleteSnapshotsOptionType(deleteSnapshotsOptions) {
+			return "", fmt.Errorf("invalid delete snapshot option type: %s; allowed: %s",
+				deleteSnapshotsOptions, azblob.PossibleDeleteSnapshotsOptionTypeValues())
+		}
+	}
+
+	return deleteSnapshotsOptions, nil
+}
+
+func (a *AzureBlobStorage) list(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	options := azblob.ListBlobsSegmentOptions{}
+
+	var payload listPayload
+	err := json.Unmarshal(req.Data, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	options.Details.Copy = payload.Include.Copy
 	options.Details.Metadata = payload.Include.Metadata
 	options.Details.Snapshots = payload.Include.Snapshots
 	options.Details.UncommittedBlobs = payload.Include.UncommittedBlobs
@@ -339,58 +1239,467 @@ func (a *AzureBlobStorage) list(req *bindings.InvokeRequest) (*bindings.InvokeRe
 		initialMarker = azblob.Marker{}
 	}
 
+	if payload.Cursor != "" {
+		if payload.Marker != "" {
+			return nil, fmt.Errorf("marker and cursor can't be used together")
+		}
+		cursor, err := objectstorage.DecodeCursor(payload.Cursor, canonicalResponseProvider, payload.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		initialMarker = azblob.Marker{Val: &cursor.Marker}
+		options.Prefix = cursor.Prefix
+		if payload.MaxResults == 0 && cursor.PageSize > 0 {
+			options.MaxResults = cursor.PageSize
+		}
+	}
+	pageSize := options.MaxResults
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	var resp *bindings.InvokeResponse
+	if payload.Delimiter != "" {
+		if payload.GroupByTier {
+			return nil, fmt.Errorf("groupByTier can't be used with a delimiter")
+		}
+		if payload.MaxDepth < 0 {
+			return nil, fmt.Errorf("invalid maxDepth %d, expected a positive number of levels", payload.MaxDepth)
+		}
+
+		resp, err = a.listHierarchy(ctx, initialMarker, payload.Delimiter, options, payload.StreamPages, payload.MaxDepth)
+	} else {
+		if payload.MaxDepth != 0 {
+			return nil, fmt.Errorf("maxDepth can only be used with a delimiter")
+		}
+
+		resp, err = a.listFlat(ctx, initialMarker, options, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The cursor wraps the marker, so that callers don't depend on its format
+	resp.Metadata[objectstorage.MetadataKeyCursor] = ""
+	if marker := resp.Metadata[metadataKeyMarker]; marker != "" {
+		resp.Metadata[objectstorage.MetadataKeyCursor], err = objectstorage.EncodeCursor(objectstorage.ListCursor{
+			Provider: canonicalResponseProvider,
+			Marker:   marker,
+			Prefix:   options.Prefix,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// listFlat lists the blobs of the container, or their summary per access tier with groupByTier.
+func (a *AzureBlobStorage) listFlat(ctx context.Context, marker azblob.Marker, options azblob.ListBlobsSegmentOptions, payload listPayload) (*bindings.InvokeResponse, error) {
 	var blobs []azblob.BlobItem
+	metadata, err := listSegments(marker, options.MaxResults, payload.StreamPages, func(marker azblob.Marker, n int32) (int, azblob.Marker, error) {
+		options.MaxResults = n
+		listBlob, err := a.containerURL.ListBlobsFlatSegment(ctx, marker, options)
+		if err != nil {
+			return 0, azblob.Marker{}, fmt.Errorf("error listing blobs: %w", err)
+		}
+		blobs = append(blobs, listBlob.Segment.BlobItems...)
+
+		return len(listBlob.Segment.BlobItems), listBlob.NextMarker, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.restoreListMetadataCase(blobs)
+	jsonResponse, err := marshalListResult(blobs, payload.GroupByTier, a.metadata.TierCostPerGB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     jsonResponse,
+		Metadata: metadata,
+	}, nil
+}
+
+// listSegments lists the segments from marker with listSegment, which returns the number of items of
+// the segment and the next marker, until the listing is complete or total items were listed. Only
+// the first segment is listed with singleSegment, so memory use is bounded by the segment size. It
+// returns the marker and number response metadata.
+func listSegments(marker azblob.Marker, total int32, singleSegment bool, listSegment func(marker azblob.Marker, maxResults int32) (int, azblob.Marker, error)) (map[string]string, error) {
+	// A segment has at most maxResults items, total caps the items of all the segments
+	segmentSize := total
+	if segmentSize > maxResults {
+		segmentSize = maxResults
+	}
+	var listed int32
 	metadata := map[string]string{}
-	ctx := context.Background()
-	for currentMaker := initialMarker; currentMaker.NotDone(); {
-		var listBlob *azblob.ListBlobsFlatSegmentResponse
-		listBlob, err = a.containerURL.ListBlobsFlatSegment(ctx, currentMaker, options)
+	for marker.NotDone() {
+		n, next, err := listSegment(marker, segmentSize)
 		if err != nil {
-			return nil, fmt.Errorf("error listing blobs: %w", err)
+			return nil, err
 		}
+		listed += int32(n)
+		marker = next
 
-		blobs = append(blobs, listBlob.Segment.BlobItems...)
+		metadata[metadataKeyNumber] = strconv.FormatInt(int64(listed), 10)
+		// The marker of the last segment is empty, or missing for some endpoints: a nil marker
+		// would restart the listing
+		if marker.Val == nil {
+			metadata[metadataKeyMarker] = ""
 
-		numBlobs := len(blobs)
-		currentMaker = listBlob.NextMarker
-		metadata[metadataKeyMarker] = *currentMaker.Val
-		metadata[metadataKeyNumber] = strconv.FormatInt(int64(numBlobs), 10)
+			break
+		}
+		metadata[metadataKeyMarker] = *marker.Val
 
-		if options.MaxResults-maxResults > 0 {
-			options.MaxResults -= maxResults
-		} else {
+		remaining := total - listed
+		if singleSegment || remaining <= 0 {
 			break
 		}
+		if remaining < segmentSize {
+			segmentSize = remaining
+		}
+	}
+
+	return metadata, nil
+}
+
+// marshalListResult returns the JSON of the entries of the blobs, or of the number of blobs and bytes
+// per access tier with the estimated cost of the tiers that have a cost per GiB.
+func marshalListResult(blobs []azblob.BlobItem, groupByTier bool, tierCostPerGB map[string]float64) ([]byte, error) {
+	var result interface{}
+	if !groupByTier {
+		entries := make([]listBlobEntry, len(blobs))
+		for i, blob := range blobs {
+			entries[i] = newListBlobEntry(blob)
+		}
+		result = entries
+	} else {
+		tiers := map[string]*tierSummary{}
+		for _, blob := range blobs {
+			tier := string(blob.Properties.AccessTier)
+			if tier == "" {
+				// Page and append blobs have no access tier
+				tier = "None"
+			}
+			if tiers[tier] == nil {
+				tiers[tier] = &tierSummary{}
+			}
+			tiers[tier].Count++
+			if blob.Properties.ContentLength != nil {
+				tiers[tier].Bytes += *blob.Properties.ContentLength
+			}
+		}
+		for tier, summary := range tiers {
+			if rate, ok := tierCostPerGB[tier]; ok {
+				cost := float64(summary.Bytes) / (1 << 30) * rate
+				summary.EstimatedMonthlyCost = &cost
+			}
+		}
+		result = tiers
 	}
 
-	jsonResponse, err := json.Marshal(blobs)
+	b, err := json.Marshal(result)
 	if err != nil {
 		return nil, fmt.Errorf("cannot marshal blobs to json: %w", err)
 	}
 
+	return b, nil
+}
+
+func (a *AzureBlobStorage) batchHead(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobNames []string
+	err := json.Unmarshal(req.Data, &blobNames)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing batchHead payload, expected a json array of blob names: %w", err)
+	}
+	if err = a.validateNames(blobNames...); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	ctx = withRetryBudget(ctx, objectstorage.NewRetryBudget(a.metadata.RetryBudget))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]batchHeadResult, len(blobNames))
+	sem := make(chan struct{}, defaultBatchConcurrency)
+	for _, name := range blobNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := a.headBlob(ctx, name)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling batchHead response for azure blob: %w", err)
+	}
+
 	return &bindings.InvokeResponse{
-		Data:     jsonResponse,
-		Metadata: metadata,
+		Data: b,
+	}, nil
+}
+
+func (a *AzureBlobStorage) headBlob(ctx context.Context, name string) batchHeadResult {
+	props, err := a.getBlobURL(name).GetProperties(ctx, azblob.BlobAccessConditions{})
+	if err != nil {
+		return batchHeadResult{Error: err.Error()}
+	}
+
+	lastModified := props.LastModified()
+
+	return batchHeadResult{
+		Size:                 props.ContentLength(),
+		ContentType:          props.ContentType(),
+		ETag:                 string(props.ETag()),
+		LastModified:         &lastModified,
+		CreationTime:         timeOrNil(props.CreationTime()),
+		AccessTierChangeTime: timeOrNil(props.AccessTierChangeTime()),
+		ObjectReplication:    objectReplicationProperties(props.Response().Header),
+	}
+}
+
+// timeOrNil returns nil for the zero time, which the SDK returns for missing date headers.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// objectReplicationProperties returns the x-ms-or-* headers of a blob without the prefix. The SDK
+// doesn't expose them as typed properties.
+// See: https://docs.microsoft.com/en-us/azure/storage/blobs/object-replication-overview
+func objectReplicationProperties(header http.Header) map[string]string {
+	var properties map[string]string
+	for k, v := range header {
+		name := strings.ToLower(k)
+		if !strings.HasPrefix(name, objectReplicationHeaderPrefix) || len(v) == 0 {
+			continue
+		}
+		if properties == nil {
+			properties = map[string]string{}
+		}
+		properties[strings.TrimPrefix(name, objectReplicationHeaderPrefix)] = v[0]
+	}
+
+	return properties
+}
+
+// listContainers returns the containers of the storage account that the credentials can access.
+func (a *AzureBlobStorage) listContainers(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	serviceURL := a.containerURL.URL()
+	serviceURL.Path = ""
+	service := azblob.NewServiceURL(serviceURL, a.pipeline)
+
+	containers := []containerItem{}
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := service.ListContainersSegment(ctx, marker, azblob.ListContainersSegmentOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing containers: %w", err)
+		}
+		for _, item := range resp.ContainerItems {
+			containers = append(containers, containerItem{
+				Name:         item.Name,
+				LastModified: item.Properties.LastModified,
+			})
+		}
+		marker = resp.NextMarker
+	}
+
+	b, err := json.Marshal(containers)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal containers to json: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+		Metadata: map[string]string{
+			metadataKeyNumber: strconv.Itoa(len(containers)),
+		},
 	}, nil
 }
 
+// Invoke runs the operation of the request without a parent context, see InvokeWithContext.
 func (a *AzureBlobStorage) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	return a.InvokeWithContext(context.Background(), req)
+}
+
+// InvokeWithContext runs the operation of the request and reports its metrics. The requests to the
+// service are cancelled with ctx, or once the timeout of the request metadata elapsed. The request
+// metadata keys with objectstorage.EchoMetadataPrefix are copied to the metadata of the response.
+func (a *AzureBlobStorage) InvokeWithContext(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	start := time.Now()
+	operation, written := req.Operation, int64(len(req.Data))
+	echo := objectstorage.ExtractEchoMetadata(req.Metadata)
+	var counter *objectstorage.RequestCounter
+	if a.metadata.ReturnBilledRequests {
+		ctx, counter = objectstorage.WithRequestCounter(ctx)
+	}
+	resp, err := a.invoke(ctx, req)
+	objectstorage.ObserveOperation(a.metrics, canonicalResponseProvider, operation, start, transferredBytes(operation, written, resp), err)
+	if err != nil {
+		return nil, err
+	}
+
+	return objectstorage.WithEchoMetadata(objectstorage.WithBilledRequests(resp, counter), echo), nil
+}
+
+// transferredBytes returns the size of the data written or read by an operation for the metrics, or
+// -1 for the operations that don't transfer blob data. written is the size of the request data. The
+// batch operations count their JSON payload and response, with the base64 encoded blob data.
+func transferredBytes(operation bindings.OperationKind, written int64, resp *bindings.InvokeResponse) int64 {
+	switch operation {
+	case bindings.CreateOperation, appendOperation, uploadChunkOperation, batchCreateOperation:
+		return written
+	case bindings.GetOperation, previewOperation, batchGetOperation:
+		if resp == nil {
+			return 0
+		}
+
+		return int64(len(resp.Data))
+	default:
+		return -1
+	}
+}
+
+// fitMetadata checks that the metadata of the blob fits in maxMetadataSize, or truncates it with
+// truncateMetadata, so that the request isn't rejected by the service.
+func (a *AzureBlobStorage) fitMetadata(blobName string, metadata map[string]string) error {
+	affected, err := objectstorage.FitMetadata(metadata, maxMetadataSize, a.metadata.TruncateMetadata)
+	if err != nil {
+		return fmt.Errorf("invalid metadata of blob %s: %w", blobName, err)
+	}
+	if len(affected) > 0 {
+		a.logger.Warnf("metadata of blob %s truncated to %d bytes, changed or removed: %s", blobName, maxMetadataSize, strings.Join(affected, ", "))
+	}
+
+	return nil
+}
+
+// isWriteOperation returns true for the operations that write blobs or their properties, which are
+// rejected outside of allowedWriteWindow.
+func isWriteOperation(operation bindings.OperationKind) bool {
+	switch operation {
+	case bindings.CreateOperation, bindings.DeleteOperation, touchOperation, setMetadataOperation, setTierOperation, appendOperation,
+		initUploadOperation, uploadChunkOperation, finishUploadOperation, copyOperation, batchCreateOperation,
+		deleteBatchOperation, createDirectoryOperation, deleteDirectoryOperation, renameDirectoryOperation:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *AzureBlobStorage) invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	req.Metadata = a.handleBackwardCompatibilityForMetadata(req.Metadata)
+	ctx, cancel, err := objectstorage.WithRequestTimeout(ctx, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	err = a.validateNames(req.Metadata[metadataKeyBlobName], req.Metadata[metadataKeyDirectoryName], req.Metadata[metadataKeyDestinationDirectoryName])
+	if err != nil {
+		return nil, err
+	}
+	if isWriteOperation(req.Operation) {
+		if err = a.metadata.WriteWindow.Check(time.Now()); err != nil {
+			return nil, err
+		}
+	}
 
 	switch req.Operation {
 	case bindings.CreateOperation:
-		return a.create(req)
+		return a.create(ctx, req)
 	case bindings.GetOperation:
-		return a.get(req)
+		return a.get(ctx, req)
 	case bindings.DeleteOperation:
-		return a.delete(req)
+		return a.delete(ctx, req)
 	case bindings.ListOperation:
-		return a.list(req)
+		return a.list(ctx, req)
+	case previewOperation:
+		return a.preview(ctx, req)
+	case touchOperation:
+		return a.touch(ctx, req)
+	case setMetadataOperation:
+		return a.setMetadata(ctx, req)
+	case existsOperation:
+		return a.exists(ctx, req)
+	case setTierOperation:
+		return a.setTier(ctx, req)
+	case appendOperation:
+		return a.append(ctx, req)
+	case initUploadOperation:
+		return a.initUpload(ctx, req)
+	case uploadChunkOperation:
+		return a.uploadChunk(ctx, req)
+	case finishUploadOperation:
+		return a.finishUpload(ctx, req)
+	case getUploadStatusOperation:
+		return a.getUploadStatus(ctx, req)
+	case copyOperation:
+		return a.copy(ctx, req)
+	case createSASURLOperation:
+		return a.createSASURL(ctx, req)
+	case batchHeadOperation:
+		return a.batchHead(ctx, req)
+	case batchGetOperation:
+		return a.batchGet(ctx, req)
+	case batchCreateOperation:
+		return a.batchCreate(ctx, req)
+	case deleteBatchOperation:
+		return a.deleteBatch(ctx, req)
+	case listContainersOperation:
+		return a.listContainers(ctx, req)
+	case readChangeFeedOperation:
+		return a.readChangeFeed(ctx, req)
+	case createDirectoryOperation:
+		return a.createDirectory(ctx, req)
+	case deleteDirectoryOperation:
+		return a.deleteDirectory(ctx, req)
+	case renameDirectoryOperation:
+		return a.renameDirectory(ctx, req)
+	case listDirectoryOperation:
+		return a.listDirectory(ctx, req)
 	default:
 		return nil, fmt.Errorf("unsupported operation %s", req.Operation)
 	}
 }
 
+// validateNames validates the blob and directory names of a request when strictKeyValidation is
+// enabled. Empty names are ignored.
+func (a *AzureBlobStorage) validateNames(names ...string) error {
+	if !a.metadata.StrictKeyValidation {
+		return nil
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if err := objectstorage.ValidateKey(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (a *AzureBlobStorage) getBlobURL(name string) azblob.BlockBlobURL {
 	blobURL := a.containerURL.NewBlockBlobURL(name)
 
@@ -408,6 +1717,26 @@ func (a *AzureBlobStorage) isValidPublicAccessType(accessType azblob.PublicAcces
 	return false
 }
 
+func (a *AzureBlobStorage) isValidAccessTier(tier azblob.AccessTierType) bool {
+	for _, item := range allowedAccessTiers() {
+		if item == tier {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *AzureBlobStorage) isValidRehydratePriority(priority azblob.RehydratePriorityType) bool {
+	for _, item := range allowedRehydratePriorities() {
+		if item == priority {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (a *AzureBlobStorage) isValidDeleteSnapshotsOptionType(accessType azblob.DeleteSnapshotsOptionType) bool {
 	validTypes := azblob.PossibleDeleteSnapshotsOptionTypeValues()
 	for _, item := range validTypes {
@@ -419,6 +1748,170 @@ func (a *AzureBlobStorage) isValidDeleteSnapshotsOptionType(accessType azblob.De
 	return false
 }
 
+// stallTimeoutReader closes the underlying RetryReader stream when a read receives no data within
+// timeout. Closing the stream from another goroutine makes the RetryReader issue a new GET request.
+type stallTimeoutReader struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	stalled int32
+}
+
+func newStallTimeoutReader(body io.ReadCloser, timeout time.Duration) io.ReadCloser {
+	return &stallTimeoutReader{body: body, timeout: timeout}
+}
+
+func (r *stallTimeoutReader) Read(p []byte) (int, error) {
+	atomic.StoreInt32(&r.stalled, 0)
+	timer := time.AfterFunc(r.timeout, func() {
+		atomic.StoreInt32(&r.stalled, 1)
+		r.body.Close()
+	})
+	n, err := r.body.Read(p)
+	timer.Stop()
+
+	if err != nil && err != io.EOF && atomic.LoadInt32(&r.stalled) == 1 {
+		return n, fmt.Errorf("download timed out, no data was received within %s on the last attempt: %w", r.timeout, err)
+	}
+
+	return n, err
+}
+
+func (r *stallTimeoutReader) Close() error {
+	return r.body.Close()
+}
+
+// parseModifiedAccessConditions returns the conditions of a conditional download.
+func parseModifiedAccessConditions(metadata map[string]string) (azblob.ModifiedAccessConditions, error) {
+	var conditions azblob.ModifiedAccessConditions
+	if val, ok := metadata[metadataKeyIfModifiedSince]; ok && val != "" {
+		t, err := parseConditionDate(metadataKeyIfModifiedSince, val)
+		if err != nil {
+			return conditions, err
+		}
+		conditions.IfModifiedSince = t
+	}
+	if val, ok := metadata[metadataKeyIfNoneMatch]; ok && val != "" {
+		conditions.IfNoneMatch = azblob.ETag(val)
+	}
+
+	return conditions, nil
+}
+
+// parseWriteAccessConditions returns the conditions of a write and removes them from the metadata,
+// so they are not stored as blob metadata.
+func parseWriteAccessConditions(metadata map[string]string) (azblob.BlobAccessConditions, error) {
+	var conditions azblob.BlobAccessConditions
+	if val, ok := metadata[metadataKeyIfUnmodifiedSince]; ok && val != "" {
+		t, err := parseConditionDate(metadataKeyIfUnmodifiedSince, val)
+		if err != nil {
+			return conditions, err
+		}
+		conditions.ModifiedAccessConditions.IfUnmodifiedSince = t
+	}
+	if val, ok := metadata[metadataKeyIfMatch]; ok && val != "" {
+		conditions.ModifiedAccessConditions.IfMatch = azblob.ETag(val)
+	}
+	if val, ok := metadata[metadataKeyIfNoneMatch]; ok && val != "" {
+		conditions.ModifiedAccessConditions.IfNoneMatch = azblob.ETag(val)
+	}
+	if val, ok := metadata[metadataKeyLeaseID]; ok && val != "" {
+		conditions.LeaseAccessConditions.LeaseID = val
+	}
+	for _, key := range []string{metadataKeyIfUnmodifiedSince, metadataKeyIfMatch, metadataKeyIfNoneMatch, metadataKeyLeaseID} {
+		delete(metadata, key)
+	}
+
+	return conditions, nil
+}
+
+// preconditionFailedError returns the error of a write whose conditions were not met.
+func preconditionFailedError(conditions azblob.BlobAccessConditions) error {
+	if conditions.ModifiedAccessConditions.IfNoneMatch == azblob.ETagAny {
+		return ErrBlobAlreadyExists
+	}
+
+	return ErrPreconditionFailed
+}
+
+func parseConditionDate(key string, val string) (time.Time, error) {
+	t, err := http.ParseTime(val)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, val)
+	}
+	if err != nil {
+		return t, fmt.Errorf("invalid %s %q, expected a RFC 1123 or RFC 3339 date", key, val)
+	}
+
+	return t, nil
+}
+
+// isPreconditionFailedError returns true when the conditions of a request were not met. The service
+// reports existing blobs with 409 BlobAlreadyExists instead of 412 for some writes with If-None-Match "*".
+func isPreconditionFailedError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+	if ok && azureError.ServiceCode() == azblob.ServiceCodeBlobAlreadyExists {
+		return true
+	}
+
+	return ok && azureError.Response() != nil && azureError.Response().StatusCode == http.StatusPreconditionFailed
+}
+
+func isNotModifiedError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+
+	return ok && azureError.Response() != nil && azureError.Response().StatusCode == http.StatusNotModified
+}
+
+func isNotFoundError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+
+	return ok && azureError.ServiceCode() == azblob.ServiceCodeBlobNotFound
+}
+
+func isContainerAlreadyExistsError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+
+	return ok && azureError.ServiceCode() == azblob.ServiceCodeContainerAlreadyExists
+}
+
+// isNotFoundResponse returns true for the error of a request to a missing resource, identified by
+// code. The responses to HEAD requests have no body, so the service code is only set if the service
+// returns its header, and a 404 without a service code also means the resource doesn't exist.
+func isNotFoundResponse(err error, code azblob.ServiceCodeType) bool {
+	azureError, ok := err.(azblob.StorageError)
+	if !ok {
+		return false
+	}
+	if azureError.ServiceCode() == code {
+		return true
+	}
+
+	return azureError.ServiceCode() == "" && azureError.Response() != nil && azureError.Response().StatusCode == http.StatusNotFound
+}
+
+// parseCreateContainer returns the value of createContainer, or of its createContainerIfNotExists
+// alias, true if neither is set.
+func parseCreateContainer(connInfo map[string]string) (bool, error) {
+	var values []bool
+	for _, key := range []string{metadataKeyCreateContainer, metadataKeyCreateContainerIfNotExists} {
+		if val, ok := connInfo[key]; ok && val != "" {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return false, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			values = append(values, b)
+		}
+	}
+	switch {
+	case len(values) == 0:
+		return true, nil
+	case len(values) == 2 && values[0] != values[1]:
+		return false, fmt.Errorf("%s and %s are set to different values", metadataKeyCreateContainer, metadataKeyCreateContainerIfNotExists)
+	default:
+		return values[0], nil
+	}
+}
+
 // TODO: remove the pascal case support when the component moves to GA
 // See: https://github.com/dapr/components-contrib/pull/999#issuecomment-876890210
 func (a *AzureBlobStorage) handleBackwardCompatibilityForMetadata(metadata map[string]string) map[string]string {
@@ -6,14 +6,592 @@
 package blobstorage
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
 	"github.com/dapr/kit/logger"
 	"github.com/stretchr/testify/assert"
 )
 
+// newTestBlobStorage returns a binding sending its requests to service. The properties request of
+// the container sent by Init is answered without calling service.
+func newTestBlobStorage(t *testing.T, service http.Handler) *AzureBlobStorage {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); r.Method == http.MethodGet && q.Get("restype") == "container" && q.Get("comp") == "" {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+		service.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	assert.Nil(t, blobStorage.Init(bindings.Metadata{Properties: map[string]string{
+		"storageAccount":   "devstoreaccount1",
+		"storageAccessKey": "a2V5",
+		"container":        "test",
+		"createContainer":  "false",
+		"endpoint":         server.URL,
+	}}))
+
+	return blobStorage
+}
+
+// newOfflineBlobStorage returns a binding answering all of its requests with an empty 200 response,
+// for the tests of Init without a service.
+func newOfflineBlobStorage() *AzureBlobStorage {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.httpSender = pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			return pipeline.NewHTTPResponse(&http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    request.Request,
+			}), nil
+		}
+	})
+
+	return blobStorage
+}
+
+func TestGetSystemProperties(t *testing.T) {
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "\"etag\"")
+		w.Header().Set("Last-Modified", "Wed, 14 Oct 2026 10:00:00 GMT")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-MD5", "XUFAKrxLKna5cZ2REBfFkg==")
+		w.Header().Set("x-ms-meta-owner", "me")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}))
+
+	resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.GetOperation,
+		Metadata:  map[string]string{"blobName": "foo"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(resp.Data))
+	assert.Equal(t, map[string]string{
+		"etag":          "\"etag\"",
+		"lastModified":  "Wed, 14 Oct 2026 10:00:00 GMT",
+		"contentType":   "text/plain",
+		"contentLength": "5",
+		"contentMD5":    "XUFAKrxLKna5cZ2REBfFkg==",
+	}, resp.Metadata)
+}
+
+func TestGetRecordCount(t *testing.T) {
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("id,name\n1,a\n2,b\n"))
+	}))
+
+	t.Run("return the number of csv records", func(t *testing.T) {
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{"blobName": "foo.csv", "reportRecordCount": "true", "recordFormat": "csv", "csvHeader": "true"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "2", resp.Metadata["recordCount"])
+		assert.Equal(t, "id,name\n1,a\n2,b\n", string(resp.Data))
+	})
+
+	t.Run("return error for invalid recordFormat", func(t *testing.T) {
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{"blobName": "foo.csv", "reportRecordCount": "true", "recordFormat": "xml"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestEchoMetadata(t *testing.T) {
+	var uploaded http.Header
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded = r.Header
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"blobName": "foo", "owner": "me", "echo.requestId": "42"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"echo.requestId": "42"}, resp.Metadata)
+	assert.Equal(t, "me", uploaded.Get("x-ms-meta-owner"))
+	assert.Empty(t, uploaded.Get("x-ms-meta-echo.requestId"))
+}
+
+func TestCreateIfNoneMatch(t *testing.T) {
+	var ifNoneMatch string
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		w.Header().Set("x-ms-error-code", "BlobAlreadyExists")
+		w.WriteHeader(http.StatusConflict)
+	}))
+
+	_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"blobName": "foo", "ifNoneMatch": "*"},
+	})
+	assert.Equal(t, ErrBlobAlreadyExists, err)
+	assert.Equal(t, "*", ifNoneMatch)
+}
+
+func TestCreateEmpty(t *testing.T) {
+	var requests int
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "0", r.Header.Get("Content-Length"))
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	r := bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Metadata:  map[string]string{"blobName": "foo"},
+	}
+	_, err := blobStorage.Invoke(&r)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+
+	blobStorage.metadata.AllowEmpty = false
+	_, err = blobStorage.Invoke(&r)
+	assert.Equal(t, objectstorage.ErrEmptyData, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestCreateMetadataSize(t *testing.T) {
+	var requests int
+	var header http.Header
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		header = r.Header
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	create := func() error {
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata: map[string]string{
+				"blobName": "foo",
+				"a":        strings.Repeat("x", 4096),
+				"b":        strings.Repeat("y", 4096),
+				"c":        "z",
+			},
+		})
+
+		return err
+	}
+
+	err := create()
+	assert.True(t, errors.Is(err, objectstorage.ErrMetadataTooLarge))
+	assert.Equal(t, 0, requests)
+
+	blobStorage.metadata.TruncateMetadata = true
+	err = create()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Len(t, header.Get("x-ms-meta-a"), 4096)
+	assert.Len(t, header.Get("x-ms-meta-b"), 8*1024-4096-2)
+	assert.Empty(t, header.Get("x-ms-meta-c"))
+}
+
+func TestCreateThumbnail(t *testing.T) {
+	var img bytes.Buffer
+	assert.Nil(t, png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	uploads := map[string]string{}
+	var thumbnail []byte
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads[r.URL.Path] = r.Header.Get("x-ms-blob-content-type")
+		if strings.HasSuffix(r.URL.Path, ".thumb.jpg") {
+			thumbnail, _ = ioutil.ReadAll(r.Body)
+		}
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	create := func(data []byte, contentType string) (createResponse, error) {
+		var resp createResponse
+		out, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      data,
+			Metadata: map[string]string{
+				"blobName":          "foo",
+				"contentType":       contentType,
+				"generateThumbnail": "true",
+				"thumbnailSize":     "10x10",
+			},
+		})
+		if err != nil {
+			return resp, err
+		}
+		assert.Nil(t, json.Unmarshal(out.Data, &resp))
+
+		return resp, nil
+	}
+
+	t.Run("upload the thumbnail of an image", func(t *testing.T) {
+		resp, err := create(img.Bytes(), "image/png")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"/devstoreaccount1/test/foo": "image/png", "/devstoreaccount1/test/foo.thumb.jpg": "image/jpeg"}, uploads)
+		assert.True(t, strings.HasSuffix(resp.ThumbnailURL, "/devstoreaccount1/test/foo.thumb.jpg"), resp.ThumbnailURL)
+
+		config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+		assert.Nil(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, 10, config.Width)
+		assert.Equal(t, 5, config.Height)
+	})
+
+	t.Run("skip content that isn't an image", func(t *testing.T) {
+		uploads = map[string]string{}
+		resp, err := create([]byte("data"), "text/plain")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"/devstoreaccount1/test/foo": "text/plain"}, uploads)
+		assert.Empty(t, resp.ThumbnailURL)
+	})
+
+	t.Run("return error for a corrupted image without uploading it", func(t *testing.T) {
+		uploads = map[string]string{}
+		_, err := create(img.Bytes()[:img.Len()/2], "image/png")
+		assert.Error(t, err)
+		assert.Empty(t, uploads)
+	})
+}
+
+// fakeListService lists blobs in segments of at most pageSize blobs. The marker is the index of the
+// next blob, and the last segment has an empty NextMarker, or none if omitMarker is set.
+type fakeListService struct {
+	blobs      []string
+	pageSize   int
+	omitMarker bool
+	requested  []string
+}
+
+func (f *fakeListService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f.requested = append(f.requested, query.Get("maxresults"))
+	start, _ := strconv.Atoi(query.Get("marker"))
+	end, _ := strconv.Atoi(query.Get("maxresults"))
+	if end > f.pageSize {
+		end = f.pageSize
+	}
+	end += start
+	if end > len(f.blobs) {
+		end = len(f.blobs)
+	}
+
+	var body strings.Builder
+	body.WriteString("<EnumerationResults><Blobs>")
+	for _, name := range f.blobs[start:end] {
+		fmt.Fprintf(&body, "<Blob><Name>%s</Name><Properties></Properties></Blob>", name)
+	}
+	body.WriteString("</Blobs>")
+	if end < len(f.blobs) {
+		fmt.Fprintf(&body, "<NextMarker>%d</NextMarker>", end)
+	} else if !f.omitMarker {
+		body.WriteString("<NextMarker />")
+	}
+	body.WriteString("</EnumerationResults>")
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body.String()))
+}
+
+func TestList(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f", "g"}
+	list := func(t *testing.T, service *fakeListService, payload string) ([]listBlobEntry, map[string]string) {
+		blobStorage := newTestBlobStorage(t, service)
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.ListOperation,
+			Data:      []byte(payload),
+		})
+		assert.Nil(t, err)
+
+		var blobs []listBlobEntry
+		assert.Nil(t, json.Unmarshal(resp.Data, &blobs))
+
+		return blobs, resp.Metadata
+	}
+
+	t.Run("list all the segments", func(t *testing.T) {
+		service := &fakeListService{blobs: names, pageSize: 3}
+		blobs, metadata := list(t, service, `{}`)
+		assert.Len(t, blobs, 7)
+		assert.Equal(t, "g", blobs[6].Name)
+		assert.Equal(t, "", metadata["marker"])
+		assert.Equal(t, "7", metadata["number"])
+		assert.Equal(t, []string{"5000", "4997", "4994"}, service.requested)
+	})
+
+	t.Run("cap the blobs of all the segments to maxResults", func(t *testing.T) {
+		service := &fakeListService{blobs: names, pageSize: 3}
+		blobs, metadata := list(t, service, `{"maxResults": 5}`)
+		assert.Len(t, blobs, 5)
+		assert.Equal(t, "e", blobs[4].Name)
+		assert.Equal(t, "5", metadata["marker"])
+		assert.Equal(t, "5", metadata["number"])
+		assert.Equal(t, []string{"5", "2"}, service.requested)
+	})
+
+	t.Run("list a single segment with streamPages", func(t *testing.T) {
+		service := &fakeListService{blobs: names, pageSize: 3}
+		blobs, metadata := list(t, service, `{"streamPages": true, "marker": "3"}`)
+		assert.Len(t, blobs, 3)
+		assert.Equal(t, "d", blobs[0].Name)
+		assert.Equal(t, "6", metadata["marker"])
+		assert.Equal(t, "3", metadata["number"])
+		assert.Equal(t, []string{"5000"}, service.requested)
+	})
+
+	t.Run("handle a last segment without marker", func(t *testing.T) {
+		service := &fakeListService{blobs: names, pageSize: 3, omitMarker: true}
+		blobs, metadata := list(t, service, `{"maxResults": 10}`)
+		assert.Len(t, blobs, 7)
+		assert.Equal(t, "", metadata["marker"])
+		assert.Equal(t, []string{"10", "7", "4"}, service.requested)
+	})
+
+	t.Run("resume from the cursor", func(t *testing.T) {
+		service := &fakeListService{blobs: names, pageSize: 3}
+		blobs, metadata := list(t, service, `{"streamPages": true, "maxResults": 2, "prefix": "x"}`)
+		assert.Len(t, blobs, 2)
+		assert.NotEmpty(t, metadata["cursor"])
+
+		cursor, err := objectstorage.DecodeCursor(metadata["cursor"], "azure.blobstorage", "x")
+		assert.Nil(t, err)
+		assert.Equal(t, objectstorage.ListCursor{Provider: "azure.blobstorage", Marker: "2", Prefix: "x", PageSize: 2}, cursor)
+
+		// The prefix and maxResults of the cursor are used when they are not set
+		blobs, metadata = list(t, service, fmt.Sprintf(`{"streamPages": true, "cursor": %q}`, metadata["cursor"]))
+		assert.Len(t, blobs, 2)
+		assert.Equal(t, "c", blobs[0].Name)
+		assert.Equal(t, []string{"2", "2"}, service.requested)
+
+		blobs, metadata = list(t, service, fmt.Sprintf(`{"cursor": %q}`, metadata["cursor"]))
+		assert.Len(t, blobs, 2)
+		assert.Equal(t, "e", blobs[0].Name)
+		assert.NotEmpty(t, metadata["cursor"])
+
+		blobs, metadata = list(t, service, fmt.Sprintf(`{"maxResults": 10, "cursor": %q}`, metadata["cursor"]))
+		assert.Len(t, blobs, 1)
+		assert.Equal(t, "", metadata["cursor"])
+	})
+
+	t.Run("reject invalid cursors", func(t *testing.T) {
+		blobStorage := newTestBlobStorage(t, &fakeListService{blobs: names, pageSize: 3})
+		otherProvider, err := objectstorage.EncodeCursor(objectstorage.ListCursor{Provider: "aws.s3", Marker: "2"})
+		assert.Nil(t, err)
+		otherPrefix, err := objectstorage.EncodeCursor(objectstorage.ListCursor{Provider: "azure.blobstorage", Marker: "2", Prefix: "x"})
+		assert.Nil(t, err)
+		for _, payload := range []string{
+			fmt.Sprintf(`{"cursor": %q}`, otherProvider),
+			fmt.Sprintf(`{"cursor": %q, "prefix": "y"}`, otherPrefix),
+			`{"cursor": "2"}`,
+		} {
+			_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+				Operation: bindings.ListOperation,
+				Data:      []byte(payload),
+			})
+			assert.True(t, errors.Is(err, objectstorage.ErrInvalidCursor), payload)
+		}
+
+		_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.ListOperation,
+			Data:      []byte(fmt.Sprintf(`{"cursor": %q, "marker": "2"}`, otherPrefix)),
+		})
+		assert.Error(t, err)
+	})
+}
+
+type fakeMetrics struct {
+	ops   []bindings.OperationLabels
+	bytes []int64
+}
+
+func (f *fakeMetrics) IncrementOp(labels bindings.OperationLabels) {
+	f.ops = append(f.ops, labels)
+}
+
+func (f *fakeMetrics) ObserveLatency(labels bindings.OperationLabels, latency time.Duration) {}
+
+func (f *fakeMetrics) ObserveBytes(labels bindings.OperationLabels, bytes int64) {
+	f.bytes = append(f.bytes, bytes)
+}
+
+func TestInvokeWithContext(t *testing.T) {
+	var blobMetadata []string
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-ms-meta-block") != "" {
+			// Hangs until the binding gives up
+			<-r.Context().Done()
+
+			return
+		}
+		for name := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-ms-meta-") {
+				blobMetadata = append(blobMetadata, strings.ToLower(name))
+			}
+		}
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	t.Run("remove the timeout from the blob metadata", func(t *testing.T) {
+		_, err := blobStorage.InvokeWithContext(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"blobName": "a", "timeout": "1m", "owner": "me"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"x-ms-meta-owner"}, blobMetadata)
+	})
+
+	t.Run("cancel the requests with the timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := blobStorage.InvokeWithContext(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"blobName": "a", "timeout": "100ms", "block": "true"},
+		})
+		assert.Error(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+	})
+
+	t.Run("cancel the requests with the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := blobStorage.InvokeWithContext(ctx, &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"blobName": "a", "block": "true"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for invalid timeout", func(t *testing.T) {
+		_, err := blobStorage.InvokeWithContext(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"blobName": "a", "timeout": "soon"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestAllowedWriteWindow(t *testing.T) {
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	now := time.Now().UTC()
+	window, err := objectstorage.ParseWriteWindow(map[string]string{
+		"allowedWriteWindow": now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04"),
+	})
+	assert.Nil(t, err)
+	blobStorage.metadata.WriteWindow = window
+
+	for _, operation := range []bindings.OperationKind{bindings.CreateOperation, bindings.DeleteOperation, setTierOperation} {
+		_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: operation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"blobName": "a", "tier": "Cool"},
+		})
+		assert.True(t, errors.Is(err, objectstorage.ErrOutsideWriteWindow), operation)
+	}
+
+	_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: existsOperation,
+		Metadata:  map[string]string{"blobName": "a"},
+	})
+	assert.Nil(t, err)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &fakeMetrics{}
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			w.Header().Set("ETag", "\"etag\"")
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("hello"))
+		default:
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	blobStorage.metrics = metrics
+
+	_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{"blobName": "foo"},
+	})
+	assert.Nil(t, err)
+	_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.GetOperation,
+		Metadata:  map[string]string{"blobName": "foo"},
+	})
+	assert.Nil(t, err)
+	_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: previewOperation,
+		Metadata:  map[string]string{"blobName": "foo", "previewBytes": "2"},
+	})
+	assert.Nil(t, err)
+	_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.DeleteOperation,
+		Metadata:  map[string]string{"blobName": "foo"},
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, []bindings.OperationLabels{
+		{Operation: "create", Provider: "azure.blobstorage", Outcome: bindings.OutcomeSuccess},
+		{Operation: "get", Provider: "azure.blobstorage", Outcome: bindings.OutcomeSuccess},
+		{Operation: "preview", Provider: "azure.blobstorage", Outcome: bindings.OutcomeSuccess},
+		{Operation: "delete", Provider: "azure.blobstorage", Outcome: bindings.OutcomeError},
+	}, metrics.ops)
+	assert.Equal(t, []int64{4, 5, 2}, metrics.bytes)
+}
+
+func TestTransferredBytes(t *testing.T) {
+	resp := &bindings.InvokeResponse{Data: []byte("hello")}
+	assert.Equal(t, int64(4), transferredBytes(batchCreateOperation, 4, nil))
+	assert.Equal(t, int64(5), transferredBytes(batchGetOperation, 4, resp))
+	assert.Equal(t, int64(5), transferredBytes(previewOperation, 0, resp))
+	assert.Equal(t, int64(0), transferredBytes(previewOperation, 0, nil))
+	assert.Equal(t, int64(-1), transferredBytes(bindings.DeleteOperation, 0, nil))
+}
+
+func TestNewAzureBlobStorageWithMetrics(t *testing.T) {
+	blobStorage := NewAzureBlobStorageWithMetrics(logger.NewLogger("test"), nil)
+	assert.Equal(t, bindings.NoopMetrics, blobStorage.metrics)
+}
+
 func TestParseMetadata(t *testing.T) {
 	m := bindings.Metadata{}
 	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
@@ -34,6 +612,38 @@ func TestParseMetadata(t *testing.T) {
 		assert.Equal(t, true, meta.DecodeBase64)
 		assert.Equal(t, 5, meta.GetBlobRetryCount)
 		assert.Equal(t, azblob.PublicAccessNone, meta.PublicAccessLevel)
+		assert.Equal(t, int64(defaultBatchGetMaxSize), meta.BatchGetMaxSize)
+		assert.Equal(t, uint16(defaultUploadParallelism), meta.UploadParallelism)
+		assert.Equal(t, int64(0), meta.BlockSize)
+	})
+
+	t.Run("parse metadata with uploadParallelism and blockSize", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"uploadParallelism": "4",
+			"blockSize":         "8388608",
+		}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, uint16(4), meta.UploadParallelism)
+		assert.Equal(t, int64(8*1024*1024), meta.BlockSize)
+	})
+
+	t.Run("parse metadata with invalid blockSize", func(t *testing.T) {
+		for _, val := range []string{"-1", "104857601"} {
+			m.Properties = map[string]string{
+				"blockSize": val,
+			}
+			_, err := blobStorage.parseMetadata(m)
+			assert.Error(t, err, val)
+		}
+	})
+
+	t.Run("parse metadata with invalid uploadParallelism", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"uploadParallelism": "-1",
+		}
+		_, err := blobStorage.parseMetadata(m)
+		assert.Error(t, err)
 	})
 
 	t.Run("parse metadata with publicAccessLevel = blob", func(t *testing.T) {
@@ -54,6 +664,147 @@ func TestParseMetadata(t *testing.T) {
 		assert.Equal(t, azblob.PublicAccessContainer, meta.PublicAccessLevel)
 	})
 
+	t.Run("parse metadata with resumableUploadTTL", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"resumableUploadTTL": "30m",
+		}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, 30*time.Minute, meta.ResumableUploadTTL)
+	})
+
+	t.Run("parse metadata with downloadTryTimeout", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"downloadTryTimeout": "15s",
+		}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, 15*time.Second, meta.DownloadTryTimeout)
+	})
+
+	t.Run("parse metadata with strictBase64", func(t *testing.T) {
+		m.Properties = map[string]string{}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.True(t, meta.StrictBase64)
+
+		m.Properties = map[string]string{
+			"strictBase64": "false",
+		}
+		meta, err = blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.False(t, meta.StrictBase64)
+	})
+
+	t.Run("parse metadata with createContainer", func(t *testing.T) {
+		m.Properties = map[string]string{}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.True(t, meta.CreateContainer)
+
+		m.Properties = map[string]string{
+			"createContainer": "false",
+		}
+		meta, err = blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.False(t, meta.CreateContainer)
+	})
+
+	t.Run("parse metadata with consistentDelete", func(t *testing.T) {
+		m.Properties = map[string]string{}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Nil(t, meta.DeleteConsistency)
+
+		m.Properties = map[string]string{
+			"consistentDelete":         "true",
+			"consistentDeleteAttempts": "2",
+		}
+		meta, err = blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, &objectstorage.DeleteConsistency{Attempts: 2, Delay: objectstorage.DefaultConsistentDeleteDelay}, meta.DeleteConsistency)
+	})
+
+	t.Run("parse metadata with createContainerIfNotExists", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"createContainerIfNotExists": "false",
+		}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.False(t, meta.CreateContainer)
+
+		m.Properties = map[string]string{
+			"createContainer":            "false",
+			"createContainerIfNotExists": "false",
+		}
+		meta, err = blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.False(t, meta.CreateContainer)
+
+		m.Properties = map[string]string{
+			"createContainerIfNotExists": "maybe",
+		}
+		_, err = blobStorage.parseMetadata(m)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for conflicting createContainer and createContainerIfNotExists", func(t *testing.T) {
+		for _, values := range [][2]string{{"true", "false"}, {"false", "true"}} {
+			m.Properties = map[string]string{
+				"createContainer":            values[0],
+				"createContainerIfNotExists": values[1],
+			}
+			_, err := blobStorage.parseMetadata(m)
+			assert.Error(t, err, values)
+		}
+	})
+
+	t.Run("parse metadata with requestHeaders", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"requestHeaders": `{"x-gateway-auth": "secret"}`,
+		}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"x-gateway-auth": "secret"}, meta.RequestHeaders)
+
+		m.Properties = map[string]string{
+			"requestHeaders": "x-gateway-auth: secret",
+		}
+		_, err = blobStorage.parseMetadata(m)
+		assert.Error(t, err)
+	})
+
+	t.Run("parse metadata with tierCostPerGB", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"tierCostPerGB": `{"Hot": 0.0184, "Archive": 0.00099}`,
+		}
+		meta, err := blobStorage.parseMetadata(m)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]float64{"Hot": 0.0184, "Archive": 0.00099}, meta.TierCostPerGB)
+
+		m.Properties = map[string]string{
+			"tierCostPerGB": `{"Hot": "cheap"}`,
+		}
+		_, err = blobStorage.parseMetadata(m)
+		assert.Error(t, err)
+	})
+
+	t.Run("parse metadata with invalid resumableUploadTTL", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"resumableUploadTTL": "soon",
+		}
+		_, err := blobStorage.parseMetadata(m)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for negative durations", func(t *testing.T) {
+		for _, key := range []string{"resumableUploadTTL", "downloadTryTimeout", "tryTimeout"} {
+			m.Properties = map[string]string{key: "-1m"}
+			_, err := blobStorage.parseMetadata(m)
+			assert.Error(t, err, key)
+		}
+	})
+
 	t.Run("parse metadata with invalid publicAccessLevel", func(t *testing.T) {
 		m.Properties = map[string]string{
 			"publicAccessLevel": "invalid",
@@ -63,16 +814,367 @@ func TestParseMetadata(t *testing.T) {
 	})
 }
 
+func TestCreateOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{DecodeBase64: true, StrictBase64: true}
+
+	t.Run("return error with blob name for invalid base64", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data:     []byte("not base64!"),
+			Metadata: map[string]string{"blobName": "foo"},
+		}
+		_, err := blobStorage.create(context.Background(), &r)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "foo")
+		assert.Contains(t, err.Error(), "decodeBase64")
+	})
+
+	t.Run("validate metadata names without uploading", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data:     []byte("ZGF0YQ=="),
+			Metadata: map[string]string{"blobName": "foo", "validateOnly": "true", "my-key": "value"},
+		}
+		_, err := blobStorage.create(context.Background(), &r)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "my-key")
+	})
+
+	t.Run("validate the size of ranges without uploading", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data:     []byte("ZGF0YQ=="),
+			Metadata: map[string]string{"blobName": "foo", "validateOnly": "true", "contentRange": "bytes 0-9/20"},
+		}
+		_, err := blobStorage.create(context.Background(), &r)
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeAndHashData(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	t.Run("hash the decoded data", func(t *testing.T) {
+		blobStorage.metadata = &blobStorageMetadata{DecodeBase64: true}
+		data, digest, err := blobStorage.decodeAndHashData("foo", []byte("ZGF0YQ=="))
+		assert.Nil(t, err)
+		assert.Equal(t, "data", string(data))
+		assert.Equal(t, objectstorage.SHA256([]byte("data")), digest)
+	})
+
+	t.Run("don't hash the data that isn't decoded", func(t *testing.T) {
+		blobStorage.metadata = &blobStorageMetadata{}
+		data, digest, err := blobStorage.decodeAndHashData("foo", []byte("ZGF0YQ=="))
+		assert.Nil(t, err)
+		assert.Equal(t, "ZGF0YQ==", string(data))
+		assert.Empty(t, digest)
+
+		blobStorage.metadata = &blobStorageMetadata{DecodeBase64: true}
+		data, digest, err = blobStorage.decodeAndHashData("foo", []byte("not base64!"))
+		assert.Nil(t, err)
+		assert.Equal(t, "not base64!", string(data))
+		assert.Empty(t, digest)
+	})
+}
+
 func TestGetOption(t *testing.T) {
 	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
 
 	t.Run("return error if blobName is missing", func(t *testing.T) {
 		r := bindings.InvokeRequest{}
-		_, err := blobStorage.get(&r)
+		_, err := blobStorage.get(context.Background(), &r)
 		if assert.Error(t, err) {
 			assert.Equal(t, ErrMissingBlobName, err)
 		}
 	})
+
+	t.Run("return error for invalid missingObjectBehavior", func(t *testing.T) {
+		r := bindings.InvokeRequest{}
+		r.Metadata = map[string]string{
+			"blobName":              "foo",
+			"missingObjectBehavior": "invalid",
+		}
+		_, err := blobStorage.get(context.Background(), &r)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for invalid ifModifiedSince", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Metadata: map[string]string{"blobName": "foo", "ifModifiedSince": "yesterday"},
+		}
+		_, err := blobStorage.get(context.Background(), &r)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for unsupported sourceEncoding", func(t *testing.T) {
+		r := bindings.InvokeRequest{}
+		r.Metadata = map[string]string{
+			"blobName":       "foo",
+			"sourceEncoding": "not-an-encoding",
+		}
+		_, err := blobStorage.get(context.Background(), &r)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "sourceEncoding")
+		}
+	})
+}
+
+func TestParseModifiedAccessConditions(t *testing.T) {
+	t.Run("parse http date and etag", func(t *testing.T) {
+		conditions, err := parseModifiedAccessConditions(map[string]string{
+			"ifModifiedSince": "Sun, 01 Aug 2021 10:00:00 GMT",
+			"ifNoneMatch":     "\"0x1\"",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC), conditions.IfModifiedSince)
+		assert.Equal(t, azblob.ETag("\"0x1\""), conditions.IfNoneMatch)
+	})
+
+	t.Run("parse rfc3339 date", func(t *testing.T) {
+		conditions, err := parseModifiedAccessConditions(map[string]string{"ifModifiedSince": "2021-08-01T10:00:00Z"})
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC), conditions.IfModifiedSince)
+	})
+}
+
+// fakeUploadResponse is the response of an upload with the etag and version ID headers.
+type fakeUploadResponse struct {
+	azblob.CommonResponse
+}
+
+func (fakeUploadResponse) ETag() azblob.ETag {
+	return "\"0x1\""
+}
+
+func (fakeUploadResponse) Response() *http.Response {
+	return &http.Response{Header: http.Header{"X-Ms-Version-Id": {"2021-08-01T10:00:00.0000000Z"}}}
+}
+
+func TestMarshalCreateResponse(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{Container: "test"}
+	u, _ := url.Parse("https://account.blob.core.windows.net/test/foo")
+	blobURL := azblob.NewBlockBlobURL(*u, azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{}))
+
+	t.Run("return blob url by default", func(t *testing.T) {
+		b, err := blobStorage.marshalCreateResponse(blobURL, "foo", fakeUploadResponse{}, 4, "text/plain", "digest", "", nil)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"blobURL": "https://account.blob.core.windows.net/test/foo", "sha256": "digest"}`, string(b))
+	})
+
+	t.Run("return canonical response", func(t *testing.T) {
+		blobStorage.metadata.CanonicalResponse = true
+		b, err := blobStorage.marshalCreateResponse(blobURL, "foo", fakeUploadResponse{}, 4, "text/plain", "digest", "", nil)
+		assert.Nil(t, err)
+
+		var resp objectstorage.CanonicalResponse
+		assert.Nil(t, json.Unmarshal(b, &resp))
+		assert.Equal(t, objectstorage.CanonicalResponse{
+			Provider:    "azure.blobstorage",
+			Bucket:      "test",
+			Key:         "foo",
+			URL:         "https://account.blob.core.windows.net/test/foo",
+			ETag:        "\"0x1\"",
+			VersionID:   "2021-08-01T10:00:00.0000000Z",
+			Size:        4,
+			ContentType: "text/plain",
+		}, resp)
+	})
+}
+
+func TestMarshalListResult(t *testing.T) {
+	size := func(v int64) *int64 { return &v }
+	blobs := []azblob.BlobItem{
+		{Name: "a", Properties: azblob.BlobProperties{AccessTier: azblob.AccessTierHot, ContentLength: size(10)}},
+		{Name: "b", Properties: azblob.BlobProperties{AccessTier: azblob.AccessTierHot, ContentLength: size(5)}},
+		{Name: "c", Properties: azblob.BlobProperties{AccessTier: azblob.AccessTierArchive, ContentLength: size(100)}},
+		{Name: "d", Properties: azblob.BlobProperties{ContentLength: size(1)}},
+	}
+
+	t.Run("group blobs by tier", func(t *testing.T) {
+		b, err := marshalListResult(blobs, true, nil)
+		assert.Nil(t, err)
+
+		var tiers map[string]tierSummary
+		assert.Nil(t, json.Unmarshal(b, &tiers))
+		assert.Equal(t, map[string]tierSummary{
+			"Hot":     {Count: 2, Bytes: 15},
+			"Archive": {Count: 1, Bytes: 100},
+			"None":    {Count: 1, Bytes: 1},
+		}, tiers)
+	})
+
+	t.Run("estimate the cost of the tiers with a rate", func(t *testing.T) {
+		gib := int64(1 << 30)
+		b, err := marshalListResult([]azblob.BlobItem{
+			{Name: "a", Properties: azblob.BlobProperties{AccessTier: azblob.AccessTierHot, ContentLength: size(2 * gib)}},
+			{Name: "b", Properties: azblob.BlobProperties{AccessTier: azblob.AccessTierCool, ContentLength: size(gib)}},
+		}, true, map[string]float64{"Hot": 0.02})
+		assert.Nil(t, err)
+
+		var tiers map[string]tierSummary
+		assert.Nil(t, json.Unmarshal(b, &tiers))
+		if assert.NotNil(t, tiers["Hot"].EstimatedMonthlyCost) {
+			assert.InDelta(t, 0.04, *tiers["Hot"].EstimatedMonthlyCost, 1e-9)
+		}
+		assert.Nil(t, tiers["Cool"].EstimatedMonthlyCost)
+	})
+
+	t.Run("return blob entries without grouping", func(t *testing.T) {
+		lastModified := time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC)
+		contentType := "text/plain"
+		b, err := marshalListResult([]azblob.BlobItem{{
+			Name:     "a",
+			Snapshot: "2021-08-01T10:00:00.0000000Z",
+			Properties: azblob.BlobProperties{
+				LastModified:  lastModified,
+				Etag:          "0x1",
+				ContentLength: size(10),
+				ContentType:   &contentType,
+				AccessTier:    azblob.AccessTierHot,
+				BlobType:      azblob.BlobBlockBlob,
+			},
+			Metadata: azblob.Metadata{"k": "v"},
+		}, {Name: "b"}}, false, nil)
+		assert.Nil(t, err)
+
+		var entries []map[string]interface{}
+		assert.Nil(t, json.Unmarshal(b, &entries))
+		assert.Equal(t, []map[string]interface{}{{
+			"name":         "a",
+			"size":         float64(10),
+			"lastModified": "2021-08-01T10:00:00Z",
+			"contentType":  "text/plain",
+			"tier":         "Hot",
+			"etag":         "0x1",
+			"metadata":     map[string]interface{}{"k": "v"},
+			"snapshot":     "2021-08-01T10:00:00.0000000Z",
+		}, {
+			"name":         "b",
+			"size":         float64(0),
+			"lastModified": "0001-01-01T00:00:00Z",
+			"etag":         "",
+		}}, entries)
+	})
+
+	t.Run("return an empty array without blobs", func(t *testing.T) {
+		b, err := marshalListResult(nil, false, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "[]", string(b))
+	})
+}
+
+func TestParseWriteAccessConditions(t *testing.T) {
+	t.Run("parse conditions and remove them from metadata", func(t *testing.T) {
+		metadata := map[string]string{
+			"leaseId":           "lease",
+			"ifMatch":           "\"0x1\"",
+			"ifUnmodifiedSince": "2021-08-01T10:00:00Z",
+			"custom":            "value",
+		}
+		conditions, err := parseWriteAccessConditions(metadata)
+		assert.Nil(t, err)
+		assert.Equal(t, "lease", conditions.LeaseAccessConditions.LeaseID)
+		assert.Equal(t, azblob.ETag("\"0x1\""), conditions.ModifiedAccessConditions.IfMatch)
+		assert.Equal(t, time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC), conditions.ModifiedAccessConditions.IfUnmodifiedSince)
+		assert.Equal(t, map[string]string{"custom": "value"}, metadata)
+	})
+
+	t.Run("parse ifNoneMatch", func(t *testing.T) {
+		conditions, err := parseWriteAccessConditions(map[string]string{"ifNoneMatch": "*"})
+		assert.Nil(t, err)
+		assert.Equal(t, azblob.ETagAny, conditions.ModifiedAccessConditions.IfNoneMatch)
+		assert.Equal(t, ErrBlobAlreadyExists, preconditionFailedError(conditions))
+		assert.True(t, errors.Is(ErrBlobAlreadyExists, ErrPreconditionFailed))
+	})
+
+	t.Run("return error for invalid ifUnmodifiedSince", func(t *testing.T) {
+		_, err := parseWriteAccessConditions(map[string]string{"ifUnmodifiedSince": "yesterday"})
+		assert.Error(t, err)
+	})
+}
+
+func TestPreviewOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		r := bindings.InvokeRequest{}
+		_, err := blobStorage.preview(context.Background(), &r)
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+
+	t.Run("return error for invalid previewBytes", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Metadata: map[string]string{"blobName": "foo", "previewBytes": "-1"},
+		}
+		_, err := blobStorage.preview(context.Background(), &r)
+		assert.Error(t, err)
+	})
+
+	t.Run("return the properties of the blob", func(t *testing.T) {
+		blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Range", "bytes 0-1/4")
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("ETag", "\"etag\"")
+			w.Header().Set("Last-Modified", "Wed, 14 Oct 2026 10:00:00 GMT")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("da"))
+		}))
+
+		resp, err := blobStorage.preview(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"blobName": "foo", "previewBytes": "2"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("da"), resp.Data)
+		assert.Equal(t, map[string]string{
+			"contentLength": "4",
+			"etag":          "\"etag\"",
+			"lastModified":  "Wed, 14 Oct 2026 10:00:00 GMT",
+			"contentType":   "text/plain",
+		}, resp.Metadata)
+	})
+}
+
+func TestTouchOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		r := bindings.InvokeRequest{}
+		_, err := blobStorage.touch(context.Background(), &r)
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+
+	t.Run("keep the metadata and headers of the blob", func(t *testing.T) {
+		var set *http.Request
+		blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodHead:
+				w.Header().Set("ETag", "\"etag\"")
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("x-ms-meta-OwnerName", "me")
+			case http.MethodPut:
+				set = r
+				w.Header().Set("ETag", "\"etag2\"")
+				w.Header().Set("Last-Modified", "Wed, 14 Oct 2026 10:00:00 GMT")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		resp, err := blobStorage.touch(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"blobName": "foo"},
+		})
+		assert.Nil(t, err)
+		if assert.NotNil(t, set) {
+			assert.Equal(t, "properties", set.URL.Query().Get("comp"))
+			assert.Equal(t, "text/plain", set.Header.Get("x-ms-blob-content-type"))
+			assert.Equal(t, "\"etag\"", set.Header.Get("If-Match"))
+			for name := range set.Header {
+				assert.False(t, strings.HasPrefix(strings.ToLower(name), "x-ms-meta-"), name)
+			}
+		}
+
+		var touched touchResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &touched))
+		assert.Equal(t, "\"etag2\"", touched.ETag)
+	})
 }
 
 func TestDeleteOption(t *testing.T) {
@@ -80,7 +1182,7 @@ func TestDeleteOption(t *testing.T) {
 
 	t.Run("return error if blobName is missing", func(t *testing.T) {
 		r := bindings.InvokeRequest{}
-		_, err := blobStorage.delete(&r)
+		_, err := blobStorage.delete(context.Background(), &r)
 		if assert.Error(t, err) {
 			assert.Equal(t, ErrMissingBlobName, err)
 		}
@@ -92,7 +1194,292 @@ func TestDeleteOption(t *testing.T) {
 			"blobName":        "foo",
 			"deleteSnapshots": "invalid",
 		}
-		_, err := blobStorage.delete(&r)
+		_, err := blobStorage.delete(context.Background(), &r)
 		assert.Error(t, err)
 	})
 }
+
+func TestBatchHeadOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	t.Run("return error for invalid payload", func(t *testing.T) {
+		r := bindings.InvokeRequest{Data: []byte(`{"blobName": "foo"}`)}
+		_, err := blobStorage.batchHead(context.Background(), &r)
+		assert.Error(t, err)
+	})
+}
+
+type blockingReadCloser struct {
+	closed chan struct{}
+}
+
+func (b *blockingReadCloser) Read(p []byte) (int, error) {
+	<-b.closed
+
+	return 0, errors.New(azblob.ReadOnClosedBodyMessage)
+}
+
+func (b *blockingReadCloser) Close() error {
+	close(b.closed)
+
+	return nil
+}
+
+func TestObjectReplicationProperties(t *testing.T) {
+	t.Run("return object replication headers", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("x-ms-or-policy-id", "policy")
+		header.Set("x-ms-or-policy_rule", "complete")
+		header.Set("x-ms-version", "2019-12-12")
+		assert.Equal(t, map[string]string{"policy-id": "policy", "policy_rule": "complete"}, objectReplicationProperties(header))
+	})
+
+	t.Run("return nil without object replication", func(t *testing.T) {
+		assert.Nil(t, objectReplicationProperties(http.Header{}))
+	})
+}
+
+func TestStallTimeoutReader(t *testing.T) {
+	t.Run("return timeout error when the stream stalls", func(t *testing.T) {
+		r := newStallTimeoutReader(&blockingReadCloser{closed: make(chan struct{})}, 10*time.Millisecond)
+		_, err := r.Read(make([]byte, 1))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "download timed out")
+		}
+	})
+}
+
+func TestStrictKeyValidation(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{StrictKeyValidation: true}
+
+	t.Run("reject blob names escaping their path", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{"blobName": "../foo"},
+		}
+		_, err := blobStorage.Invoke(&r)
+		assert.ErrorIs(t, err, objectstorage.ErrInvalidKey)
+	})
+
+	t.Run("reject batch payloads with invalid names", func(t *testing.T) {
+		r := bindings.InvokeRequest{Data: []byte(`["foo", "/bar"]`)}
+		_, err := blobStorage.batchGet(context.Background(), &r)
+		assert.ErrorIs(t, err, objectstorage.ErrInvalidKey)
+	})
+}
+
+func TestStorageCredentialProperties(t *testing.T) {
+	properties := map[string]string{"storageAccount": "account", "storageAccessKey": "key", "azureClientId": "id"}
+	result := storageCredentialProperties(properties, "key")
+	assert.Equal(t, "key", result["accountKey"])
+	assert.Equal(t, "id", result["azureClientId"])
+	assert.NotContains(t, properties, "accountKey")
+}
+
+func TestInitEndpoint(t *testing.T) {
+	m := bindings.Metadata{Properties: map[string]string{
+		"storageAccount":   "devstoreaccount1",
+		"storageAccessKey": "a2V5",
+		"container":        "test",
+		"createContainer":  "false",
+	}}
+
+	t.Run("address the container on the public cloud by default", func(t *testing.T) {
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(m))
+		assert.Equal(t, "https://devstoreaccount1.blob.core.windows.net/test", blobStorage.containerURL.String())
+	})
+
+	t.Run("address the container on the custom endpoint", func(t *testing.T) {
+		m.Properties["endpoint"] = "http://127.0.0.1:10000/"
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(m))
+		assert.Equal(t, "http://127.0.0.1:10000/devstoreaccount1/test", blobStorage.containerURL.String())
+	})
+
+	t.Run("return error for invalid endpoint", func(t *testing.T) {
+		m.Properties["endpoint"] = "http://[::1"
+		blobStorage := newOfflineBlobStorage()
+		assert.Error(t, blobStorage.Init(m))
+	})
+}
+
+func TestInitCreateContainer(t *testing.T) {
+	initBinding := func(t *testing.T, properties map[string]string, handler http.HandlerFunc) ([]string, error) {
+		var methods []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			handler(w, r)
+		}))
+		defer server.Close()
+
+		m := bindings.Metadata{Properties: map[string]string{
+			"storageAccount":   "devstoreaccount1",
+			"storageAccessKey": "a2V5",
+			"container":        "test",
+			"endpoint":         server.URL,
+			"maxTries":         "1",
+		}}
+		for k, v := range properties {
+			m.Properties[k] = v
+		}
+		blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+		err := blobStorage.Init(m)
+
+		return methods, err
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	t.Run("create the container by default", func(t *testing.T) {
+		for _, properties := range []map[string]string{
+			{},
+			{"createContainer": "true"},
+			{"createContainerIfNotExists": "true"},
+			{"createContainer": "true", "createContainerIfNotExists": "true"},
+		} {
+			methods, err := initBinding(t, properties, ok)
+			assert.Nil(t, err, properties)
+			assert.Equal(t, []string{http.MethodPut}, methods, properties)
+		}
+	})
+
+	t.Run("ignore a container that already exists", func(t *testing.T) {
+		_, err := initBinding(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-ms-error-code", "ContainerAlreadyExists")
+			w.WriteHeader(http.StatusConflict)
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("verify the container exists without creating it", func(t *testing.T) {
+		for _, properties := range []map[string]string{
+			{"createContainer": "false"},
+			{"createContainerIfNotExists": "false"},
+			{"createContainer": "false", "createContainerIfNotExists": "false"},
+		} {
+			methods, err := initBinding(t, properties, ok)
+			assert.Nil(t, err, properties)
+			assert.Equal(t, []string{http.MethodGet}, methods, properties)
+
+			methods, err = initBinding(t, properties, notFound)
+			assert.Error(t, err, properties)
+			assert.Contains(t, err.Error(), "does not exist")
+			assert.Equal(t, []string{http.MethodGet}, methods, properties)
+		}
+	})
+
+	t.Run("return error for a container that can't be accessed", func(t *testing.T) {
+		_, err := initBinding(t, map[string]string{"createContainer": "false"}, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-ms-error-code", "AuthorizationFailure")
+			w.WriteHeader(http.StatusForbidden)
+		})
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "does not exist")
+	})
+
+	t.Run("return error for conflicting values", func(t *testing.T) {
+		for _, properties := range []map[string]string{
+			{"createContainer": "true", "createContainerIfNotExists": "false"},
+			{"createContainer": "false", "createContainerIfNotExists": "true"},
+		} {
+			methods, err := initBinding(t, properties, ok)
+			assert.Error(t, err, properties)
+			assert.Empty(t, methods, properties)
+		}
+	})
+}
+
+func TestBilledRequests(t *testing.T) {
+	service := &fakeListService{blobs: []string{"a", "b", "c", "d", "e", "f", "g"}, pageSize: 2}
+	blobStorage := newTestBlobStorage(t, service)
+	list := func() *bindings.InvokeResponse {
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.ListOperation,
+			Data:      []byte(`{}`),
+		})
+		assert.Nil(t, err)
+
+		return resp
+	}
+
+	resp := list()
+	assert.NotContains(t, resp.Metadata, "billedRequests")
+
+	blobStorage.metadata.ReturnBilledRequests = true
+	resp = list()
+	assert.Equal(t, "4", resp.Metadata["billedRequests"])
+}
+
+func TestConsistentDelete(t *testing.T) {
+	var heads, visibleFor int
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+		heads++
+		if heads <= visibleFor {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	del := func(visible int, metadata map[string]string) error {
+		heads, visibleFor = 0, visible
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.DeleteOperation,
+			Metadata:  metadata,
+		})
+
+		return err
+	}
+
+	t.Run("don't check the blob by default", func(t *testing.T) {
+		assert.Nil(t, del(5, map[string]string{"blobName": "foo"}))
+		assert.Equal(t, 0, heads)
+	})
+
+	blobStorage.metadata.DeleteConsistency = &objectstorage.DeleteConsistency{Attempts: 3, Delay: time.Millisecond}
+
+	t.Run("wait until the blob is gone", func(t *testing.T) {
+		assert.Nil(t, del(0, map[string]string{"blobName": "foo"}))
+		assert.Equal(t, 1, heads)
+
+		assert.Nil(t, del(2, map[string]string{"blobName": "foo"}))
+		assert.Equal(t, 3, heads)
+	})
+
+	t.Run("return error if the blob is still visible", func(t *testing.T) {
+		err := del(3, map[string]string{"blobName": "foo"})
+		assert.True(t, errors.Is(err, objectstorage.ErrDeleteNotConfirmed))
+		assert.Equal(t, 3, heads)
+	})
+
+	t.Run("don't check the blob when only deleting its snapshots", func(t *testing.T) {
+		assert.Nil(t, del(5, map[string]string{"blobName": "foo", "deleteSnapshots": "only"}))
+		assert.Equal(t, 0, heads)
+	})
+
+	t.Run("report the blobs of deleteBatch that are still visible", func(t *testing.T) {
+		heads, visibleFor = 0, 3
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: deleteBatchOperation,
+			Data:      []byte(`["foo"]`),
+		})
+		assert.Nil(t, err)
+
+		var results []batchDeleteResult
+		assert.Nil(t, json.Unmarshal(resp.Data, &results))
+		if assert.Len(t, results, 1) {
+			assert.True(t, results[0].Deleted)
+			assert.Contains(t, results[0].Error, objectstorage.ErrDeleteNotConfirmed.Error())
+		}
+	})
+}
@@ -0,0 +1,311 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/linkedin/goavro/v2"
+)
+
+// The change feed of a storage account is stored as Avro files in the $blobchangefeed container. The
+// feed is split in hourly segments, each one described by a manifest listing the directories (shards)
+// that contain its chunk files.
+// See: https://docs.microsoft.com/en-us/azure/storage/blobs/storage-blob-change-feed
+
+const (
+	readChangeFeedOperation bindings.OperationKind = "readChangeFeed"
+
+	changeFeedContainer      = "$blobchangefeed"
+	changeFeedSegmentsPrefix = "idx/segments/"
+	// The first segment is created when the change feed is enabled and contains no events
+	changeFeedInitSegment       = "idx/segments/1601/01/01/0000/meta.json"
+	changeFeedSegmentFinalized  = "Finalized"
+	defaultChangeFeedMaxEvents  = 1000
+	changeFeedSubjectBlobsInfix = "/blobs/"
+)
+
+type changeFeedPayload struct {
+	// Opaque cursor returned by a previous readChangeFeed, the feed is read from the start if empty
+	Cursor    string `json:"cursor"`
+	MaxEvents int    `json:"maxEvents"`
+}
+
+// changeFeedCursor is the position after the last event returned. Chunks are ordered by name
+// within a segment, so the chunk name and the number of events read from it identify the position.
+type changeFeedCursor struct {
+	Segment string `json:"segment"`
+	Chunk   string `json:"chunk"`
+	Events  int64  `json:"events"`
+}
+
+type changeFeedEvent struct {
+	ID string `json:"id"`
+	// One of created, updated or deleted
+	Change    string `json:"change"`
+	EventType string `json:"eventType"`
+	EventTime string `json:"eventTime"`
+	BlobName  string `json:"blobName"`
+}
+
+type changeFeedResponse struct {
+	Events []changeFeedEvent `json:"events"`
+	Cursor string            `json:"cursor"`
+}
+
+type changeFeedSegment struct {
+	Status         string   `json:"status"`
+	ChunkFilePaths []string `json:"chunkFilePaths"`
+}
+
+func parseChangeFeedCursor(val string) (changeFeedCursor, error) {
+	var c changeFeedCursor
+	if val == "" {
+		return c, nil
+	}
+
+	b, err := b64.RawURLEncoding.DecodeString(val)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return c, fmt.Errorf("invalid change feed cursor: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c changeFeedCursor) String() string {
+	b, _ := json.Marshal(c)
+
+	return b64.RawURLEncoding.EncodeToString(b)
+}
+
+// changeFeedChange maps the event types of the change feed to the kind of change of the blob.
+func changeFeedChange(eventType string) string {
+	switch eventType {
+	case "BlobCreated":
+		return "created"
+	case "BlobDeleted":
+		return "deleted"
+	default:
+		return "updated"
+	}
+}
+
+// avroString returns the value of a string field, which goavro decodes as a map when the field is
+// a nullable union.
+func avroString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if s, ok := v["string"].(string); ok {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// readChangeFeedChunk decodes the events of a chunk file after the first skip ones, stopping after
+// max events. Only the events of blobs in the container are returned, but all the events read are
+// counted in the returned position.
+func readChangeFeedChunk(r io.Reader, container string, skip int64, max int) ([]changeFeedEvent, int64, error) {
+	ocf, err := goavro.NewOCFReader(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading change feed chunk: %w", err)
+	}
+
+	subjectPrefix := "/blobServices/default/containers/" + container + changeFeedSubjectBlobsInfix
+	events := []changeFeedEvent{}
+	var position int64
+	for len(events) < max && ocf.Scan() {
+		record, err := ocf.Read()
+		if err != nil {
+			return nil, 0, fmt.Errorf("error decoding change feed event: %w", err)
+		}
+		position++
+		if position <= skip {
+			continue
+		}
+
+		fields, ok := record.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		subject := avroString(fields["subject"])
+		if !strings.HasPrefix(subject, subjectPrefix) {
+			continue
+		}
+		eventType := avroString(fields["eventType"])
+		events = append(events, changeFeedEvent{
+			ID:        avroString(fields["id"]),
+			Change:    changeFeedChange(eventType),
+			EventType: eventType,
+			EventTime: avroString(fields["eventTime"]),
+			BlobName:  strings.TrimPrefix(subject, subjectPrefix),
+		})
+	}
+	if err := ocf.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error reading change feed chunk: %w", err)
+	}
+
+	return events, position, nil
+}
+
+// listChangeFeedBlobs returns the names of the blobs of the change feed container with the prefix, sorted.
+func listChangeFeedBlobs(ctx context.Context, containerURL azblob.ContainerURL, prefix string) ([]string, error) {
+	names := []string{}
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: prefix})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Segment.BlobItems {
+			names = append(names, item.Name)
+		}
+		marker = resp.NextMarker
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func downloadChangeFeedBlob(ctx context.Context, containerURL azblob.ContainerURL, name string) (io.ReadCloser, error) {
+	resp, err := containerURL.NewBlobURL(name).Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body(azblob.RetryReaderOptions{}), nil
+}
+
+func (a *AzureBlobStorage) readChangeFeed(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var payload changeFeedPayload
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &payload); err != nil {
+			return nil, fmt.Errorf("error parsing readChangeFeed payload: %w", err)
+		}
+	}
+	if payload.MaxEvents <= 0 {
+		payload.MaxEvents = defaultChangeFeedMaxEvents
+	}
+	cursor, err := parseChangeFeedCursor(payload.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceURL := a.containerURL.URL()
+	serviceURL.Path = ""
+	feedURL := azblob.NewServiceURL(serviceURL, a.pipeline).NewContainerURL(changeFeedContainer)
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	segments, err := listChangeFeedBlobs(ctx, feedURL, changeFeedSegmentsPrefix)
+	if err != nil {
+		if azureError, ok := err.(azblob.StorageError); ok && azureError.ServiceCode() == azblob.ServiceCodeContainerNotFound {
+			return nil, fmt.Errorf("the change feed is not enabled for storage account %s", a.metadata.StorageAccount)
+		}
+
+		return nil, fmt.Errorf("error listing change feed segments: %w", err)
+	}
+
+	events := []changeFeedEvent{}
+segments:
+	for _, segmentName := range segments {
+		if !strings.HasSuffix(segmentName, "meta.json") || segmentName == changeFeedInitSegment || segmentName < cursor.Segment {
+			continue
+		}
+
+		segment, err := a.readChangeFeedSegment(ctx, feedURL, segmentName)
+		if err != nil {
+			return nil, err
+		}
+
+		var chunks []string
+		for _, chunkPath := range segment.ChunkFilePaths {
+			names, err := listChangeFeedBlobs(ctx, feedURL, strings.TrimPrefix(chunkPath, changeFeedContainer+"/"))
+			if err != nil {
+				return nil, fmt.Errorf("error listing change feed chunks: %w", err)
+			}
+			chunks = append(chunks, names...)
+		}
+		sort.Strings(chunks)
+
+		for _, chunk := range chunks {
+			var skip int64
+			if segmentName == cursor.Segment {
+				if chunk < cursor.Chunk {
+					continue
+				}
+				if chunk == cursor.Chunk {
+					skip = cursor.Events
+				}
+			}
+
+			body, err := downloadChangeFeedBlob(ctx, feedURL, chunk)
+			if err != nil {
+				return nil, fmt.Errorf("error downloading change feed chunk %s: %w", chunk, err)
+			}
+			chunkEvents, position, err := readChangeFeedChunk(body, a.metadata.Container, skip, payload.MaxEvents-len(events))
+			body.Close()
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, chunkEvents...)
+			if position < skip {
+				position = skip
+			}
+			cursor = changeFeedCursor{Segment: segmentName, Chunk: chunk, Events: position}
+
+			if len(events) >= payload.MaxEvents {
+				break segments
+			}
+		}
+
+		// The chunks of a segment that isn't finalized yet may still get events
+		if segment.Status != changeFeedSegmentFinalized {
+			break
+		}
+	}
+
+	b, err := json.Marshal(changeFeedResponse{Events: events, Cursor: cursor.String()})
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal change feed events to json: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+		Metadata: map[string]string{
+			metadataKeyNumber: strconv.Itoa(len(events)),
+		},
+	}, nil
+}
+
+func (a *AzureBlobStorage) readChangeFeedSegment(ctx context.Context, feedURL azblob.ContainerURL, name string) (*changeFeedSegment, error) {
+	body, err := downloadChangeFeedBlob(ctx, feedURL, name)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading change feed segment %s: %w", name, err)
+	}
+	defer body.Close()
+
+	var segment changeFeedSegment
+	if err := json.NewDecoder(body).Decode(&segment); err != nil {
+		return nil, fmt.Errorf("error parsing change feed segment %s: %w", name, err)
+	}
+
+	return &segment, nil
+}
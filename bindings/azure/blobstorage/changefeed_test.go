@@ -0,0 +1,89 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChangeFeedSchema = `{
+	"type": "record",
+	"name": "BlobChangeEvent",
+	"fields": [
+		{"name": "id", "type": "string"},
+		{"name": "eventType", "type": "string"},
+		{"name": "eventTime", "type": "string"},
+		{"name": "subject", "type": ["null", "string"]}
+	]
+}`
+
+func testChangeFeedChunk(t *testing.T, records ...map[string]interface{}) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: buf, Schema: testChangeFeedSchema})
+	assert.Nil(t, err)
+	assert.Nil(t, w.Append(records))
+
+	return buf
+}
+
+func testChangeFeedRecord(id, eventType, subject string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        id,
+		"eventType": eventType,
+		"eventTime": "2021-08-01T10:00:00Z",
+		"subject":   goavro.Union("string", subject),
+	}
+}
+
+func TestReadChangeFeedChunk(t *testing.T) {
+	records := []map[string]interface{}{
+		testChangeFeedRecord("1", "BlobCreated", "/blobServices/default/containers/test/blobs/a.txt"),
+		testChangeFeedRecord("2", "BlobCreated", "/blobServices/default/containers/other/blobs/b.txt"),
+		testChangeFeedRecord("3", "BlobPropertiesUpdated", "/blobServices/default/containers/test/blobs/dir/c.txt"),
+		testChangeFeedRecord("4", "BlobDeleted", "/blobServices/default/containers/test/blobs/a.txt"),
+	}
+
+	t.Run("return the events of the container", func(t *testing.T) {
+		events, position, err := readChangeFeedChunk(testChangeFeedChunk(t, records...), "test", 0, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), position)
+		assert.Len(t, events, 3)
+		assert.Equal(t, changeFeedEvent{
+			ID:        "3",
+			Change:    "updated",
+			EventType: "BlobPropertiesUpdated",
+			EventTime: "2021-08-01T10:00:00Z",
+			BlobName:  "dir/c.txt",
+		}, events[1])
+		assert.Equal(t, "deleted", events[2].Change)
+	})
+
+	t.Run("resume after skipped events and stop at max", func(t *testing.T) {
+		events, position, err := readChangeFeedChunk(testChangeFeedChunk(t, records...), "test", 1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), position)
+		assert.Len(t, events, 1)
+		assert.Equal(t, "3", events[0].ID)
+	})
+}
+
+func TestParseChangeFeedCursor(t *testing.T) {
+	t.Run("round trip cursor", func(t *testing.T) {
+		c := changeFeedCursor{Segment: "idx/segments/2021/08/01/1000/meta.json", Chunk: "log/00/chunk.avro", Events: 5}
+		parsed, err := parseChangeFeedCursor(c.String())
+		assert.Nil(t, err)
+		assert.Equal(t, c, parsed)
+	})
+
+	t.Run("return error for invalid cursor", func(t *testing.T) {
+		_, err := parseChangeFeedCursor("not a cursor")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,52 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Defines if Init validates the container name against the naming rules, defaults to true
+const metadataKeyValidateContainerName = "validateContainerName"
+
+// Containers with reserved names, which don't follow the naming rules
+var reservedContainerNames = map[string]bool{
+	"$root": true,
+	"$web":  true,
+	"$logs": true,
+}
+
+// validateContainerName checks name against the naming rules of containers, so that an invalid name
+// fails in Init rather than on the first request.
+// See: https://docs.microsoft.com/en-us/rest/api/storageservices/naming-and-referencing-containers--blobs--and-metadata#container-names
+func validateContainerName(name string) error {
+	if reservedContainerNames[name] {
+		return nil
+	}
+
+	var rule string
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		rule = "must be between 3 and 63 characters long"
+	case strings.IndexFunc(name, func(r rune) bool { return !isLowerAlphanumeric(r) && r != '-' }) >= 0:
+		rule = "can only contain lowercase letters, numbers and hyphens"
+	case name[0] == '-':
+		rule = "must start with a letter or a number"
+	case name[len(name)-1] == '-':
+		rule = "must end with a letter or a number"
+	case strings.Contains(name, "--"):
+		rule = "can't contain consecutive hyphens"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("invalid container name %q: container names %s, disable %s to skip this check", name, rule, metadataKeyValidateContainerName)
+}
+
+func isLowerAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
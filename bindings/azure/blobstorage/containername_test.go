@@ -0,0 +1,47 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContainerName(t *testing.T) {
+	for _, name := range []string{"abc", "my-container-1", "000", "$root", "$web", "$logs"} {
+		assert.Nil(t, validateContainerName(name), name)
+	}
+
+	for name, rule := range map[string]string{
+		"ab": "between 3 and 63 characters",
+		"a234567890123456789012345678901234567890123456789012345678901234": "between 3 and 63 characters",
+		"MyContainer":   "lowercase letters, numbers and hyphens",
+		"my_container":  "lowercase letters, numbers and hyphens",
+		"-container":    "start with a letter or a number",
+		"container-":    "end with a letter or a number",
+		"my--container": "consecutive hyphens",
+		"$other":        "lowercase letters, numbers and hyphens",
+	} {
+		err := validateContainerName(name)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), rule, name)
+		}
+	}
+}
+
+func TestParseMetadataContainerName(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	_, err := blobStorage.parseMetadata(bindings.Metadata{Properties: map[string]string{"container": "My_Container"}})
+	assert.Error(t, err)
+
+	m, err := blobStorage.parseMetadata(bindings.Metadata{Properties: map[string]string{"container": "My_Container", "validateContainerName": "false"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "My_Container", m.Container)
+}
@@ -0,0 +1,130 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	copyOperation bindings.OperationKind = "copy"
+
+	// Name of the blob to copy, in the container of the binding unless sourceContainer is set
+	metadataKeySourceBlobName  = "sourceBlobName"
+	metadataKeySourceContainer = "sourceContainer"
+	// URL of the blob to copy, e.g. with a SAS token for blobs of other storage accounts. Takes
+	// precedence over sourceBlobName.
+	metadataKeySourceURL = "sourceURL"
+
+	// Interval between the checks of the status of a pending copy
+	copyPollInterval = time.Second
+)
+
+// copySourceURL returns the URL of the source blob of a copy request.
+func (a *AzureBlobStorage) copySourceURL(metadata map[string]string) (url.URL, error) {
+	if val, ok := metadata[metadataKeySourceURL]; ok && val != "" {
+		u, err := url.Parse(val)
+		if err != nil || !u.IsAbs() {
+			return url.URL{}, fmt.Errorf("invalid %s %q, expected an absolute URL", metadataKeySourceURL, val)
+		}
+
+		return *u, nil
+	}
+
+	name := metadata[metadataKeySourceBlobName]
+	if name == "" {
+		return url.URL{}, fmt.Errorf("%s or %s is required to copy a blob", metadataKeySourceBlobName, metadataKeySourceURL)
+	}
+	if err := a.validateNames(name); err != nil {
+		return url.URL{}, err
+	}
+
+	containerURL := a.containerURL
+	if container := metadata[metadataKeySourceContainer]; container != "" {
+		// The container is the last segment of the path, which also holds the account name on
+		// custom endpoints
+		u := a.containerURL.URL()
+		u.Path = path.Join(path.Dir(u.Path), container)
+		containerURL = azblob.NewContainerURL(u, a.pipeline)
+	}
+
+	return containerURL.NewBlobURL(name).URL(), nil
+}
+
+// copy starts a server-side copy of the source blob to blobName and waits for it to complete.
+// Copies within a storage account are usually completed synchronously, copies from other accounts
+// are polled until the service reports their final status.
+func (a *AzureBlobStorage) copy(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobURL azblob.BlockBlobURL
+	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
+		blobURL = a.getBlobURL(val)
+	} else {
+		return nil, ErrMissingBlobName
+	}
+
+	source, err := a.copySourceURL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	resp, err := blobURL.StartCopyFromURL(ctx, source, nil, azblob.ModifiedAccessConditions{}, azblob.BlobAccessConditions{})
+	if err != nil {
+		if isNotFoundError(err) || isCannotVerifyCopySourceError(err) {
+			return nil, fmt.Errorf("error copying from %s: %w", source.Path, ErrBlobNotFound)
+		}
+
+		return nil, fmt.Errorf("error starting copy of az blob: %w", err)
+	}
+
+	status := resp.CopyStatus()
+	description := ""
+	for status == azblob.CopyStatusPending {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("error waiting for copy %s of az blob: %w", resp.CopyID(), ctx.Err())
+		case <-time.After(copyPollInterval):
+		}
+
+		props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{})
+		if err != nil {
+			return nil, fmt.Errorf("error reading status of copy %s of az blob: %w", resp.CopyID(), err)
+		}
+		status = props.CopyStatus()
+		description = props.CopyStatusDescription()
+	}
+	if status != azblob.CopyStatusSuccess {
+		return nil, fmt.Errorf("copy %s of az blob ended with status %s: %s", resp.CopyID(), status, description)
+	}
+
+	b, err := json.Marshal(createResponse{
+		BlobURL: blobURL.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling copy response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// isCannotVerifyCopySourceError returns true when the source of a copy can't be read, which the
+// service also reports for missing source blobs.
+func isCannotVerifyCopySourceError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+
+	return ok && azureError.ServiceCode() == azblob.ServiceCodeCannotVerifyCopySource
+}
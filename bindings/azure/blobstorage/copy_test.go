@@ -0,0 +1,86 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopySourceURL(t *testing.T) {
+	m := bindings.Metadata{Properties: map[string]string{
+		"storageAccount":   "devstoreaccount1",
+		"storageAccessKey": "a2V5",
+		"container":        "test",
+		"createContainer":  "false",
+	}}
+
+	t.Run("return error if the source is missing", func(t *testing.T) {
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(m))
+		_, err := blobStorage.copySourceURL(map[string]string{})
+		assert.Error(t, err)
+	})
+
+	t.Run("use the container of the binding by default", func(t *testing.T) {
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(m))
+		u, err := blobStorage.copySourceURL(map[string]string{"sourceBlobName": "a/b.txt"})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://devstoreaccount1.blob.core.windows.net/test/a/b.txt", u.String())
+	})
+
+	t.Run("use the source container", func(t *testing.T) {
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(m))
+		u, err := blobStorage.copySourceURL(map[string]string{"sourceBlobName": "b.txt", "sourceContainer": "other"})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://devstoreaccount1.blob.core.windows.net/other/b.txt", u.String())
+	})
+
+	t.Run("keep the account of custom endpoints", func(t *testing.T) {
+		props := map[string]string{"endpoint": "http://127.0.0.1:10000"}
+		for k, v := range m.Properties {
+			props[k] = v
+		}
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(bindings.Metadata{Properties: props}))
+		u, err := blobStorage.copySourceURL(map[string]string{"sourceBlobName": "b.txt", "sourceContainer": "other"})
+		assert.Nil(t, err)
+		assert.Equal(t, "http://127.0.0.1:10000/devstoreaccount1/other/b.txt", u.String())
+	})
+
+	t.Run("use the source URL", func(t *testing.T) {
+		blobStorage := newOfflineBlobStorage()
+		assert.Nil(t, blobStorage.Init(m))
+		u, err := blobStorage.copySourceURL(map[string]string{
+			"sourceBlobName": "ignored",
+			"sourceURL":      "https://other.blob.core.windows.net/c/b.txt?sv=2019-12-12&sig=abc",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://other.blob.core.windows.net/c/b.txt?sv=2019-12-12&sig=abc", u.String())
+	})
+
+	t.Run("return error for relative source URL", func(t *testing.T) {
+		blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+		_, err := blobStorage.copySourceURL(map[string]string{"sourceURL": "c/b.txt"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCopyOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		r := bindings.InvokeRequest{Metadata: map[string]string{"sourceBlobName": "foo"}}
+		_, err := blobStorage.copy(context.Background(), &r)
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+}
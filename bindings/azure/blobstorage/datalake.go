@@ -0,0 +1,298 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// Directory operations for storage accounts with hierarchical namespace (ADLS Gen2) enabled. They
+// are sent to the Data Lake Storage (dfs) endpoint of the account through the same pipeline as the
+// blob operations, so they use the same credentials.
+// See: https://docs.microsoft.com/en-us/rest/api/storageservices/data-lake-storage-gen2
+
+const (
+	createDirectoryOperation bindings.OperationKind = "createDirectory"
+	deleteDirectoryOperation bindings.OperationKind = "deleteDirectory"
+	renameDirectoryOperation bindings.OperationKind = "renameDirectory"
+	listDirectoryOperation   bindings.OperationKind = "listDirectory"
+
+	// Path of the directory relative to the container
+	metadataKeyDirectoryName = "directoryName"
+	// Destination path of the renameDirectory operation, relative to the container
+	metadataKeyDestinationDirectoryName = "destinationDirectoryName"
+	// Defines if listDirectory returns the paths of all subdirectories too
+	metadataKeyRecursive = "recursive"
+
+	dataLakeServiceVersion = "2019-12-12"
+)
+
+var ErrHierarchicalNamespaceRequired = errors.New("the operation requires a storage account with hierarchical namespace enabled")
+
+type directoryPath struct {
+	Name          string `json:"name"`
+	IsDirectory   bool   `json:"isDirectory"`
+	ContentLength int64  `json:"contentLength"`
+	LastModified  string `json:"lastModified"`
+	ETag          string `json:"etag"`
+}
+
+// The service encodes booleans and numbers of the path listing as strings.
+type dataLakePath struct {
+	Name          string `json:"name"`
+	IsDirectory   string `json:"isDirectory"`
+	ContentLength string `json:"contentLength"`
+	LastModified  string `json:"lastModified"`
+	ETag          string `json:"etag"`
+}
+
+type dataLakePathList struct {
+	Paths []dataLakePath `json:"paths"`
+}
+
+// isHierarchicalNamespaceEnabled reports if the account has HNS enabled. The account information is
+// requested on the first directory operation and cached once it was read successfully.
+func (a *AzureBlobStorage) isHierarchicalNamespaceEnabled(ctx context.Context) (bool, error) {
+	a.hnsLock.Lock()
+	defer a.hnsLock.Unlock()
+
+	if a.hnsEnabled != nil {
+		return *a.hnsEnabled, nil
+	}
+
+	serviceURL := a.containerURL.URL()
+	serviceURL.Path = ""
+	resp, err := azblob.NewServiceURL(serviceURL, a.pipeline).GetAccountInfo(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error reading storage account information: %w", err)
+	}
+	enabled, _ := strconv.ParseBool(resp.Response().Header.Get("x-ms-is-hns-enabled"))
+	a.hnsEnabled = &enabled
+
+	return enabled, nil
+}
+
+// dataLakeURL returns the dfs endpoint URL for a path in the container. It returns an error if the
+// dfs endpoint isn't set and can't be derived from the blob endpoint.
+func (a *AzureBlobStorage) dataLakeURL(pathName string, query url.Values) (url.URL, error) {
+	u := a.containerURL.URL()
+	switch {
+	case a.metadata.DfsEndpoint != "":
+		rawURL := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(a.metadata.DfsEndpoint, "/"), a.metadata.StorageAccount, a.metadata.Container)
+		dfsURL, err := url.Parse(rawURL)
+		if err != nil {
+			return u, fmt.Errorf("invalid Data Lake Storage URL %q: %w", rawURL, err)
+		}
+		u = *dfsURL
+	case strings.Contains(u.Host, ".blob."):
+		u.Host = strings.Replace(u.Host, ".blob.", ".dfs.", 1)
+	default:
+		return u, fmt.Errorf("the Data Lake Storage endpoint can't be derived from the blob endpoint %s, set dfsEndpoint", u.Host)
+	}
+	if pathName != "" {
+		u.Path = path.Join(u.Path, pathName)
+	}
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// doDataLakeRequest sends a request to the dfs endpoint, following continuation tokens for
+// operations that the service may split in several calls.
+func (a *AzureBlobStorage) doDataLakeRequest(ctx context.Context, method string, pathName string, query url.Values, headers map[string]string, handle func(*http.Response) error) error {
+	continuation := ""
+	for {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		if continuation != "" {
+			q.Set("continuation", continuation)
+		}
+
+		u, err := a.dataLakeURL(pathName, q)
+		if err != nil {
+			return err
+		}
+		req, err := pipeline.NewRequest(method, u, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("x-ms-version", dataLakeServiceVersion)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := a.pipeline.Do(ctx, nil, req)
+		if err != nil {
+			return err
+		}
+		httpResp := resp.Response()
+		if httpResp.StatusCode >= http.StatusBadRequest {
+			body, _ := ioutil.ReadAll(httpResp.Body)
+			httpResp.Body.Close()
+
+			return fmt.Errorf("data lake request failed with status %d (%s): %s", httpResp.StatusCode,
+				httpResp.Header.Get("x-ms-error-code"), strings.TrimSpace(string(body)))
+		}
+
+		if handle != nil {
+			err = handle(httpResp)
+		}
+		httpResp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		continuation = httpResp.Header.Get("x-ms-continuation")
+		if continuation == "" {
+			return nil
+		}
+	}
+}
+
+func (a *AzureBlobStorage) requireDirectoryName(ctx context.Context, req *bindings.InvokeRequest) (string, error) {
+	name, ok := req.Metadata[metadataKeyDirectoryName]
+	if !ok || name == "" {
+		return "", fmt.Errorf("%s is a required attribute", metadataKeyDirectoryName)
+	}
+
+	hnsEnabled, err := a.isHierarchicalNamespaceEnabled(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !hnsEnabled {
+		return "", ErrHierarchicalNamespaceRequired
+	}
+
+	return name, nil
+}
+
+func (a *AzureBlobStorage) createDirectory(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+
+	name, err := a.requireDirectoryName(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.doDataLakeRequest(ctx, http.MethodPut, name, url.Values{"resource": {"directory"}}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %w", name, err)
+	}
+
+	return nil, nil
+}
+
+func (a *AzureBlobStorage) deleteDirectory(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+
+	name, err := a.requireDirectoryName(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.doDataLakeRequest(ctx, http.MethodDelete, name, url.Values{"recursive": {"true"}}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting directory %s: %w", name, err)
+	}
+
+	return nil, nil
+}
+
+// renameDirectory moves a directory and everything below it. On HNS accounts the rename is atomic.
+func (a *AzureBlobStorage) renameDirectory(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+
+	name, err := a.requireDirectoryName(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	destination, ok := req.Metadata[metadataKeyDestinationDirectoryName]
+	if !ok || destination == "" {
+		return nil, fmt.Errorf("%s is a required attribute", metadataKeyDestinationDirectoryName)
+	}
+
+	source := path.Join("/", a.metadata.Container, name)
+	headers := map[string]string{"x-ms-rename-source": (&url.URL{Path: source}).EscapedPath()}
+	err = a.doDataLakeRequest(ctx, http.MethodPut, destination, nil, headers, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error renaming directory %s to %s: %w", name, destination, err)
+	}
+
+	return nil, nil
+}
+
+func (a *AzureBlobStorage) listDirectory(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+
+	name, err := a.requireDirectoryName(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	recursive, err := req.GetMetadataAsBool(metadataKeyRecursive)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"resource":  {"filesystem"},
+		"directory": {name},
+		"recursive": {strconv.FormatBool(recursive)},
+	}
+	paths := []directoryPath{}
+	err = a.doDataLakeRequest(ctx, http.MethodGet, "", query, nil, func(resp *http.Response) error {
+		var list dataLakePathList
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			return fmt.Errorf("error parsing directory listing: %w", err)
+		}
+		for _, p := range list.Paths {
+			isDirectory, _ := strconv.ParseBool(p.IsDirectory)
+			contentLength, _ := strconv.ParseInt(p.ContentLength, 10, 64)
+			paths = append(paths, directoryPath{
+				Name:          p.Name,
+				IsDirectory:   isDirectory,
+				ContentLength: contentLength,
+				LastModified:  p.LastModified,
+				ETag:          p.ETag,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing directory %s: %w", name, err)
+	}
+
+	b, err := json.Marshal(paths)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal directory paths to json: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+		Metadata: map[string]string{
+			metadataKeyNumber: strconv.Itoa(len(paths)),
+		},
+	}, nil
+}
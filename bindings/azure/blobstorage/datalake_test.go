@@ -0,0 +1,77 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataLakeURL(t *testing.T) {
+	newBlobStorage := func(containerURL string, m *blobStorageMetadata) *AzureBlobStorage {
+		blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+		u, _ := url.Parse(containerURL)
+		blobStorage.containerURL = azblob.NewContainerURL(*u, azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{}))
+		blobStorage.metadata = m
+
+		return blobStorage
+	}
+
+	t.Run("derive the dfs endpoint from the blob endpoint", func(t *testing.T) {
+		blobStorage := newBlobStorage("https://account.blob.core.windows.net/container", &blobStorageMetadata{})
+		dfsURL, err := blobStorage.dataLakeURL("dir/sub", url.Values{"resource": {"directory"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://account.dfs.core.windows.net/container/dir/sub?resource=directory", dfsURL.String())
+	})
+
+	t.Run("use dfsEndpoint with a custom endpoint", func(t *testing.T) {
+		blobStorage := newBlobStorage("http://127.0.0.1:10000/devstoreaccount1/container", &blobStorageMetadata{
+			StorageAccount: "devstoreaccount1",
+			Container:      "container",
+			Endpoint:       "http://127.0.0.1:10000",
+			DfsEndpoint:    "http://127.0.0.1:10004/",
+		})
+		dfsURL, err := blobStorage.dataLakeURL("dir", url.Values{"resource": {"directory"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "http://127.0.0.1:10004/devstoreaccount1/container/dir?resource=directory", dfsURL.String())
+	})
+
+	t.Run("return error if the dfs endpoint can't be derived", func(t *testing.T) {
+		blobStorage := newBlobStorage("http://127.0.0.1:10000/devstoreaccount1/container", &blobStorageMetadata{
+			StorageAccount: "devstoreaccount1",
+			Container:      "container",
+			Endpoint:       "http://127.0.0.1:10000",
+		})
+		_, err := blobStorage.dataLakeURL("dir", nil)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "dfsEndpoint")
+	})
+}
+
+func TestDirectoryOperations(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{}
+	hnsEnabled := false
+	blobStorage.hnsEnabled = &hnsEnabled
+
+	t.Run("return error if directoryName is missing", func(t *testing.T) {
+		r := bindings.InvokeRequest{}
+		_, err := blobStorage.createDirectory(context.Background(), &r)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for accounts without hierarchical namespace", func(t *testing.T) {
+		r := bindings.InvokeRequest{Metadata: map[string]string{"directoryName": "dir"}}
+		_, err := blobStorage.deleteDirectory(context.Background(), &r)
+		assert.Equal(t, ErrHierarchicalNamespaceRequired, err)
+	})
+}
@@ -0,0 +1,124 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"crypto/sha256"
+	b64 "encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// Per-request server-side encryption. The SDK version used by the binding doesn't expose the
+// customer-provided key and encryption scope options on its high-level APIs, so the headers are set
+// by a pipeline policy from a value carried in the request context.
+// See: https://docs.microsoft.com/en-us/azure/storage/blobs/encryption-customer-provided-keys
+
+const (
+	// Base64 encoded AES-256 key used to encrypt and decrypt the blob (customer-provided key)
+	metadataKeyEncryptionKey = "encryptionKey"
+	// Base64 encoded SHA-256 digest of encryptionKey, checked against the key before the request is sent
+	metadataKeyEncryptionKeySHA256 = "encryptionKeySha256"
+	// Algorithm of encryptionKey, only AES256 is supported by the service
+	metadataKeyEncryptionAlgorithm = "encryptionAlgorithm"
+	// Name of the encryption scope used to encrypt the blob on create
+	metadataKeyEncryptionScope = "encryptionScope"
+
+	encryptionAlgorithmAES256 = "AES256"
+
+	serviceCodeBlobUsesCustomerSpecifiedEncryption azblob.ServiceCodeType = "BlobUsesCustomerSpecifiedEncryption"
+)
+
+var ErrEncryptionKeyRequired = errors.New("the blob is encrypted with a customer-provided key, the encryptionKey attribute is required")
+
+type requestEncryption struct {
+	key       string
+	keySHA256 string
+	scope     string
+}
+
+type requestEncryptionContextKey struct{}
+
+// parseRequestEncryption reads the encryption options from the request metadata and removes them, so
+// they are never stored as blob metadata.
+func parseRequestEncryption(metadata map[string]string) (*requestEncryption, error) {
+	key := metadata[metadataKeyEncryptionKey]
+	keySHA256 := metadata[metadataKeyEncryptionKeySHA256]
+	algorithm := metadata[metadataKeyEncryptionAlgorithm]
+	scope := metadata[metadataKeyEncryptionScope]
+	delete(metadata, metadataKeyEncryptionKey)
+	delete(metadata, metadataKeyEncryptionKeySHA256)
+	delete(metadata, metadataKeyEncryptionAlgorithm)
+	delete(metadata, metadataKeyEncryptionScope)
+
+	if key == "" && (keySHA256 != "" || algorithm != "") {
+		return nil, fmt.Errorf("%s and %s require %s", metadataKeyEncryptionKeySHA256, metadataKeyEncryptionAlgorithm, metadataKeyEncryptionKey)
+	}
+	if key == "" && scope == "" {
+		return nil, nil
+	}
+	if key != "" && scope != "" {
+		return nil, fmt.Errorf("%s and %s can't be used together", metadataKeyEncryptionKey, metadataKeyEncryptionScope)
+	}
+	if algorithm != "" && algorithm != encryptionAlgorithmAES256 {
+		return nil, fmt.Errorf("invalid %s %q, only %s is supported", metadataKeyEncryptionAlgorithm, algorithm, encryptionAlgorithmAES256)
+	}
+
+	enc := &requestEncryption{scope: scope}
+	if key != "" {
+		rawKey, err := b64.StdEncoding.DecodeString(key)
+		if err != nil || len(rawKey) != 32 {
+			return nil, fmt.Errorf("the %s value is invalid, it must be a base64 encoded 256 bits key", metadataKeyEncryptionKey)
+		}
+		sum := sha256.Sum256(rawKey)
+		enc.key = key
+		enc.keySHA256 = b64.StdEncoding.EncodeToString(sum[:])
+		if keySHA256 != "" && keySHA256 != enc.keySHA256 {
+			return nil, fmt.Errorf("the %s value doesn't match the SHA-256 digest of %s", metadataKeyEncryptionKeySHA256, metadataKeyEncryptionKey)
+		}
+	}
+
+	return enc, nil
+}
+
+func withRequestEncryption(ctx context.Context, enc *requestEncryption) context.Context {
+	if enc == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, requestEncryptionContextKey{}, enc)
+}
+
+// newRequestEncryptionPolicyFactory sets the encryption headers of the requests whose context carries
+// a requestEncryption.
+func newRequestEncryptionPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			if enc, ok := ctx.Value(requestEncryptionContextKey{}).(*requestEncryption); ok {
+				if enc.key != "" {
+					request.Header.Set("x-ms-encryption-key", enc.key)
+					request.Header.Set("x-ms-encryption-key-sha256", enc.keySHA256)
+					request.Header.Set("x-ms-encryption-algorithm", encryptionAlgorithmAES256)
+				}
+				if enc.scope != "" {
+					request.Header.Set("x-ms-encryption-scope", enc.scope)
+				}
+			}
+
+			return next.Do(ctx, request)
+		}
+	})
+}
+
+func isEncryptionKeyRequiredError(err error) bool {
+	azureError, ok := err.(azblob.StorageError)
+
+	return ok && azureError.ServiceCode() == serviceCodeBlobUsesCustomerSpecifiedEncryption
+}
@@ -0,0 +1,102 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequestEncryption(t *testing.T) {
+	key := b64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	t.Run("return nil without encryption options", func(t *testing.T) {
+		enc, err := parseRequestEncryption(map[string]string{"foo": "bar"})
+		assert.Nil(t, err)
+		assert.Nil(t, enc)
+	})
+
+	t.Run("parse customer-provided key and strip it from metadata", func(t *testing.T) {
+		metadata := map[string]string{"encryptionKey": key}
+		enc, err := parseRequestEncryption(metadata)
+		assert.Nil(t, err)
+		assert.Equal(t, key, enc.key)
+		assert.Equal(t, "Zmh6rfhivXdsj8GLjp+OIAiXFIVu4jOzkCpZHQ1fKSU=", enc.keySHA256)
+		assert.Empty(t, metadata)
+	})
+
+	t.Run("parse encryption scope", func(t *testing.T) {
+		enc, err := parseRequestEncryption(map[string]string{"encryptionScope": "tenant1"})
+		assert.Nil(t, err)
+		assert.Equal(t, "tenant1", enc.scope)
+	})
+
+	t.Run("return error for invalid key", func(t *testing.T) {
+		_, err := parseRequestEncryption(map[string]string{"encryptionKey": "c2hvcnQ="})
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for key and scope", func(t *testing.T) {
+		_, err := parseRequestEncryption(map[string]string{"encryptionKey": key, "encryptionScope": "tenant1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("check the digest and algorithm of the key", func(t *testing.T) {
+		metadata := map[string]string{
+			"encryptionKey":       key,
+			"encryptionKeySha256": "Zmh6rfhivXdsj8GLjp+OIAiXFIVu4jOzkCpZHQ1fKSU=",
+			"encryptionAlgorithm": "AES256",
+		}
+		enc, err := parseRequestEncryption(metadata)
+		assert.Nil(t, err)
+		assert.Equal(t, key, enc.key)
+		assert.Empty(t, metadata)
+
+		for _, metadata := range []map[string]string{
+			{"encryptionKey": key, "encryptionKeySha256": "c2hvcnQ="},
+			{"encryptionKey": key, "encryptionAlgorithm": "DES"},
+			{"encryptionKeySha256": "Zmh6rfhivXdsj8GLjp+OIAiXFIVu4jOzkCpZHQ1fKSU="},
+			{"encryptionAlgorithm": "AES256"},
+		} {
+			_, err = parseRequestEncryption(metadata)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestRequestEncryptionPolicy(t *testing.T) {
+	var headers http.Header
+	sender := pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		headers = request.Header
+
+		return pipeline.NewHTTPResponse(&http.Response{StatusCode: http.StatusOK}), nil
+	})
+	policy := newRequestEncryptionPolicyFactory().New(sender, nil)
+	u, _ := url.Parse("https://account.blob.core.windows.net/container/blob")
+
+	t.Run("set customer-provided key headers", func(t *testing.T) {
+		req, _ := pipeline.NewRequest(http.MethodGet, *u, nil)
+		ctx := withRequestEncryption(context.Background(), &requestEncryption{key: "key", keySHA256: "sha"})
+		_, err := policy.Do(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, "key", headers.Get("x-ms-encryption-key"))
+		assert.Equal(t, "sha", headers.Get("x-ms-encryption-key-sha256"))
+		assert.Equal(t, "AES256", headers.Get("x-ms-encryption-algorithm"))
+	})
+
+	t.Run("leave requests without encryption untouched", func(t *testing.T) {
+		req, _ := pipeline.NewRequest(http.MethodGet, *u, nil)
+		_, err := policy.Do(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Empty(t, headers.Get("x-ms-encryption-key"))
+	})
+}
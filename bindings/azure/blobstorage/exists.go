@@ -0,0 +1,56 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const existsOperation bindings.OperationKind = "exists"
+
+type existsResponse struct {
+	Exists bool `json:"exists"`
+}
+
+// exists reports if the blob exists. A missing blob is not an error, unlike the other errors of
+// the service, e.g. for a missing container or invalid credentials.
+func (a *AzureBlobStorage) exists(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobURL azblob.BlockBlobURL
+	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
+		blobURL = a.getBlobURL(val)
+	} else {
+		return nil, ErrMissingBlobName
+	}
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	found := true
+	_, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{})
+	if err != nil {
+		switch {
+		case isNotFoundResponse(err, azblob.ServiceCodeBlobNotFound):
+			found = false
+		case isEncryptionKeyRequiredError(err):
+			// The properties of a blob encrypted with a customer provided key can only be read with the key
+		default:
+			return nil, fmt.Errorf("error reading az blob properties: %w", err)
+		}
+	}
+
+	b, err := json.Marshal(existsResponse{Exists: found})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling exists response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
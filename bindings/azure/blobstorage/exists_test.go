@@ -0,0 +1,82 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExists(t *testing.T) {
+	exists := func(t *testing.T, handler http.HandlerFunc) (bool, error) {
+		blobStorage := newTestBlobStorage(t, handler)
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: existsOperation,
+			Metadata:  map[string]string{"blobName": "foo"},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		var result existsResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &result))
+
+		return result.Exists, nil
+	}
+
+	t.Run("return true for an existing blob", func(t *testing.T) {
+		found, err := exists(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodHead, r.Method)
+			w.WriteHeader(http.StatusOK)
+		})
+		assert.Nil(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("return false for a missing blob", func(t *testing.T) {
+		found, err := exists(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+		})
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		found, err = exists(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("return true for a blob encrypted with a customer provided key", func(t *testing.T) {
+		found, err := exists(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-ms-error-code", "BlobUsesCustomerSpecifiedEncryption")
+			w.WriteHeader(http.StatusConflict)
+		})
+		assert.Nil(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("return error for a missing container or invalid credentials", func(t *testing.T) {
+		for code, status := range map[string]int{"ContainerNotFound": http.StatusNotFound, "AuthenticationFailed": http.StatusForbidden} {
+			_, err := exists(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("x-ms-error-code", code)
+				w.WriteHeader(status)
+			})
+			assert.Error(t, err, code)
+		}
+	})
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		blobStorage := newTestBlobStorage(t, http.NotFoundHandler())
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{Operation: existsOperation})
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+}
@@ -0,0 +1,144 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// listHierarchyResult is the response of list with a delimiter. Prefixes are the virtual directories
+// directly under the prefix of the listing, ending with the delimiter, and Blobs the blobs directly
+// under it. Both count in the number of items of the listing. With maxDepth, Prefixes are the
+// virtual directories that were not expanded, and Blobs the blobs of all the expanded levels.
+type listHierarchyResult struct {
+	Prefixes []string        `json:"prefixes"`
+	Blobs    []listBlobEntry `json:"blobs"`
+}
+
+// listHierarchy lists the blobs and the virtual directories separated by delimiter, e.g. to browse
+// the blobs as folders with "/". The virtual directories are expanded down to maxDepth levels
+// under the prefix of the listing, see expandPrefixes.
+func (a *AzureBlobStorage) listHierarchy(ctx context.Context, marker azblob.Marker, delimiter string, options azblob.ListBlobsSegmentOptions, singleSegment bool, maxDepth int) (*bindings.InvokeResponse, error) {
+	var prefixes []string
+	var blobs []azblob.BlobItem
+	metadata, err := listSegments(marker, options.MaxResults, singleSegment, a.listHierarchySegment(ctx, delimiter, options, &prefixes, &blobs))
+	if err != nil {
+		return nil, err
+	}
+
+	if maxDepth > 1 {
+		listed, _ := strconv.ParseInt(metadata[metadataKeyNumber], 10, 32)
+		var expanded []azblob.BlobItem
+		var n int32
+		prefixes, expanded, n, err = a.expandPrefixes(ctx, delimiter, options, prefixes, maxDepth, options.MaxResults-int32(listed))
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, expanded...)
+		metadata[metadataKeyNumber] = strconv.FormatInt(listed+int64(n), 10)
+	}
+
+	result := listHierarchyResult{
+		Prefixes: append([]string{}, prefixes...),
+		Blobs:    []listBlobEntry{},
+	}
+	a.restoreListMetadataCase(blobs)
+	for _, blob := range blobs {
+		result.Blobs = append(result.Blobs, newListBlobEntry(blob))
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal blobs to json: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     b,
+		Metadata: metadata,
+	}, nil
+}
+
+// listHierarchySegment returns the function listing the segments of listSegments with delimiter,
+// which appends the virtual directories and the blobs of each segment to prefixes and blobs.
+func (a *AzureBlobStorage) listHierarchySegment(ctx context.Context, delimiter string, options azblob.ListBlobsSegmentOptions, prefixes *[]string, blobs *[]azblob.BlobItem) func(marker azblob.Marker, n int32) (int, azblob.Marker, error) {
+	return func(marker azblob.Marker, n int32) (int, azblob.Marker, error) {
+		options.MaxResults = n
+		listBlob, err := a.containerURL.ListBlobsHierarchySegment(ctx, marker, delimiter, options)
+		if err != nil {
+			return 0, azblob.Marker{}, fmt.Errorf("error listing blobs: %w", err)
+		}
+		for _, prefix := range listBlob.Segment.BlobPrefixes {
+			*prefixes = append(*prefixes, prefix.Name)
+		}
+		*blobs = append(*blobs, listBlob.Segment.BlobItems...)
+
+		return len(listBlob.Segment.BlobPrefixes) + len(listBlob.Segment.BlobItems), listBlob.NextMarker, nil
+	}
+}
+
+// expandPrefixes lists the content of the virtual directories breadth first, until they are
+// maxDepth levels under the prefix of the listing, prefixes being the first level. At most budget
+// items are listed, including the expanded virtual directories. It returns the virtual directories
+// that were not expanded, either at maxDepth or beyond the budget, the blobs of the expanded ones,
+// and the number of items returned. A virtual directory that doesn't fit in the rest of the budget
+// is kept collapsed, and the following ones aren't listed.
+func (a *AzureBlobStorage) expandPrefixes(ctx context.Context, delimiter string, options azblob.ListBlobsSegmentOptions, prefixes []string, maxDepth int, budget int32) ([]string, []azblob.BlobItem, int32, error) {
+	var collapsed []string
+	var blobs []azblob.BlobItem
+	var listed int32
+	level := prefixes
+	for depth := 1; depth < maxDepth && len(level) > 0; depth++ {
+		var next []string
+		for i, prefix := range level {
+			if budget <= 0 {
+				collapsed = append(collapsed, level[i:]...)
+
+				break
+			}
+
+			var subPrefixes []string
+			var subBlobs []azblob.BlobItem
+			options.Prefix = prefix
+			metadata, err := listSegments(azblob.Marker{}, budget, false, a.listHierarchySegment(ctx, delimiter, options, &subPrefixes, &subBlobs))
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if metadata[metadataKeyMarker] != "" {
+				collapsed = append(collapsed, level[i:]...)
+				budget = 0
+
+				break
+			}
+
+			n := int32(len(subPrefixes) + len(subBlobs))
+			budget -= n
+			// The expanded virtual directory no longer counts as an item
+			listed += n - 1
+			next = append(next, subPrefixes...)
+			blobs = append(blobs, subBlobs...)
+		}
+		level = next
+		if budget <= 0 {
+			break
+		}
+	}
+
+	// Sorted like the results of a single level
+	collapsed = append(collapsed, level...)
+	sort.Strings(collapsed)
+	sort.SliceStable(blobs, func(i, j int) bool {
+		return blobs[i].Name < blobs[j].Name
+	})
+
+	return collapsed, blobs, listed, nil
+}
@@ -0,0 +1,189 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListHierarchy(t *testing.T) {
+	var delimiter, prefix string
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delimiter, prefix = r.URL.Query().Get("delimiter"), r.URL.Query().Get("prefix")
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<EnumerationResults><Blobs>` +
+			`<BlobPrefix><Name>photos/2021/</Name></BlobPrefix>` +
+			`<BlobPrefix><Name>photos/2022/</Name></BlobPrefix>` +
+			`<Blob><Name>photos/cover.jpg</Name><Properties><Content-Length>3</Content-Length></Properties></Blob>` +
+			`</Blobs><NextMarker /></EnumerationResults>`))
+	}))
+
+	t.Run("return the prefixes and the blobs", func(t *testing.T) {
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.ListOperation,
+			Data:      []byte(`{"prefix": "photos/", "delimiter": "/"}`),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "/", delimiter)
+		assert.Equal(t, "photos/", prefix)
+
+		var result listHierarchyResult
+		assert.Nil(t, json.Unmarshal(resp.Data, &result))
+		assert.Equal(t, []string{"photos/2021/", "photos/2022/"}, result.Prefixes)
+		if assert.Len(t, result.Blobs, 1) {
+			assert.Equal(t, "photos/cover.jpg", result.Blobs[0].Name)
+			assert.Equal(t, int64(3), result.Blobs[0].Size)
+		}
+		assert.Equal(t, "3", resp.Metadata["number"])
+		assert.Equal(t, "", resp.Metadata["marker"])
+	})
+
+	t.Run("return error with groupByTier", func(t *testing.T) {
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.ListOperation,
+			Data:      []byte(`{"delimiter": "/", "groupByTier": true}`),
+		})
+		assert.Error(t, err)
+	})
+}
+
+// fakeHierarchyService lists the blobs and the virtual directories directly under the prefix of the
+// requests in segments. The marker is the index of the next item.
+type fakeHierarchyService struct {
+	blobs []string
+}
+
+func (f *fakeHierarchyService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	seen := map[string]bool{}
+	var items []string
+	for _, name := range f.blobs {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if i := strings.Index(name[len(prefix):], delimiter); i >= 0 {
+			name = name[:len(prefix)+i+len(delimiter)]
+		}
+		if !seen[name] {
+			seen[name] = true
+			items = append(items, name)
+		}
+	}
+	sort.Strings(items)
+
+	start, _ := strconv.Atoi(query.Get("marker"))
+	end, _ := strconv.Atoi(query.Get("maxresults"))
+	end += start
+	if end > len(items) {
+		end = len(items)
+	}
+
+	var body strings.Builder
+	body.WriteString("<EnumerationResults><Blobs>")
+	for _, name := range items[start:end] {
+		if strings.HasSuffix(name, delimiter) {
+			fmt.Fprintf(&body, "<BlobPrefix><Name>%s</Name></BlobPrefix>", name)
+		} else {
+			fmt.Fprintf(&body, "<Blob><Name>%s</Name><Properties></Properties></Blob>", name)
+		}
+	}
+	body.WriteString("</Blobs>")
+	if end < len(items) {
+		fmt.Fprintf(&body, "<NextMarker>%d</NextMarker>", end)
+	} else {
+		body.WriteString("<NextMarker />")
+	}
+	body.WriteString("</EnumerationResults>")
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body.String()))
+}
+
+func TestListHierarchyMaxDepth(t *testing.T) {
+	service := &fakeHierarchyService{blobs: []string{
+		"a.txt",
+		"docs/2021/deep/y.md",
+		"docs/2021/x.md",
+		"docs/readme.md",
+		"photos/p.jpg",
+	}}
+	blobStorage := newTestBlobStorage(t, service)
+	list := func(t *testing.T, payload string) (listHierarchyResult, map[string]string) {
+		var result listHierarchyResult
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: bindings.ListOperation,
+			Data:      []byte(payload),
+		})
+		if !assert.Nil(t, err) {
+			return result, nil
+		}
+		assert.Nil(t, json.Unmarshal(resp.Data, &result))
+
+		return result, resp.Metadata
+	}
+	names := func(blobs []listBlobEntry) []string {
+		result := []string{}
+		for _, blob := range blobs {
+			result = append(result, blob.Name)
+		}
+
+		return result
+	}
+
+	t.Run("list a single level by default", func(t *testing.T) {
+		result, metadata := list(t, `{"delimiter": "/"}`)
+		assert.Equal(t, []string{"docs/", "photos/"}, result.Prefixes)
+		assert.Equal(t, []string{"a.txt"}, names(result.Blobs))
+		assert.Equal(t, "3", metadata["number"])
+	})
+
+	t.Run("expand the levels down to maxDepth", func(t *testing.T) {
+		result, metadata := list(t, `{"delimiter": "/", "maxDepth": 2}`)
+		assert.Equal(t, []string{"docs/2021/"}, result.Prefixes)
+		assert.Equal(t, []string{"a.txt", "docs/readme.md", "photos/p.jpg"}, names(result.Blobs))
+		assert.Equal(t, "4", metadata["number"])
+
+		result, metadata = list(t, `{"delimiter": "/", "maxDepth": 3}`)
+		assert.Equal(t, []string{"docs/2021/deep/"}, result.Prefixes)
+		assert.Equal(t, []string{"a.txt", "docs/2021/x.md", "docs/readme.md", "photos/p.jpg"}, names(result.Blobs))
+		assert.Equal(t, "5", metadata["number"])
+		assert.Equal(t, "", metadata["marker"])
+	})
+
+	t.Run("keep the levels beyond maxResults collapsed", func(t *testing.T) {
+		result, metadata := list(t, `{"delimiter": "/", "maxDepth": 3, "maxResults": 4}`)
+		assert.Equal(t, []string{"docs/", "photos/"}, result.Prefixes)
+		assert.Equal(t, []string{"a.txt"}, names(result.Blobs))
+		assert.Equal(t, "3", metadata["number"])
+
+		result, metadata = list(t, `{"delimiter": "/", "maxDepth": 3, "maxResults": 5}`)
+		assert.Equal(t, []string{"docs/2021/", "photos/"}, result.Prefixes)
+		assert.Equal(t, []string{"a.txt", "docs/readme.md"}, names(result.Blobs))
+		assert.Equal(t, "4", metadata["number"])
+	})
+
+	t.Run("return error for an invalid maxDepth", func(t *testing.T) {
+		for _, payload := range []string{`{"delimiter": "/", "maxDepth": -1}`, `{"maxDepth": 2}`} {
+			_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+				Operation: bindings.ListOperation,
+				Data:      []byte(payload),
+			})
+			assert.Error(t, err, payload)
+		}
+	})
+}
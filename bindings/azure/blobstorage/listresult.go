@@ -0,0 +1,139 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// ListResult is the typed form of the response of the list operation, for Go code that invokes
+// the binding directly. The summaries of groupByTier aren't supported.
+type ListResult struct {
+	Blobs []BlobInfo
+	// Virtual directories of a listing with a delimiter, see listHierarchyResult
+	Prefixes []string
+	// Marker to pass in the next list request, empty once the listing is complete
+	Marker string
+	// Opaque cursor to pass in the next list request instead of Marker, empty once the listing is complete
+	Cursor string
+}
+
+// BlobInfo is a blob of the list operation response. Metadata, Snapshot and Deleted are only set
+// when they are included in the listing.
+type BlobInfo struct {
+	Name       string
+	Deleted    bool
+	Snapshot   string
+	Properties BlobProperties
+	Metadata   map[string]string
+}
+
+// BlobProperties are the properties of a blob of the list operation response. ContentLength and
+// ContentType are nil when the response doesn't have them.
+type BlobProperties struct {
+	LastModified  time.Time
+	Etag          string
+	ContentLength *int64
+	ContentType   *string
+	// Empty for page and append blobs
+	AccessTier string
+}
+
+// listBlobEntry is a blob of the JSON response of the list operation. The SDK items are mapped to it
+// so that the response doesn't change with the SDK.
+type listBlobEntry struct {
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	ContentType  string    `json:"contentType,omitempty"`
+	// Empty for page and append blobs
+	Tier     string            `json:"tier,omitempty"`
+	ETag     string            `json:"etag"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Snapshot string            `json:"snapshot,omitempty"`
+	Deleted  bool              `json:"deleted,omitempty"`
+}
+
+func newListBlobEntry(item azblob.BlobItem) listBlobEntry {
+	entry := listBlobEntry{
+		Name:         item.Name,
+		LastModified: item.Properties.LastModified,
+		Tier:         string(item.Properties.AccessTier),
+		ETag:         string(item.Properties.Etag),
+		Metadata:     item.Metadata,
+		Snapshot:     item.Snapshot,
+		Deleted:      item.Deleted,
+	}
+	if item.Properties.ContentLength != nil {
+		entry.Size = *item.Properties.ContentLength
+	}
+	if item.Properties.ContentType != nil {
+		entry.ContentType = *item.Properties.ContentType
+	}
+
+	return entry
+}
+
+// parsedListEntry is a listBlobEntry with the optional fields as pointers, to tell them apart from
+// their zero values.
+type parsedListEntry struct {
+	listBlobEntry
+	Size        *int64  `json:"size"`
+	ContentType *string `json:"contentType"`
+}
+
+// ParseListResponse parses the response of the list operation, including the continuation marker
+// returned in the response metadata. The response of a listing with a delimiter is an object with
+// the blobs and the prefixes, the other ones are an array of blobs.
+func ParseListResponse(resp *bindings.InvokeResponse) (ListResult, error) {
+	var result ListResult
+	if resp == nil {
+		return result, fmt.Errorf("list response is nil")
+	}
+
+	var entries []parsedListEntry
+	if data := bytes.TrimSpace(resp.Data); len(data) > 0 && data[0] == '{' {
+		var hierarchy struct {
+			Prefixes []string          `json:"prefixes"`
+			Blobs    []parsedListEntry `json:"blobs"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&hierarchy); err != nil {
+			return result, fmt.Errorf("error parsing list response, expected the blobs and prefixes of a listing with a delimiter, groupByTier isn't supported: %w", err)
+		}
+		entries, result.Prefixes = hierarchy.Blobs, hierarchy.Prefixes
+	} else if err := json.Unmarshal(resp.Data, &entries); err != nil {
+		return result, fmt.Errorf("error parsing list response: %w", err)
+	}
+	result.Blobs = make([]BlobInfo, len(entries))
+	for i, entry := range entries {
+		result.Blobs[i] = BlobInfo{
+			Name:     entry.Name,
+			Deleted:  entry.Deleted,
+			Snapshot: entry.Snapshot,
+			Properties: BlobProperties{
+				LastModified:  entry.LastModified,
+				Etag:          entry.ETag,
+				ContentLength: entry.Size,
+				ContentType:   entry.ContentType,
+				AccessTier:    entry.Tier,
+			},
+			Metadata: entry.Metadata,
+		}
+	}
+	result.Marker = resp.Metadata[metadataKeyMarker]
+	result.Cursor = resp.Metadata[objectstorage.MetadataKeyCursor]
+
+	return result, nil
+}
@@ -0,0 +1,95 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListResponse(t *testing.T) {
+	t.Run("parse blobs and marker", func(t *testing.T) {
+		lastModified := time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC)
+		size := int64(5)
+		contentType := "text/plain"
+		data, err := marshalListResult([]azblob.BlobItem{{
+			Name: "a.txt",
+			Properties: azblob.BlobProperties{
+				LastModified:  lastModified,
+				Etag:          "0x1",
+				ContentLength: &size,
+				ContentType:   &contentType,
+				AccessTier:    azblob.AccessTierCool,
+			},
+			Metadata: azblob.Metadata{"k": "v"},
+		}}, false, nil)
+		assert.Nil(t, err)
+
+		result, err := ParseListResponse(&bindings.InvokeResponse{
+			Data:     data,
+			Metadata: map[string]string{"marker": "next", "number": "1"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "next", result.Marker)
+		assert.Len(t, result.Blobs, 1)
+		blob := result.Blobs[0]
+		assert.Equal(t, "a.txt", blob.Name)
+		assert.Equal(t, lastModified, blob.Properties.LastModified)
+		assert.Equal(t, "0x1", blob.Properties.Etag)
+		assert.Equal(t, size, *blob.Properties.ContentLength)
+		assert.Equal(t, contentType, *blob.Properties.ContentType)
+		assert.Equal(t, "Cool", blob.Properties.AccessTier)
+		assert.Equal(t, "v", blob.Metadata["k"])
+	})
+
+	t.Run("parse empty listing", func(t *testing.T) {
+		result, err := ParseListResponse(&bindings.InvokeResponse{Data: []byte("null")})
+		assert.Nil(t, err)
+		assert.Empty(t, result.Blobs)
+		assert.Equal(t, "", result.Marker)
+	})
+
+	t.Run("leave missing properties unset", func(t *testing.T) {
+		result, err := ParseListResponse(&bindings.InvokeResponse{Data: []byte(`[{"name": "a.txt", "size": 0}, {"name": "b.txt"}]`)})
+		assert.Nil(t, err)
+		assert.Len(t, result.Blobs, 2)
+		assert.Equal(t, int64(0), *result.Blobs[0].Properties.ContentLength)
+		assert.Nil(t, result.Blobs[0].Properties.ContentType)
+		assert.Nil(t, result.Blobs[1].Properties.ContentLength)
+	})
+
+	t.Run("parse the listing of a delimiter", func(t *testing.T) {
+		data, err := json.Marshal(listHierarchyResult{
+			Prefixes: []string{"dir/"},
+			Blobs:    []listBlobEntry{{Name: "a.txt", Size: 5, ContentType: "text/plain"}},
+		})
+		assert.Nil(t, err)
+
+		result, err := ParseListResponse(&bindings.InvokeResponse{Data: data})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"dir/"}, result.Prefixes)
+		assert.Len(t, result.Blobs, 1)
+		assert.Equal(t, "a.txt", result.Blobs[0].Name)
+		assert.Equal(t, int64(5), *result.Blobs[0].Properties.ContentLength)
+		assert.Equal(t, "text/plain", *result.Blobs[0].Properties.ContentType)
+	})
+
+	t.Run("return error for groupByTier summaries", func(t *testing.T) {
+		_, err := ParseListResponse(&bindings.InvokeResponse{Data: []byte(`{"Cool": {"count": 1, "bytes": 5}}`)})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "groupByTier")
+	})
+
+	t.Run("return error for invalid data", func(t *testing.T) {
+		_, err := ParseListResponse(&bindings.InvokeResponse{Data: []byte("{")})
+		assert.Error(t, err)
+	})
+}
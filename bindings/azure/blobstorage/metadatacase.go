@@ -0,0 +1,78 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// The service stores metadata names as sent, but they are returned as HTTP headers and the SDK
+// lowercases them. With preserveMetadataCase, the names that aren't lowercase are stored in a
+// reserved metadata entry when writing, and are restored from it when reading.
+
+// Comma separated names of the metadata with upper case letters. Metadata names are C# identifiers,
+// so they can't contain commas.
+const metadataCaseKey = "daprmetadatacase"
+
+// withMetadataCase returns the metadata with the reserved entry recording the original case of the names.
+func withMetadataCase(metadata map[string]string) map[string]string {
+	var names []string
+	for name := range metadata {
+		if name != strings.ToLower(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return metadata
+	}
+	sort.Strings(names)
+
+	result := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		result[k] = v
+	}
+	result[metadataCaseKey] = strings.Join(names, ",")
+
+	return result
+}
+
+// restoreMetadataCase returns the metadata with the names recorded in the reserved entry, without the entry.
+func restoreMetadataCase(metadata map[string]string) map[string]string {
+	names, ok := metadata[metadataCaseKey]
+	if !ok {
+		return metadata
+	}
+
+	result := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if k != metadataCaseKey {
+			result[k] = v
+		}
+	}
+	for _, name := range strings.Split(names, ",") {
+		lower := strings.ToLower(name)
+		if v, ok := result[lower]; ok && lower != name {
+			delete(result, lower)
+			result[name] = v
+		}
+	}
+
+	return result
+}
+
+// restoreListMetadataCase restores the case of the metadata names of the blobs of a list page when
+// preserveMetadataCase is enabled.
+func (a *AzureBlobStorage) restoreListMetadataCase(blobs []azblob.BlobItem) {
+	if !a.metadata.PreserveMetadataCase {
+		return
+	}
+	for i := range blobs {
+		blobs[i].Metadata = restoreMetadataCase(blobs[i].Metadata)
+	}
+}
@@ -0,0 +1,29 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataCase(t *testing.T) {
+	t.Run("record names with upper case letters", func(t *testing.T) {
+		metadata := withMetadataCase(map[string]string{"MyKey": "a", "other": "b", "Z": "c"})
+		assert.Equal(t, "MyKey,Z", metadata[metadataCaseKey])
+	})
+
+	t.Run("keep lowercase metadata as-is", func(t *testing.T) {
+		metadata := withMetadataCase(map[string]string{"other": "b"})
+		assert.Equal(t, map[string]string{"other": "b"}, metadata)
+	})
+
+	t.Run("restore the case of metadata read back", func(t *testing.T) {
+		metadata := restoreMetadataCase(map[string]string{"mykey": "a", "other": "b", metadataCaseKey: "MyKey,Missing"})
+		assert.Equal(t, map[string]string{"MyKey": "a", "other": "b"}, metadata)
+	})
+}
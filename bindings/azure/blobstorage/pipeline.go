@@ -0,0 +1,58 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// newPipeline builds the same pipeline as azblob.NewPipeline with the binding's own policies added
+// before the credential policy, so that the headers they set are included in the request signature.
+func newPipeline(c azblob.Credential, o azblob.PipelineOptions, policies ...pipeline.Factory) pipeline.Pipeline {
+	// Closest to API goes first; closest to the wire goes last
+	f := []pipeline.Factory{
+		azblob.NewTelemetryPolicyFactory(o.Telemetry),
+		azblob.NewUniqueRequestIDPolicyFactory(),
+		azblob.NewRetryPolicyFactory(o.Retry),
+	}
+	f = append(f, policies...)
+	f = append(f,
+		c,
+		azblob.NewRequestLogPolicyFactory(o.RequestLog),
+		pipeline.MethodFactoryMarker())
+
+	return pipeline.NewPipeline(f, pipeline.Options{HTTPSender: o.HTTPSender, Log: o.Log})
+}
+
+// newRequestCountPolicyFactory returns a policy that counts every try of the requests whose context
+// has an objectstorage.RequestCounter.
+func newRequestCountPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			objectstorage.CountRequest(ctx)
+
+			return next.Do(ctx, request)
+		}
+	})
+}
+
+// newRequestHeadersPolicyFactory returns a policy that sets static headers on every request, e.g. the
+// authentication header of a gateway in front of the storage account.
+func newRequestHeadersPolicyFactory(headers map[string]string) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			for k, v := range headers {
+				request.Header.Set(k, v)
+			}
+
+			return next.Do(ctx, request)
+		}
+	})
+}
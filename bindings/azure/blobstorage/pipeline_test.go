@@ -0,0 +1,32 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHeadersPolicy(t *testing.T) {
+	var headers http.Header
+	sender := pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		headers = request.Header
+
+		return pipeline.NewHTTPResponse(&http.Response{StatusCode: http.StatusOK}), nil
+	})
+	policy := newRequestHeadersPolicyFactory(map[string]string{"x-gateway-auth": "secret"}).New(sender, nil)
+	u, _ := url.Parse("https://account.blob.core.windows.net/container/blob")
+
+	req, _ := pipeline.NewRequest(http.MethodGet, *u, nil)
+	_, err := policy.Do(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", headers.Get("x-gateway-auth"))
+}
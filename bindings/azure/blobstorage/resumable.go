@@ -0,0 +1,195 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	b64 "encoding/base64"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// Resumable uploads let a client send a blob with several create invocations, each one carrying a
+// Content-Range style value in the contentRange metadata key, e.g. "bytes 0-1048575/5242880".
+// Every range is staged as a block and the block list is committed once the final range arrives.
+// A client whose create was interrupted can retry from the nextOffset returned in the response
+// metadata, or re-send a range that starts where a previously staged range started.
+// Ranges for the same blob are expected to be sent sequentially.
+
+type contentRange struct {
+	start int64
+	end   int64
+	total int64
+}
+
+type stagedBlock struct {
+	offset  int64
+	blockID string
+}
+
+type partialUpload struct {
+	blocks     []stagedBlock
+	nextOffset int64
+	total      int64
+	expiresAt  time.Time
+}
+
+// partialUploads tracks the resumable uploads in progress, keyed by blob name.
+type partialUploads struct {
+	lock    sync.Mutex
+	uploads map[string]*partialUpload
+}
+
+func newPartialUploads() *partialUploads {
+	return &partialUploads{uploads: map[string]*partialUpload{}}
+}
+
+// parseContentRange parses a value of the form "bytes <start>-<end>/<total>". The end offset is
+// inclusive and the total size must be known.
+func parseContentRange(val string) (contentRange, error) {
+	var r contentRange
+	n, err := fmt.Sscanf(val, "bytes %d-%d/%d", &r.start, &r.end, &r.total)
+	if err != nil || n != 3 {
+		return r, fmt.Errorf("invalid %s %q, expected format \"bytes <start>-<end>/<total>\"", metadataKeyContentRange, val)
+	}
+	if r.start < 0 || r.end < r.start || r.end >= r.total {
+		return r, fmt.Errorf("invalid %s %q, range is out of bounds", metadataKeyContentRange, val)
+	}
+
+	return r, nil
+}
+
+// blockIDFromOffset returns a fixed-width base64 block ID, so that all the blocks of a blob have
+// IDs of the same length as required by the service.
+func blockIDFromOffset(offset int64) string {
+	return b64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%020d", offset)))
+}
+
+// begin validates that r continues (or retries part of) the upload of name and returns the ID to
+// stage the range with. Expired uploads are discarded.
+func (p *partialUploads) begin(name string, r contentRange, ttl time.Duration) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	for key, upload := range p.uploads {
+		if now.After(upload.expiresAt) {
+			delete(p.uploads, key)
+		}
+	}
+
+	upload, ok := p.uploads[name]
+	if !ok || r.start == 0 {
+		upload = &partialUpload{total: r.total}
+		p.uploads[name] = upload
+	}
+	if upload.total != r.total {
+		return "", fmt.Errorf("total size %d of %s doesn't match the size %d of the upload in progress", r.total, metadataKeyContentRange, upload.total)
+	}
+
+	if r.start != upload.nextOffset {
+		// A retry of a range that was already staged drops that range and everything after it
+		retried := -1
+		for i, block := range upload.blocks {
+			if block.offset == r.start {
+				retried = i
+
+				break
+			}
+		}
+		if retried == -1 {
+			return "", fmt.Errorf("unexpected range start %d, the upload can be resumed from offset %d", r.start, upload.nextOffset)
+		}
+		upload.blocks = upload.blocks[:retried]
+		upload.nextOffset = r.start
+	}
+	upload.expiresAt = now.Add(ttl)
+
+	return blockIDFromOffset(r.start), nil
+}
+
+// commit records the staged range and, when it is the last one, returns the block IDs to commit
+// in order and forgets the upload.
+func (p *partialUploads) commit(name string, r contentRange, blockID string) ([]string, int64, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	upload, ok := p.uploads[name]
+	if !ok {
+		return nil, 0, false
+	}
+	upload.blocks = append(upload.blocks, stagedBlock{offset: r.start, blockID: blockID})
+	upload.nextOffset = r.end + 1
+	if upload.nextOffset < upload.total {
+		return nil, upload.nextOffset, false
+	}
+
+	blockIDs := make([]string, len(upload.blocks))
+	for i, block := range upload.blocks {
+		blockIDs[i] = block.blockID
+	}
+	delete(p.uploads, name)
+
+	return blockIDs, upload.nextOffset, true
+}
+
+// createRange stages a range of a resumable upload. The access conditions are checked again when
+// the block list is committed, so a blob modified during the upload isn't overwritten.
+func (a *AzureBlobStorage) createRange(ctx context.Context, blobURL azblob.BlockBlobURL, name string, rangeVal string, req *bindings.InvokeRequest, blobHTTPHeaders azblob.BlobHTTPHeaders, conditions azblob.BlobAccessConditions) (*bindings.InvokeResponse, error) {
+	r, err := parseContentRange(rangeVal)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(req.Data)) != r.end-r.start+1 {
+		return nil, fmt.Errorf("the size of the data (%d bytes) doesn't match the %s %q", len(req.Data), metadataKeyContentRange, rangeVal)
+	}
+
+	blockID, err := a.partialUploads.begin(name, r, a.metadata.ResumableUploadTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = blobURL.StageBlock(ctx, blockID, bytes.NewReader(req.Data), conditions.LeaseAccessConditions, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error staging block for az blob: %w", err)
+	}
+
+	blockIDs, nextOffset, done := a.partialUploads.commit(name, r, blockID)
+	if !done {
+		return &bindings.InvokeResponse{
+			Metadata: map[string]string{
+				metadataKeyNextOffset: strconv.FormatInt(nextOffset, 10),
+			},
+		}, nil
+	}
+
+	commitResp, err := blobURL.CommitBlockList(ctx, blockIDs, blobHTTPHeaders, req.Metadata, conditions)
+	if err != nil {
+		if isPreconditionFailedError(err) {
+			return nil, preconditionFailedError(conditions)
+		}
+
+		return nil, fmt.Errorf("error committing block list for az blob: %w", err)
+	}
+
+	b, err := a.marshalCreateResponse(blobURL, name, commitResp, nextOffset, blobHTTPHeaders.ContentType, "", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+		Metadata: map[string]string{
+			metadataKeyNextOffset: strconv.FormatInt(nextOffset, 10),
+		},
+	}, nil
+}
@@ -0,0 +1,129 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContentRange(t *testing.T) {
+	t.Run("parse valid range", func(t *testing.T) {
+		r, err := parseContentRange("bytes 0-99/200")
+		assert.Nil(t, err)
+		assert.Equal(t, contentRange{start: 0, end: 99, total: 200}, r)
+	})
+
+	t.Run("return error for unknown total", func(t *testing.T) {
+		_, err := parseContentRange("bytes 0-99/*")
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for range past total", func(t *testing.T) {
+		_, err := parseContentRange("bytes 100-200/200")
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for inverted range", func(t *testing.T) {
+		_, err := parseContentRange("bytes 99-0/200")
+		assert.Error(t, err)
+	})
+}
+
+func TestPartialUploads(t *testing.T) {
+	first := contentRange{start: 0, end: 99, total: 200}
+	second := contentRange{start: 100, end: 199, total: 200}
+
+	t.Run("commit blocks in order once the last range is staged", func(t *testing.T) {
+		p := newPartialUploads()
+
+		id1, err := p.begin("blob", first, time.Hour)
+		assert.Nil(t, err)
+		_, next, done := p.commit("blob", first, id1)
+		assert.False(t, done)
+		assert.Equal(t, int64(100), next)
+
+		id2, err := p.begin("blob", second, time.Hour)
+		assert.Nil(t, err)
+		blockIDs, next, done := p.commit("blob", second, id2)
+		assert.True(t, done)
+		assert.Equal(t, int64(200), next)
+		assert.Equal(t, []string{id1, id2}, blockIDs)
+		assert.Empty(t, p.uploads)
+	})
+
+	t.Run("block IDs have the same length", func(t *testing.T) {
+		assert.Equal(t, len(blockIDFromOffset(0)), len(blockIDFromOffset(123456789)))
+	})
+
+	t.Run("retry a range that was already staged", func(t *testing.T) {
+		p := newPartialUploads()
+
+		id1, _ := p.begin("blob", first, time.Hour)
+		p.commit("blob", first, id1)
+
+		id1, err := p.begin("blob", contentRange{start: 0, end: 99, total: 200}, time.Hour)
+		assert.Nil(t, err)
+		_, next, _ := p.commit("blob", first, id1)
+		assert.Equal(t, int64(100), next)
+		assert.Len(t, p.uploads["blob"].blocks, 1)
+	})
+
+	t.Run("return error for a gap", func(t *testing.T) {
+		p := newPartialUploads()
+
+		_, err := p.begin("blob", second, time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for mismatched total", func(t *testing.T) {
+		p := newPartialUploads()
+
+		id1, _ := p.begin("blob", first, time.Hour)
+		p.commit("blob", first, id1)
+
+		_, err := p.begin("blob", contentRange{start: 100, end: 199, total: 300}, time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("discard expired uploads", func(t *testing.T) {
+		p := newPartialUploads()
+
+		id1, _ := p.begin("blob", first, -time.Second)
+		p.commit("blob", first, id1)
+
+		_, err := p.begin("blob", second, time.Hour)
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateRangeOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	blobStorage.metadata = &blobStorageMetadata{}
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data:     []byte("data"),
+			Metadata: map[string]string{"contentRange": "bytes 0-3/8"},
+		}
+		_, err := blobStorage.create(context.Background(), &r)
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+
+	t.Run("return error if data doesn't match the range", func(t *testing.T) {
+		r := bindings.InvokeRequest{
+			Data:     []byte("data"),
+			Metadata: map[string]string{"blobName": "foo", "contentRange": "bytes 0-1/8"},
+		}
+		_, err := blobStorage.create(context.Background(), &r)
+		assert.Error(t, err)
+	})
+}
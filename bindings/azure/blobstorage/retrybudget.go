@@ -0,0 +1,62 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// The retry policy of the pipeline retries each request on its own. To share a budget across the
+// requests of an operation, the budget is passed in the context and this policy, which runs on
+// every try, recognizes the retries by the client request ID the tries of a request have in common.
+
+type retryBudgetContextKey struct{}
+
+type requestRetryBudget struct {
+	budget *objectstorage.RetryBudget
+	lock   sync.Mutex
+	seen   map[string]bool
+}
+
+// withRetryBudget returns a context whose requests share the budget. A nil budget is unlimited.
+func withRetryBudget(ctx context.Context, budget *objectstorage.RetryBudget) context.Context {
+	if budget == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, retryBudgetContextKey{}, &requestRetryBudget{budget: budget, seen: map[string]bool{}})
+}
+
+// isRetry reports if a request with the ID was already tried.
+func (b *requestRetryBudget) isRetry(requestID string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.seen[requestID] {
+		return true
+	}
+	b.seen[requestID] = true
+
+	return false
+}
+
+func newRetryBudgetPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			if b, ok := ctx.Value(retryBudgetContextKey{}).(*requestRetryBudget); ok {
+				if b.isRetry(request.Header.Get("x-ms-client-request-id")) && !b.budget.Take() {
+					return nil, objectstorage.ErrRetryBudgetExceeded
+				}
+			}
+
+			return next.Do(ctx, request)
+		}
+	})
+}
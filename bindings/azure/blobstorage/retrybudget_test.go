@@ -0,0 +1,52 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudgetPolicy(t *testing.T) {
+	sender := pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		return pipeline.NewHTTPResponse(&http.Response{StatusCode: http.StatusOK}), nil
+	})
+	policy := newRetryBudgetPolicyFactory().New(sender, nil)
+	u, _ := url.Parse("https://account.blob.core.windows.net/container/blob")
+	newRequest := func(id string) pipeline.Request {
+		req, _ := pipeline.NewRequest(http.MethodGet, *u, nil)
+		req.Header.Set("x-ms-client-request-id", id)
+
+		return req
+	}
+
+	t.Run("fail retries once the budget is exhausted", func(t *testing.T) {
+		ctx := withRetryBudget(context.Background(), objectstorage.NewRetryBudget(1))
+
+		_, err := policy.Do(ctx, newRequest("a"))
+		assert.Nil(t, err)
+		_, err = policy.Do(ctx, newRequest("b"))
+		assert.Nil(t, err)
+		// First retry of a uses the budget
+		_, err = policy.Do(ctx, newRequest("a"))
+		assert.Nil(t, err)
+		_, err = policy.Do(ctx, newRequest("b"))
+		assert.Equal(t, objectstorage.ErrRetryBudgetExceeded, err)
+	})
+
+	t.Run("allow retries without budget", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := policy.Do(context.Background(), newRequest("a"))
+			assert.Nil(t, err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// Options of the retry policy of the pipeline, which retries the requests that fail with a
+// transient error with an exponential backoff. The options that are not set keep the defaults of
+// the SDK: 4 tries, a try timeout of 1 minute, and delays from 4 seconds up to 2 minutes.
+
+const (
+	// Maximum number of tries of a request, including the first one
+	metadataKeyMaxTries = "maxTries"
+	// Maximum time a try of a request may take before it is cancelled and retried
+	metadataKeyTryTimeout = "tryTimeout"
+	// Delay before the first retry, doubled on every retry
+	metadataKeyRetryDelay = "retryDelay"
+	// Maximum delay between two retries
+	metadataKeyMaxRetryDelay = "maxRetryDelay"
+
+	defaultRetryDelay    = 4 * time.Second
+	defaultMaxRetryDelay = 120 * time.Second
+)
+
+// parseRetryOptions parses the retry options from the component metadata. The SDK requires both
+// delays to be set when one of them is, so the other one gets its default value.
+func parseRetryOptions(properties map[string]string) (azblob.RetryOptions, error) {
+	o := azblob.RetryOptions{Policy: azblob.RetryPolicyExponential}
+	if val, ok := properties[metadataKeyMaxTries]; ok && val != "" {
+		n, err := strconv.ParseInt(val, 10, 32)
+		if err != nil || n <= 0 {
+			return o, fmt.Errorf("invalid %s %q, expected a positive number", metadataKeyMaxTries, val)
+		}
+		o.MaxTries = int32(n)
+	}
+
+	var err error
+	for key, d := range map[string]*time.Duration{
+		metadataKeyTryTimeout:    &o.TryTimeout,
+		metadataKeyRetryDelay:    &o.RetryDelay,
+		metadataKeyMaxRetryDelay: &o.MaxRetryDelay,
+	} {
+		*d, err = objectstorage.ParseDuration(properties, key, 0)
+		if err != nil {
+			return o, err
+		}
+	}
+
+	if o.RetryDelay == 0 && o.MaxRetryDelay == 0 {
+		return o, nil
+	}
+	if o.RetryDelay == 0 {
+		o.RetryDelay = defaultRetryDelay
+		if o.RetryDelay > o.MaxRetryDelay {
+			o.RetryDelay = o.MaxRetryDelay
+		}
+	}
+	if o.MaxRetryDelay == 0 {
+		o.MaxRetryDelay = defaultMaxRetryDelay
+		if o.MaxRetryDelay < o.RetryDelay {
+			o.MaxRetryDelay = o.RetryDelay
+		}
+	}
+	if o.RetryDelay > o.MaxRetryDelay {
+		return o, fmt.Errorf("invalid %s %s, it can't be greater than %s %s", metadataKeyRetryDelay, o.RetryDelay, metadataKeyMaxRetryDelay, o.MaxRetryDelay)
+	}
+
+	return o, nil
+}
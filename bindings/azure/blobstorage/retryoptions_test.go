@@ -0,0 +1,98 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryOptions(t *testing.T) {
+	t.Run("keep the defaults of the SDK", func(t *testing.T) {
+		o, err := parseRetryOptions(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, azblob.RetryOptions{}, o)
+	})
+
+	t.Run("parse all the options", func(t *testing.T) {
+		o, err := parseRetryOptions(map[string]string{
+			"maxTries":      "6",
+			"tryTimeout":    "30s",
+			"retryDelay":    "1s",
+			"maxRetryDelay": "10s",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, azblob.RetryOptions{MaxTries: 6, TryTimeout: 30 * time.Second, RetryDelay: time.Second, MaxRetryDelay: 10 * time.Second}, o)
+	})
+
+	t.Run("set the default of the other delay", func(t *testing.T) {
+		o, err := parseRetryOptions(map[string]string{"retryDelay": "1s"})
+		assert.Nil(t, err)
+		assert.Equal(t, defaultMaxRetryDelay, o.MaxRetryDelay)
+
+		o, err = parseRetryOptions(map[string]string{"retryDelay": "5m"})
+		assert.Nil(t, err)
+		assert.Equal(t, 5*time.Minute, o.MaxRetryDelay)
+
+		o, err = parseRetryOptions(map[string]string{"maxRetryDelay": "1s"})
+		assert.Nil(t, err)
+		assert.Equal(t, time.Second, o.RetryDelay)
+	})
+
+	t.Run("return error for invalid options", func(t *testing.T) {
+		for _, properties := range []map[string]string{
+			{"maxTries": "0"},
+			{"maxTries": "many"},
+			{"tryTimeout": "-1s"},
+			{"retryDelay": "soon"},
+			{"retryDelay": "10s", "maxRetryDelay": "1s"},
+		} {
+			_, err := parseRetryOptions(properties)
+			assert.Error(t, err, properties)
+		}
+	})
+}
+
+func TestRetryOptions(t *testing.T) {
+	var tries int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The container checked by Init exists
+		if r.URL.Query().Get("restype") == "container" {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+		atomic.AddInt32(&tries, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+	assert.Nil(t, blobStorage.Init(bindings.Metadata{Properties: map[string]string{
+		"storageAccount":   "devstoreaccount1",
+		"storageAccessKey": "a2V5",
+		"container":        "test",
+		"createContainer":  "false",
+		"endpoint":         server.URL,
+		"maxTries":         "3",
+		"retryDelay":       "1ms",
+	}}))
+
+	_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+		Operation: existsOperation,
+		Metadata:  map[string]string{"blobName": "foo"},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&tries))
+}
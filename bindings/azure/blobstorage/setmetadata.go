@@ -0,0 +1,82 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const setMetadataOperation bindings.OperationKind = "setMetadata"
+
+type setMetadataResponse struct {
+	// ETag of the blob after the update, for the conditions of the next requests
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// setMetadata replaces the user-defined metadata of a blob with the request metadata, without
+// rewriting its content. The blob name, the access conditions and the customer-provided key of the
+// request are not stored as metadata.
+func (a *AzureBlobStorage) setMetadata(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+	delete(req.Metadata, metadataKeyBlobName)
+	blobURL := a.getBlobURL(blobName)
+
+	enc, err := parseRequestEncryption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := parseWriteAccessConditions(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := req.Metadata
+	if a.metadata.PreserveMetadataCase {
+		metadata = withMetadataCase(metadata)
+	}
+	if err = a.fitMetadata(blobName, metadata); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, enc)
+	resp, err := blobURL.SetMetadata(ctx, metadata, conditions)
+	if err != nil {
+		switch {
+		case isPreconditionFailedError(err):
+			return nil, ErrPreconditionFailed
+		case isEncryptionKeyRequiredError(err):
+			return nil, ErrEncryptionKeyRequired
+		case isNotFoundResponse(err, azblob.ServiceCodeBlobNotFound):
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("error setting az blob metadata: %w", err)
+	}
+
+	b, err := json.Marshal(setMetadataResponse{
+		ETag:         string(resp.ETag()),
+		LastModified: resp.LastModified(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling setMetadata response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
@@ -0,0 +1,70 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMetadata(t *testing.T) {
+	t.Run("replace the metadata and return the new etag", func(t *testing.T) {
+		var header http.Header
+		var query string
+		blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			header, query = r.Header, r.URL.RawQuery
+			w.Header().Set("ETag", "\"new\"")
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: setMetadataOperation,
+			Metadata:  map[string]string{"blobName": "foo", "ifMatch": "\"old\"", "owner": "alice"},
+		})
+		assert.Nil(t, err)
+		assert.Contains(t, query, "comp=metadata")
+		assert.Equal(t, "alice", header.Get("x-ms-meta-owner"))
+		assert.Empty(t, header.Get("x-ms-meta-blobName"))
+		assert.Empty(t, header.Get("x-ms-meta-ifMatch"))
+		assert.Equal(t, "\"old\"", header.Get("If-Match"))
+
+		var result setMetadataResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &result))
+		assert.Equal(t, "\"new\"", result.ETag)
+		assert.Equal(t, 2006, result.LastModified.Year())
+	})
+
+	t.Run("return the errors of the service", func(t *testing.T) {
+		for expected, status := range map[error]int{
+			ErrPreconditionFailed: http.StatusPreconditionFailed,
+			ErrBlobNotFound:       http.StatusNotFound,
+		} {
+			blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+				Operation: setMetadataOperation,
+				Metadata:  map[string]string{"blobName": "foo", "ifMatch": "\"old\""},
+			})
+			assert.Equal(t, expected, err)
+		}
+	})
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		blobStorage := newTestBlobStorage(t, http.NotFoundHandler())
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: setMetadataOperation,
+			Metadata:  map[string]string{"owner": "alice"},
+		})
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+}
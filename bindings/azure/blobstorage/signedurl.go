@@ -0,0 +1,129 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+const (
+	createSASURLOperation bindings.OperationKind = "createSasUrl"
+
+	// Validity of the SAS URL of createSasUrl, such as 1h
+	metadataKeyExpiry = "expiry"
+	// Permissions of the SAS URL of createSasUrl, a combination of r (read), w (write) and d (delete)
+	metadataKeyPermissions = "permissions"
+	defaultSASPermissions  = "r"
+	allowedSASPermissions  = "rwd"
+)
+
+// ErrSASNotSupported is returned by createSasUrl when the binding doesn't use shared key credentials.
+var ErrSASNotSupported = errors.New("SAS URLs can only be signed with shared key credentials, set storageAccessKey")
+
+type createSASURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresOn time.Time `json:"expiresOn"`
+}
+
+// createSASURL returns a service SAS URL of a blob, e.g. to let a browser download it without going
+// through the binding. The blob doesn't need to exist, so that the URL can be used to upload it.
+func (a *AzureBlobStorage) createSASURL(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+	expiry, err := objectstorage.ParseURLExpiry(req.Metadata, metadataKeyExpiry)
+	if err != nil {
+		return nil, err
+	}
+	permissions := req.Metadata[metadataKeyPermissions]
+	if permissions == "" {
+		permissions = defaultSASPermissions
+	}
+	if strings.Trim(permissions, allowedSASPermissions) != "" {
+		return nil, fmt.Errorf("invalid %s %q, expected a combination of r, w and d", metadataKeyPermissions, permissions)
+	}
+	if a.sharedKeyCredential == nil {
+		return nil, ErrSASNotSupported
+	}
+
+	// Parse orders the permissions as the service expects them
+	var sasPermissions azblob.BlobSASPermissions
+	if err = sasPermissions.Parse(permissions); err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", metadataKeyPermissions, permissions, err)
+	}
+	signedURL, expiresOn, err := a.signBlobURL(a.getBlobURL(blobName).URL(), blobName, expiry, sasPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(createSASURLResponse{
+		URL:       signedURL,
+		ExpiresOn: expiresOn,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling createSasUrl response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// objectURLs returns the public URL of the blob and, with shared key credentials, a service SAS URL
+// granting read access to the blob until expiry. Azure AD credentials would need a user delegation
+// key, so no signed URL is returned for them.
+func (a *AzureBlobStorage) objectURLs(blobURL azblob.BlockBlobURL, name string, expiry time.Duration) (*objectstorage.ObjectURLs, error) {
+	u := blobURL.URL()
+	urls := &objectstorage.ObjectURLs{PublicURL: objectstorage.PublicURL(u.String())}
+	if a.sharedKeyCredential == nil {
+		a.logger.Debugf("not returning a signed URL for %s, the credentials can't sign SAS URLs", name)
+
+		return urls, nil
+	}
+
+	signedURL, expiresOn, err := a.signBlobURL(u, name, expiry, azblob.BlobSASPermissions{Read: true})
+	if err != nil {
+		return nil, err
+	}
+	urls.SignedURL = signedURL
+	urls.SignedURLExpiresOn = &expiresOn
+
+	return urls, nil
+}
+
+// signBlobURL adds a service SAS signed with the shared key credential to the URL of a blob.
+func (a *AzureBlobStorage) signBlobURL(u url.URL, name string, expiry time.Duration, permissions azblob.BlobSASPermissions) (string, time.Time, error) {
+	protocol := azblob.SASProtocolHTTPS
+	if u.Scheme == "http" {
+		// Custom endpoints such as Azurite may not use TLS
+		protocol = azblob.SASProtocolHTTPSandHTTP
+	}
+	expiresOn := time.Now().UTC().Add(expiry).Truncate(time.Second)
+	sas, err := azblob.BlobSASSignatureValues{
+		Protocol:      protocol,
+		ExpiryTime:    expiresOn,
+		ContainerName: a.metadata.Container,
+		BlobName:      name,
+		Permissions:   permissions.String(),
+	}.NewSASQueryParameters(a.sharedKeyCredential)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error signing URL for az blob: %w", err)
+	}
+	u.RawQuery = sas.Encode()
+
+	return u.String(), expiresOn, nil
+}
@@ -0,0 +1,134 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSignedURL(t *testing.T) {
+	var uploaded http.Header
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded = r.Header
+		w.Header().Set("ETag", "\"etag\"")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	newRequest := func() *bindings.InvokeRequest {
+		return &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"blobName": "foo", "returnSignedURL": "true", "signedURLExpiry": "1h"},
+		}
+	}
+
+	t.Run("return a SAS URL with shared key credentials", func(t *testing.T) {
+		resp, err := blobStorage.Invoke(newRequest())
+		assert.Nil(t, err)
+		assert.Empty(t, uploaded.Get("x-ms-meta-returnSignedURL"))
+		assert.Empty(t, uploaded.Get("x-ms-meta-signedURLExpiry"))
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.NotNil(t, created.ObjectURLs)
+		assert.Equal(t, created.BlobURL, created.PublicURL)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *created.SignedURLExpiresOn, time.Minute)
+
+		signed, err := url.Parse(created.SignedURL)
+		assert.Nil(t, err)
+		assert.Equal(t, created.PublicURL, signed.Scheme+"://"+signed.Host+signed.Path)
+		assert.Equal(t, "r", signed.Query().Get("sp"))
+		assert.Equal(t, "b", signed.Query().Get("sr"))
+		assert.Equal(t, created.SignedURLExpiresOn.Format(time.RFC3339), signed.Query().Get("se"))
+		assert.NotEmpty(t, signed.Query().Get("sig"))
+	})
+
+	t.Run("only return the public URL without shared key credentials", func(t *testing.T) {
+		blobStorage.sharedKeyCredential = nil
+		resp, err := blobStorage.Invoke(newRequest())
+		assert.Nil(t, err)
+
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, created.BlobURL, created.PublicURL)
+		assert.Empty(t, created.SignedURL)
+		assert.Nil(t, created.SignedURLExpiresOn)
+	})
+
+	t.Run("return error for invalid signedURLExpiry", func(t *testing.T) {
+		r := newRequest()
+		r.Metadata["signedURLExpiry"] = "1000h"
+		_, err := blobStorage.Invoke(r)
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateSASURL(t *testing.T) {
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail(t, "unexpected request")
+	}))
+
+	t.Run("return a signed URL with the permissions", func(t *testing.T) {
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: createSASURLOperation,
+			Metadata:  map[string]string{"blobName": "dir/foo", "expiry": "2h", "permissions": "dr"},
+		})
+		assert.Nil(t, err)
+
+		var created createSASURLResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), created.ExpiresOn, time.Minute)
+
+		signed, err := url.Parse(created.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, blobStorage.getBlobURL("dir/foo").String(), signed.Scheme+"://"+signed.Host+signed.Path)
+		assert.Equal(t, "rd", signed.Query().Get("sp"))
+		assert.Equal(t, created.ExpiresOn.Format(time.RFC3339), signed.Query().Get("se"))
+		assert.NotEmpty(t, signed.Query().Get("sig"))
+	})
+
+	t.Run("default to read permission", func(t *testing.T) {
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: createSASURLOperation,
+			Metadata:  map[string]string{"blobName": "foo"},
+		})
+		assert.Nil(t, err)
+
+		var created createSASURLResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		signed, err := url.Parse(created.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, "r", signed.Query().Get("sp"))
+	})
+
+	t.Run("return error for invalid requests", func(t *testing.T) {
+		for _, metadata := range []map[string]string{
+			{},
+			{"blobName": "foo", "permissions": "rx"},
+			{"blobName": "foo", "expiry": "0s"},
+			{"blobName": "foo", "expiry": "later"},
+		} {
+			_, err := blobStorage.Invoke(&bindings.InvokeRequest{Operation: createSASURLOperation, Metadata: metadata})
+			assert.Error(t, err, metadata)
+		}
+	})
+
+	t.Run("return ErrSASNotSupported without shared key credentials", func(t *testing.T) {
+		blobStorage.sharedKeyCredential = nil
+		_, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: createSASURLOperation,
+			Metadata:  map[string]string{"blobName": "foo"},
+		})
+		assert.Equal(t, ErrSASNotSupported, err)
+	})
+}
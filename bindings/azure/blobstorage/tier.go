@@ -0,0 +1,134 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// Access tiers of block blobs. The SDK version used by the binding doesn't expose the rehydrate
+// priority on BlobURL.SetTier, so the header is set by a pipeline policy from a value carried in the
+// request context, like the encryption headers.
+// See: https://docs.microsoft.com/en-us/azure/storage/blobs/storage-blob-storage-tiers
+
+const (
+	setTierOperation bindings.OperationKind = "setTier"
+
+	// Access tier of the setTier operation, one of azblob.PossibleAccessTierTypeValues()
+	metadataKeyTier = "tier"
+	// Priority of the rehydration of an archived blob moved to the Hot or Cool tier, High or Standard
+	metadataKeyRehydratePriority = "rehydratePriority"
+)
+
+type rehydratePriorityContextKey struct{}
+
+// setTier moves the blob to another access tier. Moving an archived blob to the Hot or Cool tier
+// starts its rehydration, which can take several hours; the blob stays in the Archive tier until
+// it is completed. With verifyTier, the tier is read back from the blob properties and reported as
+// pending while the blob is rehydrating.
+func (a *AzureBlobStorage) setTier(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobURL azblob.BlockBlobURL
+	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
+		blobURL = a.getBlobURL(val)
+	} else {
+		return nil, ErrMissingBlobName
+	}
+
+	tier := azblob.AccessTierType(req.Metadata[metadataKeyTier])
+	if !a.isValidAccessTier(tier) {
+		return nil, fmt.Errorf("invalid access tier: %s; allowed: %s",
+			tier, allowedAccessTiers())
+	}
+
+	priority := azblob.RehydratePriorityNone
+	if val, ok := req.Metadata[metadataKeyRehydratePriority]; ok && val != "" {
+		priority = azblob.RehydratePriorityType(val)
+		if !a.isValidRehydratePriority(priority) {
+			return nil, fmt.Errorf("invalid rehydrate priority: %s; allowed: %s",
+				priority, allowedRehydratePriorities())
+		}
+	}
+
+	verifyTier, err := req.GetMetadataAsBool(objectstorage.MetadataKeyVerifyTier)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	if priority != azblob.RehydratePriorityNone {
+		ctx = context.WithValue(ctx, rehydratePriorityContextKey{}, priority)
+	}
+	_, err = blobURL.SetTier(ctx, tier, azblob.LeaseAccessConditions{})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("error setting tier of az blob: %w", err)
+	}
+	if !verifyTier {
+		return nil, nil
+	}
+
+	props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{})
+	if err != nil {
+		return nil, fmt.Errorf("error verifying tier of az blob: %w", err)
+	}
+	// The archive status is set while the blob is rehydrating, e.g. rehydrate-pending-to-hot
+	status, err := objectstorage.VerifyTier(string(tier), props.AccessTier(), props.ArchiveStatus() != "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{objectstorage.MetadataKeyTierStatus: status},
+	}, nil
+}
+
+// allowedAccessTiers returns the access tiers known to the SDK. Hot, Cool and Archive apply to block
+// blobs, the P* tiers to page blobs on premium accounts.
+func allowedAccessTiers() []azblob.AccessTierType {
+	var tiers []azblob.AccessTierType
+	for _, tier := range azblob.PossibleAccessTierTypeValues() {
+		if tier != azblob.AccessTierNone {
+			tiers = append(tiers, tier)
+		}
+	}
+
+	return tiers
+}
+
+func allowedRehydratePriorities() []azblob.RehydratePriorityType {
+	var priorities []azblob.RehydratePriorityType
+	for _, priority := range azblob.PossibleRehydratePriorityTypeValues() {
+		if priority != azblob.RehydratePriorityNone {
+			priorities = append(priorities, priority)
+		}
+	}
+
+	return priorities
+}
+
+// newRehydratePriorityPolicyFactory sets the rehydrate priority header of the requests whose context
+// carries one.
+func newRehydratePriorityPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			if priority, ok := ctx.Value(rehydratePriorityContextKey{}).(azblob.RehydratePriorityType); ok {
+				request.Header.Set("x-ms-rehydrate-priority", string(priority))
+			}
+
+			return next.Do(ctx, request)
+		}
+	})
+}
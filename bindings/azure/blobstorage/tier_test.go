@@ -0,0 +1,117 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTierOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+
+	t.Run("return error if blobName is missing", func(t *testing.T) {
+		r := bindings.InvokeRequest{Metadata: map[string]string{"tier": "Cool"}}
+		_, err := blobStorage.setTier(context.Background(), &r)
+		assert.Equal(t, ErrMissingBlobName, err)
+	})
+
+	t.Run("return error for invalid tier", func(t *testing.T) {
+		for _, tier := range []string{"", "Frozen"} {
+			r := bindings.InvokeRequest{Metadata: map[string]string{"blobName": "foo", "tier": tier}}
+			_, err := blobStorage.setTier(context.Background(), &r)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "allowed: [Archive Cool Hot")
+			}
+		}
+	})
+
+	t.Run("return error for invalid rehydrate priority", func(t *testing.T) {
+		r := bindings.InvokeRequest{Metadata: map[string]string{"blobName": "foo", "tier": "Hot", "rehydratePriority": "Urgent"}}
+		_, err := blobStorage.setTier(context.Background(), &r)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "allowed: [High Standard]")
+		}
+	})
+}
+
+func TestRehydratePriorityPolicy(t *testing.T) {
+	var header string
+	next := pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		header = request.Header.Get("x-ms-rehydrate-priority")
+
+		return nil, nil
+	})
+	policy := newRehydratePriorityPolicyFactory().New(next, nil)
+
+	t.Run("set the priority of the context", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://account.blob.core.windows.net/c/b", nil)
+		ctx := context.WithValue(context.Background(), rehydratePriorityContextKey{}, azblob.RehydratePriorityHigh)
+		_, _ = policy.Do(ctx, pipeline.Request{Request: req})
+		assert.Equal(t, "High", header)
+	})
+
+	t.Run("leave the header unset without priority", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://account.blob.core.windows.net/c/b", nil)
+		_, _ = policy.Do(context.Background(), pipeline.Request{Request: req})
+		assert.Empty(t, header)
+	})
+}
+
+func TestSetTierVerifyTier(t *testing.T) {
+	var accessTier, archiveStatus string
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("x-ms-access-tier", accessTier)
+			if archiveStatus != "" {
+				w.Header().Set("x-ms-archive-status", archiveStatus)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	setTier := func(tier string) (*bindings.InvokeResponse, error) {
+		return blobStorage.setTier(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"blobName": "foo", "tier": tier, "verifyTier": "true"},
+		})
+	}
+
+	t.Run("report the tier as applied", func(t *testing.T) {
+		accessTier, archiveStatus = "Cool", ""
+		resp, err := setTier("Cool")
+		assert.Nil(t, err)
+		assert.Equal(t, objectstorage.TierStatusApplied, resp.Metadata["tierStatus"])
+	})
+
+	t.Run("report rehydrating blobs as pending", func(t *testing.T) {
+		accessTier, archiveStatus = "Archive", "rehydrate-pending-to-hot"
+		resp, err := setTier("Hot")
+		assert.Nil(t, err)
+		assert.Equal(t, objectstorage.TierStatusPending, resp.Metadata["tierStatus"])
+	})
+
+	t.Run("return error if the tier was not applied", func(t *testing.T) {
+		accessTier, archiveStatus = "Hot", ""
+		_, err := setTier("Cool")
+		assert.True(t, errors.Is(err, objectstorage.ErrTierNotApplied), err)
+	})
+
+	t.Run("don't read the properties without verifyTier", func(t *testing.T) {
+		resp, err := blobStorage.setTier(context.Background(), &bindings.InvokeRequest{
+			Metadata: map[string]string{"blobName": "foo", "tier": "Cool"},
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, resp)
+	})
+}
@@ -0,0 +1,256 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+)
+
+// Upload sessions stream a blob of unknown size, see objectstorage.UploadSessions. Every chunk is
+// staged as a block when it is received, so the binding doesn't buffer the data of a session, and
+// finishUpload commits the staged blocks. The blocks of sessions that are never finished are
+// discarded by the service after a week.
+
+const (
+	initUploadOperation   bindings.OperationKind = "initUpload"
+	uploadChunkOperation  bindings.OperationKind = "uploadChunk"
+	finishUploadOperation bindings.OperationKind = "finishUpload"
+	// Returns the blocks staged so far for a session or a blob, see uploadStatusResponse
+	getUploadStatusOperation bindings.OperationKind = "getUploadStatus"
+)
+
+// blobUploadSession is the state of an upload session. The content headers, metadata, access
+// conditions and encryption are the ones of the initUpload request.
+type blobUploadSession struct {
+	blobURL         azblob.BlockBlobURL
+	blobHTTPHeaders azblob.BlobHTTPHeaders
+	metadata        azblob.Metadata
+	conditions      azblob.BlobAccessConditions
+	enc             *requestEncryption
+	blockIDs        []string
+}
+
+type initUploadResponse struct {
+	SessionToken string `json:"sessionToken"`
+	BlobName     string `json:"blobName"`
+}
+
+// uploadStatusResponse is the response of getUploadStatus. The committed blocks are the ones of the
+// current blob, the uncommitted ones are the blocks staged by upload sessions or concurrent uploads.
+type uploadStatusResponse struct {
+	BlobName         string `json:"blobName"`
+	BlockCount       int    `json:"blockCount"`
+	CommittedBytes   int64  `json:"committedBytes"`
+	UncommittedBytes int64  `json:"uncommittedBytes"`
+	TotalBytes       int64  `json:"totalBytes"`
+}
+
+// initUpload starts an upload session for blobName, generated like the name of create if not set.
+func (a *AzureBlobStorage) initUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	delete(req.Metadata, metadataKeyBlobName)
+
+	enc, err := parseRequestEncryption(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := parseWriteAccessConditions(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	blobHTTPHeaders, err := parseBlobHTTPHeaders(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if blobName == "" {
+		blobName = objectstorage.GenerateKey(a.metadata.KeyTemplate, blobHTTPHeaders.ContentType, time.Now())
+	}
+	if a.metadata.AppendExtensionFromContentType {
+		blobName = objectstorage.AppendExtension(blobName, blobHTTPHeaders.ContentType)
+	}
+	if a.metadata.PreserveMetadataCase {
+		req.Metadata = withMetadataCase(req.Metadata)
+	}
+	if err = a.fitMetadata(blobName, req.Metadata); err != nil {
+		return nil, err
+	}
+
+	token, err := a.uploadSessions.Start(blobName, &blobUploadSession{
+		blobURL:         a.getBlobURL(blobName),
+		blobHTTPHeaders: blobHTTPHeaders,
+		metadata:        req.Metadata,
+		conditions:      conditions,
+		enc:             enc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(initUploadResponse{
+		SessionToken: token,
+		BlobName:     blobName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling initUpload response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// uploadChunk stages the data of the request as the block at offset of the session.
+func (a *AzureBlobStorage) uploadChunk(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	token := req.Metadata[objectstorage.MetadataKeySessionToken]
+	if token == "" {
+		return nil, fmt.Errorf("%s is a required attribute", objectstorage.MetadataKeySessionToken)
+	}
+	offset, err := objectstorage.ParseOffset(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Data) == 0 {
+		return nil, fmt.Errorf("the data of a chunk can't be empty")
+	}
+
+	session, err := a.uploadSessions.Acquire(token)
+	if err != nil {
+		return nil, err
+	}
+	defer a.uploadSessions.Release(session)
+	if err = session.CheckOffset(offset); err != nil {
+		return nil, err
+	}
+	state := session.State.(*blobUploadSession)
+
+	data, err := a.decodeData(session.Name, req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, state.enc)
+	blockID := blockIDFromOffset(offset)
+	_, err = state.blobURL.StageBlock(ctx, blockID, bytes.NewReader(data), state.conditions.LeaseAccessConditions, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error staging block for az blob: %w", err)
+	}
+	state.blockIDs = append(state.blockIDs, blockID)
+	session.Advance(data)
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			metadataKeyNextOffset: strconv.FormatInt(session.Offset, 10),
+		},
+	}, nil
+}
+
+// finishUpload commits the blocks staged by the session and ends it. The session is kept if the
+// commit fails, so that it can be retried.
+func (a *AzureBlobStorage) finishUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	token := req.Metadata[objectstorage.MetadataKeySessionToken]
+	if token == "" {
+		return nil, fmt.Errorf("%s is a required attribute", objectstorage.MetadataKeySessionToken)
+	}
+
+	session, err := a.uploadSessions.Acquire(token)
+	if err != nil {
+		return nil, err
+	}
+	state := session.State.(*blobUploadSession)
+	if session.Offset == 0 && !a.metadata.AllowEmpty {
+		a.uploadSessions.Release(session)
+
+		return nil, objectstorage.ErrEmptyData
+	}
+
+	// An empty block list commits a blob without data
+	ctx, cancel := a.metadata.Timeouts.WriteContext(ctx)
+	defer cancel()
+	ctx = withRequestEncryption(ctx, state.enc)
+	commitResp, err := state.blobURL.CommitBlockList(ctx, state.blockIDs, state.blobHTTPHeaders, state.metadata, state.conditions)
+	if err != nil {
+		a.uploadSessions.Release(session)
+		if isPreconditionFailedError(err) {
+			return nil, preconditionFailedError(state.conditions)
+		}
+
+		return nil, fmt.Errorf("error committing block list for az blob: %w", err)
+	}
+	a.uploadSessions.Finish(token, session)
+
+	b, err := a.marshalCreateResponse(state.blobURL, session.Name, commitResp, session.Offset, state.blobHTTPHeaders.ContentType, session.SHA256(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// getUploadStatus returns the size of the blocks of the blob of a session, or of blobName, read from the
+// block list of the blob. It doesn't wait for the chunk of the session in progress, if any.
+func (a *AzureBlobStorage) getUploadStatus(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var blobName string
+	var blobURL azblob.BlockBlobURL
+	var lease azblob.LeaseAccessConditions
+	if token := req.Metadata[objectstorage.MetadataKeySessionToken]; token != "" {
+		session, err := a.uploadSessions.Lookup(token)
+		if err != nil {
+			return nil, err
+		}
+		state := session.State.(*blobUploadSession)
+		blobName, blobURL, lease = session.Name, state.blobURL, state.conditions.LeaseAccessConditions
+	} else if val := req.Metadata[metadataKeyBlobName]; val != "" {
+		blobName, blobURL = val, a.getBlobURL(val)
+	} else {
+		return nil, fmt.Errorf("%s or %s is a required attribute", objectstorage.MetadataKeySessionToken, metadataKeyBlobName)
+	}
+
+	ctx, cancel := a.metadata.Timeouts.ReadContext(ctx)
+	defer cancel()
+	status := uploadStatusResponse{BlobName: blobName}
+	blockList, err := blobURL.GetBlockList(ctx, azblob.BlockListAll, lease)
+	switch {
+	case err == nil:
+		for _, block := range blockList.CommittedBlocks {
+			status.CommittedBytes += int64(block.Size)
+		}
+		for _, block := range blockList.UncommittedBlocks {
+			status.UncommittedBytes += int64(block.Size)
+		}
+		status.BlockCount = len(blockList.CommittedBlocks) + len(blockList.UncommittedBlocks)
+		status.TotalBytes = status.CommittedBytes + status.UncommittedBytes
+	case isNotFoundError(err):
+		// The blob of a session doesn't exist until a block is staged
+		if req.Metadata[objectstorage.MetadataKeySessionToken] == "" {
+			return nil, ErrBlobNotFound
+		}
+	default:
+		return nil, fmt.Errorf("error getting block list of az blob: %w", err)
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling getUploadStatus response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
@@ -0,0 +1,183 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/objectstorage"
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBlockService stores the staged blocks and the committed block lists of the requests it receives.
+type fakeBlockService struct {
+	lock      sync.Mutex
+	blocks    map[string]string
+	blockList string
+}
+
+func (f *fakeBlockService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	switch r.URL.Query().Get("comp") {
+	case "block":
+		f.blocks[r.URL.Query().Get("blockid")] = string(body)
+	case "blocklist":
+		f.blockList = string(body)
+	}
+	w.Header().Set("ETag", "\"etag\"")
+	w.WriteHeader(http.StatusCreated)
+}
+
+func TestUploadSession(t *testing.T) {
+	t.Run("stage the chunks and commit them in order", func(t *testing.T) {
+		service := &fakeBlockService{blocks: map[string]string{}}
+		blobStorage := newTestBlobStorage(t, service)
+
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: initUploadOperation,
+			Metadata:  map[string]string{"blobName": "foo"},
+		})
+		assert.Nil(t, err)
+		var started initUploadResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &started))
+		assert.Equal(t, "foo", started.BlobName)
+
+		for _, chunk := range []struct{ offset, data string }{{"0", "abc"}, {"3", "de"}} {
+			resp, err = blobStorage.Invoke(&bindings.InvokeRequest{
+				Operation: uploadChunkOperation,
+				Data:      []byte(chunk.data),
+				Metadata:  map[string]string{"sessionToken": started.SessionToken, "offset": chunk.offset},
+			})
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, "5", resp.Metadata["nextOffset"])
+
+		resp, err = blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: finishUploadOperation,
+			Metadata:  map[string]string{"sessionToken": started.SessionToken},
+		})
+		assert.Nil(t, err)
+		var created createResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, objectstorage.SHA256([]byte("abcde")), created.SHA256)
+
+		assert.Equal(t, "abc", service.blocks[blockIDFromOffset(0)])
+		assert.Equal(t, "de", service.blocks[blockIDFromOffset(3)])
+		assert.Contains(t, service.blockList, "<Latest>"+blockIDFromOffset(0)+"</Latest><Latest>"+blockIDFromOffset(3)+"</Latest>")
+
+		_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: uploadChunkOperation,
+			Data:      []byte("f"),
+			Metadata:  map[string]string{"sessionToken": started.SessionToken, "offset": "5"},
+		})
+		assert.Equal(t, objectstorage.ErrUnknownUploadSession, err)
+	})
+
+	t.Run("return error for unexpected offset", func(t *testing.T) {
+		blobStorage := newTestBlobStorage(t, &fakeBlockService{blocks: map[string]string{}})
+
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: initUploadOperation,
+			Metadata:  map[string]string{"blobName": "foo"},
+		})
+		assert.Nil(t, err)
+		var started initUploadResponse
+		assert.Nil(t, json.Unmarshal(resp.Data, &started))
+
+		_, err = blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: uploadChunkOperation,
+			Data:      []byte("abc"),
+			Metadata:  map[string]string{"sessionToken": started.SessionToken, "offset": "3"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("return error if sessionToken is missing", func(t *testing.T) {
+		blobStorage := NewAzureBlobStorage(logger.NewLogger("test"))
+		_, err := blobStorage.uploadChunk(context.Background(), &bindings.InvokeRequest{Data: []byte("abc"), Metadata: map[string]string{"offset": "0"}})
+		assert.Error(t, err)
+		_, err = blobStorage.finishUpload(context.Background(), &bindings.InvokeRequest{Metadata: map[string]string{}})
+		assert.Error(t, err)
+	})
+}
+
+func TestGetUploadStatus(t *testing.T) {
+	blobStorage := newTestBlobStorage(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "all", r.URL.Query().Get("blocklisttype"))
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<BlockList>
+			<CommittedBlocks><Block><Name>YQ==</Name><Size>10</Size></Block></CommittedBlocks>
+			<UncommittedBlocks><Block><Name>Yg==</Name><Size>4</Size></Block><Block><Name>Yw==</Name><Size>2</Size></Block></UncommittedBlocks>
+		</BlockList>`))
+	}))
+	getStatus := func(t *testing.T, metadata map[string]string) (uploadStatusResponse, error) {
+		var status uploadStatusResponse
+		resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+			Operation: getUploadStatusOperation,
+			Metadata:  metadata,
+		})
+		if err != nil {
+			return status, err
+		}
+		assert.Nil(t, json.Unmarshal(resp.Data, &status))
+
+		return status, nil
+	}
+
+	t.Run("return the blocks of a blob", func(t *testing.T) {
+		status, err := getStatus(t, map[string]string{"blobName": "foo"})
+		assert.Nil(t, err)
+		assert.Equal(t, uploadStatusResponse{BlobName: "foo", BlockCount: 3, CommittedBytes: 10, UncommittedBytes: 6, TotalBytes: 16}, status)
+
+		_, err = getStatus(t, map[string]string{"blobName": "missing"})
+		assert.Equal(t, ErrBlobNotFound, err)
+	})
+
+	t.Run("return the blocks of the blob of a session", func(t *testing.T) {
+		for name, want := range map[string]uploadStatusResponse{
+			"foo":     {BlobName: "foo", BlockCount: 3, CommittedBytes: 10, UncommittedBytes: 6, TotalBytes: 16},
+			"missing": {BlobName: "missing"},
+		} {
+			resp, err := blobStorage.Invoke(&bindings.InvokeRequest{
+				Operation: initUploadOperation,
+				Metadata:  map[string]string{"blobName": name},
+			})
+			assert.Nil(t, err)
+			var started initUploadResponse
+			assert.Nil(t, json.Unmarshal(resp.Data, &started))
+
+			status, err := getStatus(t, map[string]string{"sessionToken": started.SessionToken})
+			assert.Nil(t, err)
+			assert.Equal(t, want, status)
+		}
+	})
+
+	t.Run("return error without session or blob", func(t *testing.T) {
+		_, err := getStatus(t, map[string]string{})
+		assert.Error(t, err)
+
+		_, err = getStatus(t, map[string]string{"sessionToken": "unknown"})
+		assert.Equal(t, objectstorage.ErrUnknownUploadSession, err)
+	})
+}
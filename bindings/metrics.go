@@ -0,0 +1,42 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package bindings
+
+import "time"
+
+// Outcomes of the operations reported to Metrics
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// OperationLabels identify the operation of a metric
+type OperationLabels struct {
+	Operation string
+	// Provider of the binding, e.g. azure.blobstorage
+	Provider string
+	// OutcomeSuccess or OutcomeError
+	Outcome string
+}
+
+// Metrics receives the metrics of the operations of an output binding, so that the application
+// embedding it can record them with its own metrics library. The methods are called once per
+// operation after it completed, and must be safe for concurrent use.
+type Metrics interface {
+	IncrementOp(labels OperationLabels)
+	ObserveLatency(labels OperationLabels, latency time.Duration)
+	// ObserveBytes records the size of the data written or read by the operation
+	ObserveBytes(labels OperationLabels, bytes int64)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) IncrementOp(OperationLabels)                   {}
+func (noopMetrics) ObserveLatency(OperationLabels, time.Duration) {}
+func (noopMetrics) ObserveBytes(OperationLabels, int64)           {}
+
+// NoopMetrics discards the metrics
+var NoopMetrics Metrics = noopMetrics{}
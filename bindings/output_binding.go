@@ -5,9 +5,17 @@
 
 package bindings
 
+import "context"
+
 // OutputBinding is the interface for an output binding, allowing users to invoke remote systems with optional payloads
 type OutputBinding interface {
 	Init(metadata Metadata) error
 	Invoke(req *InvokeRequest) (*InvokeResponse, error)
 	Operations() []OperationKind
 }
+
+// OutputBindingWithContext is implemented by the output bindings that can cancel the requests of an
+// invocation with a context.
+type OutputBindingWithContext interface {
+	InvokeWithContext(ctx context.Context, req *InvokeRequest) (*InvokeResponse, error)
+}
@@ -0,0 +1,106 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The providers differ in the consistency of the listings after a delete. Azure Blob Storage and
+// AWS S3 (since December 2020) are strongly consistent: once a delete succeeds, the object is no
+// longer returned by head or list requests. Some S3 compatible stores, gateways and caching proxies
+// in front of them are only eventually consistent, and keep returning deleted objects for a while.
+// consistentDelete checks that the objects are gone before returning, at the cost of at least one
+// more request per object, so it is opt-in.
+
+const (
+	// Defines if the delete operations wait until the deleted objects are no longer visible
+	MetadataKeyConsistentDelete = "consistentDelete"
+	// Maximum number of checks of a deleted object
+	MetadataKeyConsistentDeleteAttempts = "consistentDeleteAttempts"
+	// Delay before the second check of a deleted object, doubled after every check
+	MetadataKeyConsistentDeleteDelay = "consistentDeleteDelay"
+
+	DefaultConsistentDeleteAttempts = 5
+	DefaultConsistentDeleteDelay    = 100 * time.Millisecond
+)
+
+// ErrDeleteNotConfirmed is returned when a deleted object is still visible after all the checks.
+var ErrDeleteNotConfirmed = errors.New("deleted object still visible")
+
+// DeleteConsistency is the checks of consistentDelete. A nil DeleteConsistency doesn't check.
+type DeleteConsistency struct {
+	Attempts int
+	Delay    time.Duration
+}
+
+// ParseDeleteConsistency parses consistentDelete from the component metadata. It returns nil if
+// consistentDelete isn't enabled.
+func ParseDeleteConsistency(properties map[string]string) (*DeleteConsistency, error) {
+	val, ok := properties[MetadataKeyConsistentDelete]
+	if !ok || val == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", MetadataKeyConsistentDelete, err)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	c := &DeleteConsistency{Attempts: DefaultConsistentDeleteAttempts}
+	if val, ok := properties[MetadataKeyConsistentDeleteAttempts]; ok && val != "" {
+		c.Attempts, err = strconv.Atoi(val)
+		if err != nil || c.Attempts <= 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a positive number", MetadataKeyConsistentDeleteAttempts, val)
+		}
+	}
+	c.Delay, err = ParseDuration(properties, MetadataKeyConsistentDeleteDelay, DefaultConsistentDeleteDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WaitUntilGone calls gone until it reports that the deleted object is no longer visible. The first
+// check is immediate, so strongly consistent providers only pay for one request. It returns an
+// error wrapping ErrDeleteNotConfirmed if the object is still visible after all the checks, or the
+// error of ctx if it is done first. A nil DeleteConsistency returns at once.
+func (c *DeleteConsistency) WaitUntilGone(ctx context.Context, gone func(ctx context.Context) (bool, error)) error {
+	if c == nil {
+		return nil
+	}
+
+	delay := c.Delay
+	for attempt := 1; ; attempt++ {
+		ok, err := gone(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if attempt == c.Attempts {
+			return fmt.Errorf("%w after %d checks", ErrDeleteNotConfirmed, c.Attempts)
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+
+			return ctx.Err()
+		case <-t.C:
+		}
+		delay *= 2
+	}
+}
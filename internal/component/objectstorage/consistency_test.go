@@ -0,0 +1,89 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeleteConsistency(t *testing.T) {
+	c, err := ParseDeleteConsistency(map[string]string{})
+	assert.Nil(t, err)
+	assert.Nil(t, c)
+
+	c, err = ParseDeleteConsistency(map[string]string{"consistentDelete": "false", "consistentDeleteAttempts": "3"})
+	assert.Nil(t, err)
+	assert.Nil(t, c)
+
+	c, err = ParseDeleteConsistency(map[string]string{"consistentDelete": "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, &DeleteConsistency{Attempts: DefaultConsistentDeleteAttempts, Delay: DefaultConsistentDeleteDelay}, c)
+
+	c, err = ParseDeleteConsistency(map[string]string{"consistentDelete": "true", "consistentDeleteAttempts": "3", "consistentDeleteDelay": "1s"})
+	assert.Nil(t, err)
+	assert.Equal(t, &DeleteConsistency{Attempts: 3, Delay: time.Second}, c)
+
+	for _, properties := range []map[string]string{
+		{"consistentDelete": "maybe"},
+		{"consistentDelete": "true", "consistentDeleteAttempts": "0"},
+		{"consistentDelete": "true", "consistentDeleteDelay": "soon"},
+	} {
+		_, err = ParseDeleteConsistency(properties)
+		assert.Error(t, err, properties)
+	}
+}
+
+func TestWaitUntilGone(t *testing.T) {
+	c := &DeleteConsistency{Attempts: 3, Delay: time.Millisecond}
+	checks := 0
+	visibleFor := func(n int) func(context.Context) (bool, error) {
+		checks = 0
+
+		return func(context.Context) (bool, error) {
+			checks++
+
+			return checks > n, nil
+		}
+	}
+
+	t.Run("return once the object is gone", func(t *testing.T) {
+		assert.Nil(t, c.WaitUntilGone(context.Background(), visibleFor(0)))
+		assert.Equal(t, 1, checks)
+
+		assert.Nil(t, c.WaitUntilGone(context.Background(), visibleFor(2)))
+		assert.Equal(t, 3, checks)
+	})
+
+	t.Run("return ErrDeleteNotConfirmed after the last check", func(t *testing.T) {
+		err := c.WaitUntilGone(context.Background(), visibleFor(3))
+		assert.True(t, errors.Is(err, ErrDeleteNotConfirmed))
+		assert.Equal(t, 3, checks)
+	})
+
+	t.Run("return the errors of the checks and of the context", func(t *testing.T) {
+		failure := errors.New("failure")
+		err := c.WaitUntilGone(context.Background(), func(context.Context) (bool, error) {
+			return false, failure
+		})
+		assert.Equal(t, failure, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = (&DeleteConsistency{Attempts: 3, Delay: time.Hour}).WaitUntilGone(ctx, visibleFor(3))
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("don't check without consistentDelete", func(t *testing.T) {
+		var disabled *DeleteConsistency
+		assert.Nil(t, disabled.WaitUntilGone(context.Background(), visibleFor(3)))
+		assert.Equal(t, 0, checks)
+	})
+}
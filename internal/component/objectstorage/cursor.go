@@ -0,0 +1,86 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// A list cursor is the continuation state of a listing returned to the caller as an opaque token,
+// so that callers don't depend on the marker format of the provider. The token is the base64 of a
+// versioned JSON document followed by a checksum: a cursor that was modified, or that was returned
+// by another provider or for another prefix, is rejected with ErrInvalidCursor. The checksum
+// detects changes to the token, it doesn't authenticate it.
+
+const (
+	// Cursor returned by a list operation that is not complete, to pass to the next one
+	MetadataKeyCursor = "cursor"
+
+	cursorVersion  = 1
+	cursorChecksum = 8
+)
+
+// ErrInvalidCursor is returned for cursors that can't be decoded or don't match the listing.
+var ErrInvalidCursor = errors.New("invalid list cursor")
+
+// ListCursor is the continuation state of a listing.
+type ListCursor struct {
+	Provider string `json:"provider"`
+	Marker   string `json:"marker"`
+	Prefix   string `json:"prefix,omitempty"`
+	PageSize int32  `json:"pageSize,omitempty"`
+}
+
+type versionedCursor struct {
+	Version int `json:"v"`
+	ListCursor
+}
+
+// EncodeCursor returns the token of c.
+func EncodeCursor(c ListCursor) (string, error) {
+	b, err := json.Marshal(versionedCursor{Version: cursorVersion, ListCursor: c})
+	if err != nil {
+		return "", fmt.Errorf("error encoding list cursor: %w", err)
+	}
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(append(b, sum[:cursorChecksum]...)), nil
+}
+
+// DecodeCursor returns the cursor of token, which must have been encoded for provider and prefix.
+// An empty prefix matches the prefix of the cursor.
+func DecodeCursor(token, provider, prefix string) (ListCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) <= cursorChecksum {
+		return ListCursor{}, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+	data, checksum := b[:len(b)-cursorChecksum], b[len(b)-cursorChecksum:]
+	sum := sha256.Sum256(data)
+	if !bytes.Equal(sum[:cursorChecksum], checksum) {
+		return ListCursor{}, fmt.Errorf("%w: checksum mismatch", ErrInvalidCursor)
+	}
+
+	var c versionedCursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return ListCursor{}, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+	if c.Version != cursorVersion {
+		return ListCursor{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidCursor, c.Version)
+	}
+	if c.Provider != provider {
+		return ListCursor{}, fmt.Errorf("%w: cursor of %s can't be used with %s", ErrInvalidCursor, c.Provider, provider)
+	}
+	if prefix != "" && c.Prefix != prefix {
+		return ListCursor{}, fmt.Errorf("%w: cursor of prefix %q can't be used with prefix %q", ErrInvalidCursor, c.Prefix, prefix)
+	}
+
+	return c.ListCursor, nil
+}
@@ -0,0 +1,41 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := ListCursor{Provider: "test", Marker: "2!88!MDAwMDE", Prefix: "logs/", PageSize: 100}
+	token, err := EncodeCursor(cursor)
+	assert.Nil(t, err)
+
+	decoded, err := DecodeCursor(token, "test", "logs/")
+	assert.Nil(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	decoded, err = DecodeCursor(token, "test", "")
+	assert.Nil(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	_, err = DecodeCursor(token, "other", "")
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+
+	_, err = DecodeCursor(token, "test", "images/")
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+
+	b, _ := base64.RawURLEncoding.DecodeString(token)
+	b[len(b)/2] ^= 1
+	for _, val := range []string{base64.RawURLEncoding.EncodeToString(b), "not a cursor", "", "YWJj"} {
+		_, err = DecodeCursor(val, "test", "")
+		assert.True(t, errors.Is(err, ErrInvalidCursor), val)
+	}
+}
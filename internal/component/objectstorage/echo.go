@@ -0,0 +1,52 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"strings"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// EchoMetadataPrefix is the prefix of the request metadata keys that are copied verbatim to the
+// response metadata, e.g. to correlate the response of an asynchronous pipeline with its request.
+// The binding doesn't interpret these keys and never sends them to the storage service.
+const EchoMetadataPrefix = "echo."
+
+// ExtractEchoMetadata removes the keys with EchoMetadataPrefix from the request metadata and
+// returns them.
+func ExtractEchoMetadata(metadata map[string]string) map[string]string {
+	var echo map[string]string
+	for k, v := range metadata {
+		if strings.HasPrefix(k, EchoMetadataPrefix) {
+			if echo == nil {
+				echo = map[string]string{}
+			}
+			echo[k] = v
+			delete(metadata, k)
+		}
+	}
+
+	return echo
+}
+
+// WithEchoMetadata adds the echoed keys to the metadata of resp, which is created if nil.
+func WithEchoMetadata(resp *bindings.InvokeResponse, echo map[string]string) *bindings.InvokeResponse {
+	if len(echo) == 0 {
+		return resp
+	}
+	if resp == nil {
+		resp = &bindings.InvokeResponse{}
+	}
+	if resp.Metadata == nil {
+		resp.Metadata = make(map[string]string, len(echo))
+	}
+	for k, v := range echo {
+		resp.Metadata[k] = v
+	}
+
+	return resp
+}
@@ -0,0 +1,33 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMetadata(t *testing.T) {
+	t.Run("move the echoed keys out of the request metadata", func(t *testing.T) {
+		metadata := map[string]string{"echo.requestId": "42", "key": "foo", "echoed": "no"}
+		echo := ExtractEchoMetadata(metadata)
+		assert.Equal(t, map[string]string{"echo.requestId": "42"}, echo)
+		assert.Equal(t, map[string]string{"key": "foo", "echoed": "no"}, metadata)
+	})
+
+	t.Run("add the echoed keys to the response", func(t *testing.T) {
+		resp := WithEchoMetadata(&bindings.InvokeResponse{Metadata: map[string]string{"etag": "1"}}, map[string]string{"echo.requestId": "42"})
+		assert.Equal(t, map[string]string{"etag": "1", "echo.requestId": "42"}, resp.Metadata)
+	})
+
+	t.Run("create the response of operations without one", func(t *testing.T) {
+		resp := WithEchoMetadata(nil, map[string]string{"echo.requestId": "42"})
+		assert.Equal(t, map[string]string{"echo.requestId": "42"}, resp.Metadata)
+		assert.Nil(t, WithEchoMetadata(nil, nil))
+	})
+}
@@ -0,0 +1,33 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Defines if objects without data can be created, e.g. as folder placeholders. Defaults to true.
+const MetadataKeyAllowEmpty = "allowEmpty"
+
+// ErrEmptyData is returned by writes of objects without data when allowEmpty is false.
+var ErrEmptyData = errors.New("the data is empty and allowEmpty is false")
+
+// ParseAllowEmpty parses allowEmpty from the component metadata.
+func ParseAllowEmpty(properties map[string]string) (bool, error) {
+	val, ok := properties[MetadataKeyAllowEmpty]
+	if !ok || val == "" {
+		return true, nil
+	}
+
+	allow, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", MetadataKeyAllowEmpty, err)
+	}
+
+	return allow, nil
+}
@@ -0,0 +1,25 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAllowEmpty(t *testing.T) {
+	allow, err := ParseAllowEmpty(map[string]string{})
+	assert.Nil(t, err)
+	assert.True(t, allow)
+
+	allow, err = ParseAllowEmpty(map[string]string{"allowEmpty": "false"})
+	assert.Nil(t, err)
+	assert.False(t, allow)
+
+	_, err = ParseAllowEmpty(map[string]string{"allowEmpty": "maybe"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// HashingReader computes the SHA-256 digest of the data read through it, so that the content hash of an
+// upload is available once the upload read the body, without a second pass over the data.
+// It's not an io.Seeker on purpose: callers that rewind the body would hash the same data twice.
+type HashingReader struct {
+	r io.Reader
+	h hash.Hash
+}
+
+// NewHashingReader returns a HashingReader reading from r.
+func NewHashingReader(r io.Reader) *HashingReader {
+	return &HashingReader{r: r, h: sha256.New()}
+}
+
+func (r *HashingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.h.Write(p[:n])
+
+	return n, err
+}
+
+// SHA256 returns the hex encoded digest of the data read so far.
+func (r *HashingReader) SHA256() string {
+	return hex.EncodeToString(r.h.Sum(nil))
+}
+
+// SHA256 returns the hex encoded digest of data.
+func SHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package objectstorage contains helpers shared by the object storage bindings (AWS S3 and Azure Blob Storage).
+package objectstorage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultKeyTemplate generates a random UUID key.
+const DefaultKeyTemplate = "{uuid}"
+
+// ErrInvalidKey is returned by ValidateKey for keys that could address objects outside of the
+// intended path.
+var ErrInvalidKey = errors.New("invalid object key")
+
+// GenerateKey expands a key template used to name objects created without an explicit name.
+// Supported tokens are {uuid} (a random UUID), {date} (the current UTC date as yyyy-mm-dd),
+// {yyyy}, {mm} and {dd} (the current UTC year, month and day) and {ext} (the extension for
+// contentType including the leading dot, or an empty string if unknown).
+func GenerateKey(template string, contentType string, now time.Time) string {
+	if template == "" {
+		template = DefaultKeyTemplate
+	}
+
+	now = now.UTC()
+	r := strings.NewReplacer(
+		"{uuid}", uuid.New().String(),
+		"{date}", now.Format("2006-01-02"),
+		"{yyyy}", now.Format("2006"),
+		"{mm}", now.Format("01"),
+		"{dd}", now.Format("02"),
+		"{ext}", ExtensionForContentType(contentType),
+	)
+
+	return r.Replace(template)
+}
+
+// Bounds of the length of the prefixes of HashPrefix, in hex characters
+const (
+	DefaultHashPrefixLength = 4
+	MaxHashPrefixLength     = 16
+)
+
+// HashPrefix prepends the first length hex characters of the SHA-256 digest of key to it as a path
+// element, e.g. "3f2a/2021-07-04/log.json". Sequential keys get evenly distributed prefixes, which
+// spreads writes across the partitions of the bucket.
+func HashPrefix(key string, length int) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])[:length] + "/" + key
+}
+
+// ExtensionForContentType returns the file extension registered for a content type, including the
+// leading dot. When several extensions are registered, the shortest one is picked (alphabetically
+// for ties) so the result is stable.
+func ExtensionForContentType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	exts, err := mime.ExtensionsByType(contentType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+
+	sort.Slice(exts, func(i, j int) bool {
+		if len(exts[i]) != len(exts[j]) {
+			return len(exts[i]) < len(exts[j])
+		}
+
+		return exts[i] < exts[j]
+	})
+
+	return exts[0]
+}
+
+// AppendExtension appends the extension for contentType to key when the last element of key has
+// no extension. The key is returned unchanged if the content type has no registered extension.
+func AppendExtension(key string, contentType string) string {
+	if path.Ext(key) != "" {
+		return key
+	}
+
+	return key + ExtensionForContentType(contentType)
+}
+
+// ValidateKey rejects keys that contain ".." path segments or null bytes, or that start with a
+// slash. Such keys are valid object names, but are resolved as paths by hierarchical namespaces
+// and by some path-style endpoints.
+func ValidateKey(key string) error {
+	if strings.HasPrefix(key, "/") {
+		return fmt.Errorf("%w %q: keys can't start with a slash", ErrInvalidKey, key)
+	}
+	if strings.ContainsRune(key, 0) {
+		return fmt.Errorf("%w %q: keys can't contain null bytes", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%w %q: keys can't contain .. segments", ErrInvalidKey, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKey(t *testing.T) {
+	now := time.Date(2021, 7, 4, 10, 0, 0, 0, time.UTC)
+
+	t.Run("default to a uuid", func(t *testing.T) {
+		key := GenerateKey("", "", now)
+		_, err := uuid.Parse(key)
+		assert.Nil(t, err)
+	})
+
+	t.Run("expand date tokens", func(t *testing.T) {
+		assert.Equal(t, "2021/07/04/2021-07-04", GenerateKey("{yyyy}/{mm}/{dd}/{date}", "", now))
+	})
+
+	t.Run("expand extension token", func(t *testing.T) {
+		assert.Equal(t, "logs/file.png", GenerateKey("logs/file{ext}", "image/png", now))
+		assert.Equal(t, "logs/file", GenerateKey("logs/file{ext}", "application/x-unknown-type", now))
+	})
+}
+
+func TestHashPrefix(t *testing.T) {
+	key := HashPrefix("2021-07-04/log.json", 4)
+	assert.Equal(t, "2021-07-04/log.json", key[5:])
+	assert.Regexp(t, "^[0-9a-f]{4}/", key)
+	assert.Equal(t, key, HashPrefix("2021-07-04/log.json", 4))
+	assert.NotEqual(t, key[:4], HashPrefix("2021-07-05/log.json", 4)[:4])
+	assert.Len(t, HashPrefix("a", MaxHashPrefixLength), MaxHashPrefixLength+2)
+}
+
+func TestAppendExtension(t *testing.T) {
+	t.Run("append extension to key without one", func(t *testing.T) {
+		assert.Equal(t, "v1.2/image.png", AppendExtension("v1.2/image", "image/png"))
+	})
+
+	t.Run("keep existing extension", func(t *testing.T) {
+		assert.Equal(t, "image.jpeg", AppendExtension("image.jpeg", "image/png"))
+	})
+
+	t.Run("keep key for unknown content type", func(t *testing.T) {
+		assert.Equal(t, "image", AppendExtension("image", ""))
+	})
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"foo", "dir/foo.txt", "foo..bar", "dir/.../foo"} {
+		assert.Nil(t, ValidateKey(key), key)
+	}
+	for _, key := range []string{"/foo", "../foo", "dir/../../foo", "dir/..", "foo\x00bar"} {
+		assert.ErrorIs(t, ValidateKey(key), ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,86 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// When true, the metadata of objects larger than the limit of the provider is truncated to fit
+// instead of failing the request
+const MetadataKeyTruncateMetadata = "truncateMetadata"
+
+// ErrMetadataTooLarge is returned for objects whose metadata exceeds the limit of the provider.
+var ErrMetadataTooLarge = errors.New("object metadata too large")
+
+// MetadataSize returns the size of metadata as counted by the providers, the sum of the bytes of
+// the names and values.
+func MetadataSize(metadata map[string]string) int {
+	size := 0
+	for k, v := range metadata {
+		size += len(k) + len(v)
+	}
+
+	return size
+}
+
+// FitMetadata returns an error wrapping ErrMetadataTooLarge if metadata takes more than limit bytes.
+// With truncate, the entries are kept in the order of their names while they fit: the value of the
+// first entry that doesn't fit is truncated, and the following entries are removed. It returns the
+// names of the entries that were truncated or removed.
+func FitMetadata(metadata map[string]string, limit int, truncate bool) ([]string, error) {
+	size := MetadataSize(metadata)
+	if size <= limit {
+		return nil, nil
+	}
+	if !truncate {
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d bytes of names and values", ErrMetadataTooLarge, size, limit)
+	}
+
+	names := make([]string, 0, len(metadata))
+	for name := range metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var affected []string
+	remaining := limit
+	for _, name := range names {
+		value := metadata[name]
+		if len(name)+len(value) <= remaining {
+			remaining -= len(name) + len(value)
+
+			continue
+		}
+
+		affected = append(affected, name)
+		if len(name) < remaining {
+			value = truncateUTF8(value, remaining-len(name))
+		} else {
+			value = ""
+		}
+		if value == "" {
+			delete(metadata, name)
+		} else {
+			metadata[name] = value
+		}
+		remaining = 0
+	}
+
+	return affected, nil
+}
+
+// truncateUTF8 returns the longest prefix of s of at most n bytes that doesn't split a character.
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,51 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFitMetadata(t *testing.T) {
+	t.Run("keep metadata within the limit", func(t *testing.T) {
+		metadata := map[string]string{"a": "123", "b": "45"}
+		assert.Equal(t, 7, MetadataSize(metadata))
+		affected, err := FitMetadata(metadata, 7, false)
+		assert.Nil(t, err)
+		assert.Empty(t, affected)
+		assert.Equal(t, map[string]string{"a": "123", "b": "45"}, metadata)
+	})
+
+	t.Run("return error for metadata over the limit", func(t *testing.T) {
+		_, err := FitMetadata(map[string]string{"a": "123", "b": "45"}, 6, false)
+		assert.True(t, errors.Is(err, ErrMetadataTooLarge))
+		assert.Contains(t, err.Error(), "7 bytes, the limit is 6 bytes")
+	})
+
+	t.Run("truncate and remove the entries that don't fit", func(t *testing.T) {
+		metadata := map[string]string{"a": "123", "b": "4567", "c": "8"}
+		affected, err := FitMetadata(metadata, 7, true)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"b", "c"}, affected)
+		assert.Equal(t, map[string]string{"a": "123", "b": "45"}, metadata)
+	})
+
+	t.Run("don't split characters", func(t *testing.T) {
+		metadata := map[string]string{"a": "été"}
+		affected, err := FitMetadata(metadata, 3, true)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, affected)
+		assert.Equal(t, map[string]string{"a": "é"}, metadata)
+
+		metadata = map[string]string{"a": "été"}
+		_, err = FitMetadata(metadata, 2, true)
+		assert.Nil(t, err)
+		assert.Empty(t, metadata)
+	})
+}
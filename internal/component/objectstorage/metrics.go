@@ -0,0 +1,35 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// ObserveOperation reports an operation that started at start and returned err. bytes is the size of
+// the data written or read by the operation, or negative if the operation doesn't transfer object
+// data. Bytes are only reported for successful operations. A nil m discards the metrics.
+func ObserveOperation(m bindings.Metrics, provider string, operation bindings.OperationKind, start time.Time, bytes int64, err error) {
+	if m == nil {
+		return
+	}
+	labels := bindings.OperationLabels{
+		Operation: string(operation),
+		Provider:  provider,
+		Outcome:   bindings.OutcomeSuccess,
+	}
+	if err != nil {
+		labels.Outcome = bindings.OutcomeError
+	}
+
+	m.IncrementOp(labels)
+	m.ObserveLatency(labels, time.Since(start))
+	if bytes >= 0 && err == nil {
+		m.ObserveBytes(labels, bytes)
+	}
+}
@@ -0,0 +1,55 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMetrics struct {
+	ops       []bindings.OperationLabels
+	latencies []time.Duration
+	bytes     []int64
+}
+
+func (f *fakeMetrics) IncrementOp(labels bindings.OperationLabels) {
+	f.ops = append(f.ops, labels)
+}
+
+func (f *fakeMetrics) ObserveLatency(labels bindings.OperationLabels, latency time.Duration) {
+	f.latencies = append(f.latencies, latency)
+}
+
+func (f *fakeMetrics) ObserveBytes(labels bindings.OperationLabels, bytes int64) {
+	f.bytes = append(f.bytes, bytes)
+}
+
+func TestObserveOperation(t *testing.T) {
+	m := &fakeMetrics{}
+	start := time.Now().Add(-time.Second)
+
+	ObserveOperation(m, "test", bindings.CreateOperation, start, 4, nil)
+	ObserveOperation(m, "test", bindings.CreateOperation, start, 4, errors.New("failed"))
+	ObserveOperation(m, "test", bindings.DeleteOperation, start, -1, nil)
+
+	assert.Equal(t, []bindings.OperationLabels{
+		{Operation: "create", Provider: "test", Outcome: bindings.OutcomeSuccess},
+		{Operation: "create", Provider: "test", Outcome: bindings.OutcomeError},
+		{Operation: "delete", Provider: "test", Outcome: bindings.OutcomeSuccess},
+	}, m.ops)
+	assert.Len(t, m.latencies, 3)
+	assert.GreaterOrEqual(t, int64(m.latencies[0]), int64(time.Second))
+	assert.Equal(t, []int64{4}, m.bytes)
+
+	assert.NotPanics(t, func() {
+		ObserveOperation(nil, "test", bindings.CreateOperation, start, 4, nil)
+	})
+}
@@ -0,0 +1,51 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Number of bytes returned by the preview operation
+	MetadataKeyPreviewBytes = "previewBytes"
+	DefaultPreviewBytes     = 4096
+	// Total size of the object, returned in the metadata of the preview response
+	MetadataKeyContentLength = "contentLength"
+)
+
+// ParsePreviewBytes returns the number of bytes to preview from the request metadata.
+func ParsePreviewBytes(metadata map[string]string) (int64, error) {
+	val, ok := metadata[MetadataKeyPreviewBytes]
+	if !ok || val == "" {
+		return DefaultPreviewBytes, nil
+	}
+
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a positive number of bytes", MetadataKeyPreviewBytes, val)
+	}
+
+	return n, nil
+}
+
+// ContentRangeSize returns the total size of the object from a Content-Range response header,
+// e.g. "bytes 0-99/1234". It returns false if the header has no known size.
+func ContentRangeSize(contentRange string) (int64, bool) {
+	i := strings.LastIndex(contentRange, "/")
+	if i < 0 {
+		return 0, false
+	}
+
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return size, true
+}
@@ -0,0 +1,40 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePreviewBytes(t *testing.T) {
+	t.Run("default when not set", func(t *testing.T) {
+		n, err := ParsePreviewBytes(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(DefaultPreviewBytes), n)
+	})
+
+	t.Run("parse previewBytes", func(t *testing.T) {
+		n, err := ParsePreviewBytes(map[string]string{"previewBytes": "100"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(100), n)
+	})
+
+	t.Run("return error for invalid previewBytes", func(t *testing.T) {
+		_, err := ParsePreviewBytes(map[string]string{"previewBytes": "0"})
+		assert.Error(t, err)
+	})
+}
+
+func TestContentRangeSize(t *testing.T) {
+	size, ok := ContentRangeSize("bytes 0-99/1234")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1234), size)
+
+	_, ok = ContentRangeSize("bytes 0-99/*")
+	assert.False(t, ok)
+}
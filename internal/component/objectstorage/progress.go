@@ -0,0 +1,104 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dapr/kit/logger"
+)
+
+// Minimum interval between two progress logs of a transfer, progress isn't logged if not set
+const MetadataKeyProgressLogInterval = "progressLogInterval"
+
+// ParseProgressLogInterval parses the progress log interval from the component metadata.
+func ParseProgressLogInterval(properties map[string]string) (time.Duration, error) {
+	return ParseDuration(properties, MetadataKeyProgressLogInterval, 0)
+}
+
+// ProgressLogger logs the bytes transferred by an upload or a download at most once per interval.
+// A nil ProgressLogger doesn't log.
+type ProgressLogger struct {
+	logger    logger.Logger
+	operation string
+	name      string
+	total     int64
+	interval  time.Duration
+
+	lock    sync.Mutex
+	lastLog time.Time
+	now     func() time.Time
+}
+
+// NewProgressLogger returns a ProgressLogger for the transfer of total bytes of the object name, or
+// nil if interval is not positive. total is ignored if not positive.
+func NewProgressLogger(log logger.Logger, operation string, name string, total int64, interval time.Duration) *ProgressLogger {
+	if interval <= 0 {
+		return nil
+	}
+
+	p := &ProgressLogger{
+		logger:    log,
+		operation: operation,
+		name:      name,
+		total:     total,
+		interval:  interval,
+		now:       time.Now,
+	}
+	p.lastLog = p.now()
+
+	return p
+}
+
+// Report records that transferred bytes were transferred so far, and logs them if the interval
+// elapsed since the last log or the transfer completed.
+func (p *ProgressLogger) Report(transferred int64) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	completed := p.total > 0 && transferred >= p.total
+	if !completed && now.Sub(p.lastLog) < p.interval {
+		return
+	}
+	p.lastLog = now
+
+	if p.total > 0 {
+		p.logger.Infof("%s %s: %d of %d bytes (%.0f%%)", p.operation, p.name, transferred, p.total, float64(transferred)*100/float64(p.total))
+	} else {
+		p.logger.Infof("%s %s: %d bytes", p.operation, p.name, transferred)
+	}
+}
+
+// ProgressReader reports the bytes read from the reader to a ProgressLogger.
+type ProgressReader struct {
+	r         io.Reader
+	progress  *ProgressLogger
+	lock      sync.Mutex
+	bytesRead int64
+}
+
+// NewProgressReader returns a reader reporting the bytes read from r to progress.
+func NewProgressReader(r io.Reader, progress *ProgressLogger) *ProgressReader {
+	return &ProgressReader{r: r, progress: progress}
+}
+
+func (r *ProgressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.lock.Lock()
+	r.bytesRead += int64(n)
+	bytesRead := r.bytesRead
+	r.lock.Unlock()
+	r.progress.Report(bytesRead)
+
+	return n, err
+}
@@ -0,0 +1,60 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dapr/kit/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProgressLogInterval(t *testing.T) {
+	d, err := ParseProgressLogInterval(map[string]string{"progressLogInterval": "10s"})
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Second, d)
+
+	_, err = ParseProgressLogInterval(map[string]string{"progressLogInterval": "often"})
+	assert.Error(t, err)
+}
+
+func TestProgressLogger(t *testing.T) {
+	t.Run("nil when interval is not set", func(t *testing.T) {
+		p := NewProgressLogger(logger.NewLogger("test"), "upload", "foo", 10, 0)
+		assert.Nil(t, p)
+		p.Report(5)
+	})
+
+	t.Run("log at most once per interval", func(t *testing.T) {
+		p := NewProgressLogger(logger.NewLogger("test"), "upload", "foo", 100, time.Minute)
+		now := p.lastLog
+		p.now = func() time.Time { return now }
+
+		p.Report(10)
+		assert.Equal(t, now, p.lastLog)
+
+		now = now.Add(time.Minute)
+		p.Report(20)
+		assert.Equal(t, now, p.lastLog)
+
+		// Completion is always logged
+		now = now.Add(time.Second)
+		p.Report(100)
+		assert.Equal(t, now, p.lastLog)
+	})
+}
+
+func TestProgressReader(t *testing.T) {
+	p := NewProgressLogger(logger.NewLogger("test"), "download", "foo", 5, time.Minute)
+	r := NewProgressReader(strings.NewReader("hello"), p)
+	data, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, int64(5), r.bytesRead)
+}
@@ -0,0 +1,70 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+const (
+	// Defines if get counts the records of the object and returns the count in MetadataKeyRecordCount
+	MetadataKeyReportRecordCount = "reportRecordCount"
+	// Format of the records counted by get, RecordFormatNDJSON or RecordFormatCSV
+	MetadataKeyRecordFormat = "recordFormat"
+	// Defines if the first CSV record is a header, which is not counted
+	MetadataKeyCSVHeader = "csvHeader"
+	// Number of records of the object, returned in the metadata of the get response
+	MetadataKeyRecordCount = "recordCount"
+
+	// One JSON value per line, blank lines are not counted
+	RecordFormatNDJSON = "ndjson"
+	// RFC 4180 CSV, quoted fields can span several lines
+	RecordFormatCSV = "csv"
+)
+
+// CountRecords returns the number of records of data in format. The records are only delimited, not
+// validated: an NDJSON line that is not valid JSON is counted.
+func CountRecords(data []byte, format string, header bool) (int64, error) {
+	var count int64
+	switch format {
+	case RecordFormatNDJSON:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, len(data)+1)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+				count++
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, fmt.Errorf("error reading ndjson records: %w", err)
+		}
+	case RecordFormatCSV:
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.ReuseRecord = true
+		for {
+			_, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return 0, fmt.Errorf("error reading csv records: %w", err)
+			}
+			count++
+		}
+		if header && count > 0 {
+			count--
+		}
+	default:
+		return 0, fmt.Errorf("invalid %s %q, allowed: %s, %s", MetadataKeyRecordFormat, format, RecordFormatNDJSON, RecordFormatCSV)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,47 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountRecords(t *testing.T) {
+	t.Run("count ndjson lines", func(t *testing.T) {
+		n, err := CountRecords([]byte("{\"a\":1}\n\n{\"a\":2}\r\n{\"a\":3}"), RecordFormatNDJSON, false)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("count csv records", func(t *testing.T) {
+		data := []byte("name,note\na,\"multi\nline\"\nb,x\n")
+		n, err := CountRecords(data, RecordFormatCSV, false)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), n)
+
+		n, err = CountRecords(data, RecordFormatCSV, true)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+
+	t.Run("count empty data", func(t *testing.T) {
+		n, err := CountRecords(nil, RecordFormatCSV, true)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	t.Run("return error for malformed csv", func(t *testing.T) {
+		_, err := CountRecords([]byte("a,\"unterminated\n"), RecordFormatCSV, false)
+		assert.Error(t, err)
+	})
+
+	t.Run("return error for unknown format", func(t *testing.T) {
+		_, err := CountRecords([]byte("a"), "xml", false)
+		assert.Error(t, err)
+	})
+}
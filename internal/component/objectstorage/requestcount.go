@@ -0,0 +1,66 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	// Defines if the responses report the number of requests an operation sent to the service
+	MetadataKeyReturnBilledRequests = "returnBilledRequests"
+	// Response metadata key of the number of requests sent to the service by the operation
+	MetadataKeyBilledRequests = "billedRequests"
+)
+
+type requestCounterContextKey struct{}
+
+// RequestCounter counts the requests an operation sends to the service, as an estimate of the
+// billable requests of the operation. Every try of a request is counted, since the providers bill
+// the requests that are retried.
+type RequestCounter struct {
+	n int64
+}
+
+// WithRequestCounter returns a context whose requests are counted by counter.
+func WithRequestCounter(ctx context.Context) (context.Context, *RequestCounter) {
+	counter := &RequestCounter{}
+
+	return context.WithValue(ctx, requestCounterContextKey{}, counter), counter
+}
+
+// CountRequest counts a request sent with ctx, if ctx has a RequestCounter.
+func CountRequest(ctx context.Context) {
+	if counter, ok := ctx.Value(requestCounterContextKey{}).(*RequestCounter); ok {
+		atomic.AddInt64(&counter.n, 1)
+	}
+}
+
+// Count returns the number of requests counted so far.
+func (c *RequestCounter) Count() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+// WithBilledRequests adds the number of requests counted by counter to the metadata of resp, which
+// is created if nil. resp is returned unchanged if counter is nil.
+func WithBilledRequests(resp *bindings.InvokeResponse, counter *RequestCounter) *bindings.InvokeResponse {
+	if counter == nil {
+		return resp
+	}
+	if resp == nil {
+		resp = &bindings.InvokeResponse{}
+	}
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]string{}
+	}
+	resp.Metadata[MetadataKeyBilledRequests] = strconv.FormatInt(counter.Count(), 10)
+
+	return resp
+}
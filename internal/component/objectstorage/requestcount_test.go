@@ -0,0 +1,40 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestCounter(t *testing.T) {
+	ctx, counter := WithRequestCounter(context.Background())
+	CountRequest(ctx)
+	child, cancel := context.WithCancel(ctx)
+	defer cancel()
+	CountRequest(child)
+	assert.Equal(t, int64(2), counter.Count())
+
+	// Requests sent without a counter are ignored
+	CountRequest(context.Background())
+	assert.Equal(t, int64(2), counter.Count())
+}
+
+func TestWithBilledRequests(t *testing.T) {
+	_, counter := WithRequestCounter(context.Background())
+	resp := WithBilledRequests(nil, counter)
+	assert.Equal(t, map[string]string{"billedRequests": "0"}, resp.Metadata)
+
+	resp = WithBilledRequests(&bindings.InvokeResponse{Metadata: map[string]string{"marker": "m"}}, counter)
+	assert.Equal(t, map[string]string{"marker": "m", "billedRequests": "0"}, resp.Metadata)
+
+	resp = &bindings.InvokeResponse{}
+	assert.Equal(t, resp, WithBilledRequests(resp, nil))
+	assert.Nil(t, resp.Metadata)
+}
@@ -0,0 +1,24 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+// CanonicalResponse is the response of the write operations of the object storage bindings when
+// canonicalResponse is enabled, so that callers don't depend on the response of a provider. Bucket
+// is the bucket or container of the object.
+type CanonicalResponse struct {
+	Provider    string `json:"provider"`
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ETag        string `json:"etag,omitempty"`
+	VersionID   string `json:"versionId,omitempty"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType,omitempty"`
+	// URL of the thumbnail of the object, set when generateThumbnail is set for an image
+	ThumbnailURL string `json:"thumbnailURL,omitempty"`
+	// Set when returnSignedURL is set
+	*ObjectURLs
+}
@@ -0,0 +1,47 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrRetryBudgetExceeded is returned by the sub-calls of an operation once the operation used all its retries.
+var ErrRetryBudgetExceeded = errors.New("retry budget exceeded")
+
+// RetryBudget is the total number of retries the sub-calls of a single operation can make, so that
+// batch operations stay bounded when many sub-calls are throttled. A nil RetryBudget is unlimited.
+type RetryBudget struct {
+	lock      sync.Mutex
+	remaining int
+}
+
+// NewRetryBudget returns a budget of retries, or nil for an unlimited budget when retries isn't positive.
+func NewRetryBudget(retries int) *RetryBudget {
+	if retries <= 0 {
+		return nil
+	}
+
+	return &RetryBudget{remaining: retries}
+}
+
+// Take uses a retry of the budget, returning false if the budget is exhausted.
+func (b *RetryBudget) Take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+
+	return true
+}
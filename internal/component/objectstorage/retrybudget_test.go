@@ -0,0 +1,27 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Run("allow retries until the budget is exhausted", func(t *testing.T) {
+		budget := NewRetryBudget(2)
+		assert.True(t, budget.Take())
+		assert.True(t, budget.Take())
+		assert.False(t, budget.Take())
+	})
+
+	t.Run("nil budget is unlimited", func(t *testing.T) {
+		budget := NewRetryBudget(0)
+		assert.Nil(t, budget)
+		assert.True(t, budget.Take())
+	})
+}
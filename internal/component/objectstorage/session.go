@@ -0,0 +1,204 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Upload sessions stream an object of unknown size with several invocations:
+//
+//   initUpload   starts a session and returns its token
+//   uploadChunk  sends the next chunk of the session, at the offset of the bytes received so far
+//   finishUpload commits the chunks received as the object and ends the session
+//
+// The chunks of a session are processed one at a time: a chunk sent while another one of the same
+// session is in progress waits for it, and each call returns once its chunk was stored (or buffered
+// up to the minimum part size of the provider). A failed chunk leaves the session unchanged, so it
+// can be sent again at the same offset. Sessions expire when no chunk was sent for their TTL.
+
+const (
+	// Token of an upload session returned by initUpload
+	MetadataKeySessionToken = "sessionToken"
+	// Offset of a chunk in the object, must be the number of bytes received so far by the session
+	MetadataKeyOffset = "offset"
+	// Time after which an upload session without activity is discarded
+	MetadataKeyUploadSessionTTL = "uploadSessionTTL"
+	DefaultUploadSessionTTL     = time.Hour
+)
+
+// ErrUnknownUploadSession is returned for tokens of sessions that were finished, expired or never started.
+var ErrUnknownUploadSession = errors.New("unknown or expired upload session")
+
+// ParseUploadSessionTTL parses the TTL of upload sessions from the component metadata.
+func ParseUploadSessionTTL(properties map[string]string) (time.Duration, error) {
+	return ParseDuration(properties, MetadataKeyUploadSessionTTL, DefaultUploadSessionTTL)
+}
+
+// ParseOffset returns the offset of a chunk from the request metadata.
+func ParseOffset(metadata map[string]string) (int64, error) {
+	val, ok := metadata[MetadataKeyOffset]
+	if !ok || val == "" {
+		return 0, fmt.Errorf("%s is a required attribute", MetadataKeyOffset)
+	}
+
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a number of bytes", MetadataKeyOffset, val)
+	}
+
+	return n, nil
+}
+
+// UploadSession is an upload in progress. State holds the provider specific data of the session,
+// e.g. the staged blocks or uploaded parts.
+type UploadSession struct {
+	// Name of the object
+	Name string
+	// Number of bytes received so far
+	Offset int64
+	State  interface{}
+
+	lock      sync.Mutex
+	hash      hash.Hash
+	active    int
+	expiresAt time.Time
+}
+
+// Advance records that data was stored at the current offset.
+func (s *UploadSession) Advance(data []byte) {
+	s.hash.Write(data)
+	s.Offset += int64(len(data))
+}
+
+// CheckOffset returns an error if offset is not where the next chunk is expected.
+func (s *UploadSession) CheckOffset(offset int64) error {
+	if offset != s.Offset {
+		return fmt.Errorf("unexpected %s %d, the upload can be resumed from offset %d", MetadataKeyOffset, offset, s.Offset)
+	}
+
+	return nil
+}
+
+// SHA256 returns the hex encoded digest of the data received so far.
+func (s *UploadSession) SHA256() string {
+	return hex.EncodeToString(s.hash.Sum(nil))
+}
+
+// UploadSessions tracks the upload sessions in progress, keyed by token.
+type UploadSessions struct {
+	lock     sync.Mutex
+	sessions map[string]*UploadSession
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewUploadSessions returns an UploadSessions discarding the sessions without activity for ttl.
+func NewUploadSessions(ttl time.Duration) *UploadSessions {
+	return &UploadSessions{
+		sessions: map[string]*UploadSession{},
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// Start registers a session for the object name and returns its token. Expired sessions are discarded.
+func (u *UploadSessions) Start(name string, state interface{}) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating upload session token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	now := u.now()
+	for t, session := range u.sessions {
+		if session.active == 0 && now.After(session.expiresAt) {
+			delete(u.sessions, t)
+		}
+	}
+	u.sessions[token] = &UploadSession{
+		Name:      name,
+		State:     state,
+		hash:      sha256.New(),
+		expiresAt: now.Add(u.ttl),
+	}
+
+	return token, nil
+}
+
+// Acquire returns the session of token, waiting for the chunk of the session in progress if any.
+// The session must be given back with Release or Finish.
+func (u *UploadSessions) Acquire(token string) (*UploadSession, error) {
+	u.lock.Lock()
+	session, ok := u.sessions[token]
+	if !ok || (session.active == 0 && u.now().After(session.expiresAt)) {
+		delete(u.sessions, token)
+		u.lock.Unlock()
+
+		return nil, ErrUnknownUploadSession
+	}
+	session.active++
+	u.lock.Unlock()
+
+	session.lock.Lock()
+
+	// The session may have been finished while waiting
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if _, ok = u.sessions[token]; !ok {
+		session.active--
+		session.lock.Unlock()
+
+		return nil, ErrUnknownUploadSession
+	}
+
+	return session, nil
+}
+
+// Lookup returns the session of token without acquiring it, e.g. to report the progress of an upload
+// while a chunk is in progress. Only Name and the state set by Start that doesn't change can be read.
+func (u *UploadSessions) Lookup(token string) (*UploadSession, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	session, ok := u.sessions[token]
+	if !ok || (session.active == 0 && u.now().After(session.expiresAt)) {
+		return nil, ErrUnknownUploadSession
+	}
+
+	return session, nil
+}
+
+// Release gives back a session acquired with Acquire and extends its expiry.
+func (u *UploadSessions) Release(session *UploadSession) {
+	u.lock.Lock()
+	session.active--
+	session.expiresAt = u.now().Add(u.ttl)
+	u.lock.Unlock()
+
+	session.lock.Unlock()
+}
+
+// Finish ends a session acquired with Acquire, chunks waiting for it fail with ErrUnknownUploadSession.
+func (u *UploadSessions) Finish(token string, session *UploadSession) {
+	u.lock.Lock()
+	delete(u.sessions, token)
+	session.active--
+	u.lock.Unlock()
+
+	session.lock.Unlock()
+}
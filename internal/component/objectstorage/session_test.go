@@ -0,0 +1,120 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUploadSessionTTL(t *testing.T) {
+	d, err := ParseUploadSessionTTL(map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultUploadSessionTTL, d)
+
+	d, err = ParseUploadSessionTTL(map[string]string{"uploadSessionTTL": "10m"})
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, d)
+}
+
+func TestParseOffset(t *testing.T) {
+	n, err := ParseOffset(map[string]string{"offset": "42"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), n)
+
+	for _, val := range []string{"", "-1", "abc"} {
+		_, err = ParseOffset(map[string]string{"offset": val})
+		assert.Error(t, err)
+	}
+}
+
+func TestUploadSessions(t *testing.T) {
+	t.Run("track the offset and digest of the session", func(t *testing.T) {
+		u := NewUploadSessions(time.Hour)
+		token, err := u.Start("foo", "state")
+		assert.Nil(t, err)
+
+		session, err := u.Acquire(token)
+		assert.Nil(t, err)
+		assert.Equal(t, "foo", session.Name)
+		assert.Equal(t, "state", session.State)
+		assert.Nil(t, session.CheckOffset(0))
+		session.Advance([]byte("data"))
+		assert.Error(t, session.CheckOffset(0))
+		u.Release(session)
+
+		session, err = u.Acquire(token)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), session.Offset)
+		assert.Equal(t, SHA256([]byte("data")), session.SHA256())
+		u.Finish(token, session)
+
+		_, err = u.Acquire(token)
+		assert.Equal(t, ErrUnknownUploadSession, err)
+	})
+
+	t.Run("discard expired sessions", func(t *testing.T) {
+		u := NewUploadSessions(time.Minute)
+		now := time.Now()
+		u.now = func() time.Time { return now }
+		token, err := u.Start("foo", nil)
+		assert.Nil(t, err)
+
+		now = now.Add(2 * time.Minute)
+		_, err = u.Acquire(token)
+		assert.Equal(t, ErrUnknownUploadSession, err)
+	})
+
+	t.Run("process the chunks of a session one at a time", func(t *testing.T) {
+		u := NewUploadSessions(time.Hour)
+		token, err := u.Start("foo", nil)
+		assert.Nil(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				session, err := u.Acquire(token)
+				if !assert.Nil(t, err) {
+					return
+				}
+				session.Advance([]byte("x"))
+				u.Release(session)
+			}()
+		}
+		wg.Wait()
+
+		session, err := u.Acquire(token)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(50), session.Offset)
+		u.Release(session)
+	})
+	t.Run("look up a session without acquiring it", func(t *testing.T) {
+		u := NewUploadSessions(time.Minute)
+		now := time.Now()
+		u.now = func() time.Time { return now }
+		token, err := u.Start("foo", "state")
+		assert.Nil(t, err)
+
+		acquired, err := u.Acquire(token)
+		assert.Nil(t, err)
+		session, err := u.Lookup(token)
+		assert.Nil(t, err)
+		assert.Equal(t, "foo", session.Name)
+		assert.Equal(t, "state", session.State)
+		u.Release(acquired)
+
+		now = now.Add(2 * time.Minute)
+		_, err = u.Lookup(token)
+		assert.Equal(t, ErrUnknownUploadSession, err)
+		_, err = u.Lookup("other")
+		assert.Equal(t, ErrUnknownUploadSession, err)
+	})
+}
@@ -0,0 +1,76 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// Defines if create returns a signed URL granting read access to the object
+	MetadataKeyReturnSignedURL = "returnSignedURL"
+	// Validity of the signed URL returned by create, such as 15m
+	MetadataKeySignedURLExpiry = "signedURLExpiry"
+
+	DefaultSignedURLExpiry = 15 * time.Minute
+	// Longest validity of a presigned S3 URL, also applied to Azure SAS for consistency
+	MaxSignedURLExpiry = 7 * 24 * time.Hour
+)
+
+// ObjectURLs are the URLs of an object returned by create when returnSignedURL is set. The public
+// URL only gives access to objects of public buckets or containers. SignedURL is not set if the
+// credentials of the component can't sign URLs.
+type ObjectURLs struct {
+	PublicURL          string     `json:"publicURL"`
+	SignedURL          string     `json:"signedURL,omitempty"`
+	SignedURLExpiresOn *time.Time `json:"signedURLExpiresOn,omitempty"`
+}
+
+// ParseSignedURLExpiry returns the validity of the signed URL requested by returnSignedURL in the
+// request metadata, or 0 if no signed URL is requested.
+func ParseSignedURLExpiry(metadata map[string]string) (time.Duration, error) {
+	val, ok := metadata[MetadataKeyReturnSignedURL]
+	if !ok || val == "" {
+		return 0, nil
+	}
+	signed, err := strconv.ParseBool(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", MetadataKeyReturnSignedURL, err)
+	}
+	if !signed {
+		return 0, nil
+	}
+
+	return ParseURLExpiry(metadata, MetadataKeySignedURLExpiry)
+}
+
+// ParseURLExpiry returns the validity of a signed URL from key of the request metadata, or
+// DefaultSignedURLExpiry if it is not set.
+func ParseURLExpiry(metadata map[string]string, key string) (time.Duration, error) {
+	expiry, err := ParseDuration(metadata, key, DefaultSignedURLExpiry)
+	if err != nil {
+		return 0, err
+	}
+	if expiry == 0 || expiry > MaxSignedURLExpiry {
+		return 0, fmt.Errorf("invalid %s %s, expected a duration between 1s and %s", key, expiry, MaxSignedURLExpiry)
+	}
+
+	return expiry, nil
+}
+
+// PublicURL returns rawURL without its query.
+func PublicURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = ""
+
+	return u.String()
+}
@@ -0,0 +1,44 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSignedURLExpiry(t *testing.T) {
+	expiry, err := ParseSignedURLExpiry(map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), expiry)
+
+	expiry, err = ParseSignedURLExpiry(map[string]string{"returnSignedURL": "false", "signedURLExpiry": "1h"})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), expiry)
+
+	expiry, err = ParseSignedURLExpiry(map[string]string{"returnSignedURL": "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultSignedURLExpiry, expiry)
+
+	expiry, err = ParseSignedURLExpiry(map[string]string{"returnSignedURL": "true", "signedURLExpiry": "1h"})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour, expiry)
+
+	for _, val := range []string{"0s", "8d", "200h", "soon"} {
+		_, err = ParseSignedURLExpiry(map[string]string{"returnSignedURL": "true", "signedURLExpiry": val})
+		assert.Error(t, err, val)
+	}
+
+	_, err = ParseSignedURLExpiry(map[string]string{"returnSignedURL": "maybe"})
+	assert.Error(t, err)
+}
+
+func TestPublicURL(t *testing.T) {
+	assert.Equal(t, "https://bucket.s3.amazonaws.com/foo%20bar", PublicURL("https://bucket.s3.amazonaws.com/foo%20bar?versionId=1"))
+	assert.Equal(t, "https://account.blob.core.windows.net/container/foo", PublicURL("https://account.blob.core.windows.net/container/foo"))
+}
@@ -0,0 +1,182 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"mime"
+	"strconv"
+	"strings"
+
+	// Decoders of the image formats supported by Thumbnail
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// Defines if create writes a thumbnail of image objects next to them
+	MetadataKeyGenerateThumbnail = "generateThumbnail"
+	// Bounding box of the thumbnail as WIDTHxHEIGHT, e.g. 200x200, keeping the aspect ratio
+	MetadataKeyThumbnailSize = "thumbnailSize"
+
+	// Suffix appended to the name of an object to get the name of its thumbnail
+	ThumbnailSuffix      = ".thumb.jpg"
+	ThumbnailContentType = "image/jpeg"
+	DefaultThumbnailSize = 256
+	MaxThumbnailSize     = 2048
+	// Largest image a thumbnail is generated for, so that small compressed images can't make
+	// the binding allocate an arbitrary amount of memory
+	MaxThumbnailSourcePixels = 64 * 1024 * 1024
+
+	thumbnailQuality = 85
+)
+
+// ThumbnailSize is the bounding box of a thumbnail, in pixels.
+type ThumbnailSize struct {
+	Width  int
+	Height int
+}
+
+// ParseThumbnailSize returns the size of the thumbnail requested by generateThumbnail in the request
+// metadata, or nil if no thumbnail is requested.
+func ParseThumbnailSize(metadata map[string]string) (*ThumbnailSize, error) {
+	val, ok := metadata[MetadataKeyGenerateThumbnail]
+	if !ok || val == "" {
+		return nil, nil
+	}
+	generate, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", MetadataKeyGenerateThumbnail, err)
+	}
+	if !generate {
+		return nil, nil
+	}
+
+	val, ok = metadata[MetadataKeyThumbnailSize]
+	if !ok || val == "" {
+		return &ThumbnailSize{Width: DefaultThumbnailSize, Height: DefaultThumbnailSize}, nil
+	}
+	dims := strings.Split(strings.ToLower(val), "x")
+	if len(dims) != 2 {
+		return nil, invalidThumbnailSizeError(val)
+	}
+	width, err := strconv.Atoi(dims[0])
+	if err != nil || width <= 0 || width > MaxThumbnailSize {
+		return nil, invalidThumbnailSizeError(val)
+	}
+	height, err := strconv.Atoi(dims[1])
+	if err != nil || height <= 0 || height > MaxThumbnailSize {
+		return nil, invalidThumbnailSizeError(val)
+	}
+
+	return &ThumbnailSize{Width: width, Height: height}, nil
+}
+
+func invalidThumbnailSizeError(val string) error {
+	return fmt.Errorf("invalid %s %q, expected WIDTHxHEIGHT with dimensions from 1 to %d pixels", MetadataKeyThumbnailSize, val, MaxThumbnailSize)
+}
+
+// ThumbnailKey returns the name of the thumbnail of the object key.
+func ThumbnailKey(key string) string {
+	return key + ThumbnailSuffix
+}
+
+// Thumbnail returns a JPEG thumbnail of data fitting in size. It returns nil if contentType isn't an
+// image, or if the image format isn't supported: only JPEG, PNG and GIF images are decoded. Images
+// are downscaled with an area average and never upscaled, and transparent pixels are rendered over
+// a white background.
+func Thumbnail(data []byte, contentType string, size ThumbnailSize) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		return nil, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error decoding image for thumbnail: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxThumbnailSourcePixels {
+		return nil, fmt.Errorf("error generating thumbnail: image of %dx%d pixels is larger than %d pixels", config.Width, config.Height, MaxThumbnailSourcePixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image for thumbnail: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, downscale(src, size), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("error encoding thumbnail: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// thumbnailBounds returns the dimensions of an image of width by height pixels scaled down to fit
+// in size, keeping its aspect ratio.
+func thumbnailBounds(width, height int, size ThumbnailSize) (int, int) {
+	if width <= size.Width && height <= size.Height {
+		return width, height
+	}
+	// Scale by the smaller ratio, compared with cross products to stay in integers
+	if width*size.Height >= height*size.Width {
+		return size.Width, maxInt(1, (height*size.Width+width/2)/width)
+	}
+
+	return maxInt(1, (width*size.Height+height/2)/height), size.Height
+}
+
+// downscale returns src scaled down to fit in size. Each pixel of the result is the average of the
+// source pixels it covers.
+func downscale(src image.Image, size ThumbnailSize) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := thumbnailBounds(sw, sh, size)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, (y+1)*sh/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, (x+1)*sw/dw
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// Premultiplied by alpha, so adding the missing coverage renders over white
+					pr, pg, pb, pa := src.At(b.Min.X+sx, b.Min.Y+sy).RGBA()
+					r += uint64(pr + 0xffff - pa)
+					g += uint64(pg + 0xffff - pa)
+					bl += uint64(pb + 0xffff - pa)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,123 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func TestParseThumbnailSize(t *testing.T) {
+	size, err := ParseThumbnailSize(map[string]string{})
+	assert.Nil(t, err)
+	assert.Nil(t, size)
+
+	size, err = ParseThumbnailSize(map[string]string{"generateThumbnail": "false", "thumbnailSize": "10x10"})
+	assert.Nil(t, err)
+	assert.Nil(t, size)
+
+	size, err = ParseThumbnailSize(map[string]string{"generateThumbnail": "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, &ThumbnailSize{Width: DefaultThumbnailSize, Height: DefaultThumbnailSize}, size)
+
+	size, err = ParseThumbnailSize(map[string]string{"generateThumbnail": "true", "thumbnailSize": "320X200"})
+	assert.Nil(t, err)
+	assert.Equal(t, &ThumbnailSize{Width: 320, Height: 200}, size)
+
+	for _, val := range []string{"200", "0x100", "100x-1", "axb", "100x100x100", "4096x100"} {
+		_, err = ParseThumbnailSize(map[string]string{"generateThumbnail": "true", "thumbnailSize": val})
+		assert.Error(t, err, val)
+	}
+
+	_, err = ParseThumbnailSize(map[string]string{"generateThumbnail": "maybe"})
+	assert.Error(t, err)
+}
+
+func TestThumbnailBounds(t *testing.T) {
+	for _, tc := range []struct {
+		width, height int
+		size          ThumbnailSize
+		expected      [2]int
+	}{
+		{100, 50, ThumbnailSize{200, 200}, [2]int{100, 50}},
+		{400, 200, ThumbnailSize{200, 200}, [2]int{200, 100}},
+		{200, 400, ThumbnailSize{200, 200}, [2]int{100, 200}},
+		{1000, 1, ThumbnailSize{100, 100}, [2]int{100, 1}},
+		{300, 300, ThumbnailSize{200, 100}, [2]int{100, 100}},
+	} {
+		w, h := thumbnailBounds(tc.width, tc.height, tc.size)
+		assert.Equal(t, tc.expected, [2]int{w, h}, tc)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	t.Run("downscale an image", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		for x := 0; x < 40; x++ {
+			for y := 0; y < 20; y++ {
+				if x < 20 {
+					img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+				} else {
+					img.Set(x, y, color.RGBA{B: 0xff, A: 0xff})
+				}
+			}
+		}
+
+		thumb, err := Thumbnail(encodePNG(t, img), "image/png", ThumbnailSize{Width: 10, Height: 10})
+		assert.Nil(t, err)
+		decoded, err := jpeg.Decode(bytes.NewReader(thumb))
+		assert.Nil(t, err)
+		assert.Equal(t, image.Rect(0, 0, 10, 5), decoded.Bounds())
+
+		r, _, b, _ := decoded.At(1, 2).RGBA()
+		assert.Greater(t, r, b)
+		r, _, b, _ = decoded.At(8, 2).RGBA()
+		assert.Greater(t, b, r)
+	})
+
+	t.Run("render transparent pixels over white", func(t *testing.T) {
+		thumb, err := Thumbnail(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 4, 4))), "image/png; charset=binary", ThumbnailSize{Width: 2, Height: 2})
+		assert.Nil(t, err)
+		decoded, err := jpeg.Decode(bytes.NewReader(thumb))
+		assert.Nil(t, err)
+		r, g, b, _ := decoded.At(0, 0).RGBA()
+		assert.Greater(t, r, uint32(0xf000))
+		assert.Greater(t, g, uint32(0xf000))
+		assert.Greater(t, b, uint32(0xf000))
+	})
+
+	t.Run("skip content that isn't a supported image", func(t *testing.T) {
+		data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+		for _, contentType := range []string{"", "text/plain", "application/octet-stream", "not a type"} {
+			thumb, err := Thumbnail(data, contentType, ThumbnailSize{Width: 2, Height: 2})
+			assert.Nil(t, err, contentType)
+			assert.Nil(t, thumb, contentType)
+		}
+
+		thumb, err := Thumbnail([]byte("RIFF....WEBPVP8 "), "image/webp", ThumbnailSize{Width: 2, Height: 2})
+		assert.Nil(t, err)
+		assert.Nil(t, thumb)
+	})
+
+	t.Run("return error for a corrupted image", func(t *testing.T) {
+		data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+		_, err := Thumbnail(data[:len(data)/2], "image/png", ThumbnailSize{Width: 2, Height: 2})
+		assert.Error(t, err)
+	})
+}
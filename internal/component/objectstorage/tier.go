@@ -0,0 +1,42 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Defines if the tier written by a request is read back from the object properties
+	MetadataKeyVerifyTier = "verifyTier"
+	// Result of verifyTier, returned in the response metadata: TierStatusApplied or TierStatusPending
+	MetadataKeyTierStatus = "tierStatus"
+
+	// The object is in the requested tier
+	TierStatusApplied = "applied"
+	// The object is moving to the requested tier asynchronously, e.g. rehydrating from an archive tier
+	TierStatusPending = "pending"
+)
+
+// ErrTierNotApplied is returned by verifyTier when the object isn't in the requested tier and isn't
+// moving to it.
+var ErrTierNotApplied = errors.New("tier not applied")
+
+// VerifyTier compares the tier of an object read back after a write with the requested one, ignoring
+// the case. pending is true if the provider reports that the object is moving to the requested tier.
+// It returns the status to report in MetadataKeyTierStatus.
+func VerifyTier(requested, effective string, pending bool) (string, error) {
+	if strings.EqualFold(requested, effective) {
+		return TierStatusApplied, nil
+	}
+	if pending {
+		return TierStatusPending, nil
+	}
+
+	return "", fmt.Errorf("%w: requested %s, the object is in %s", ErrTierNotApplied, requested, effective)
+}
@@ -0,0 +1,26 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyTier(t *testing.T) {
+	status, err := VerifyTier("Cool", "cool", false)
+	assert.Nil(t, err)
+	assert.Equal(t, TierStatusApplied, status)
+
+	status, err = VerifyTier("Hot", "Archive", true)
+	assert.Nil(t, err)
+	assert.Equal(t, TierStatusPending, status)
+
+	_, err = VerifyTier("GLACIER", "STANDARD", false)
+	assert.True(t, errors.Is(err, ErrTierNotApplied), err)
+}
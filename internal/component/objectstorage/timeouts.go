@@ -0,0 +1,97 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// Deadline of every operation, unless readTimeout or writeTimeout is set for the operation
+	MetadataKeyOperationTimeout = "operationTimeout"
+	// Deadline of the operations that only read, e.g. get and list
+	MetadataKeyReadTimeout = "readTimeout"
+	// Deadline of the operations that write, e.g. create and delete
+	MetadataKeyWriteTimeout = "writeTimeout"
+	// Deadline of a single invocation, set in the request metadata. It applies on top of the
+	// timeouts of the component metadata.
+	MetadataKeyTimeout = "timeout"
+)
+
+// Timeouts are the deadlines of the read and write operations of a binding. Zero means no deadline.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// ParseTimeouts parses the timeouts from the component metadata. readTimeout and writeTimeout take
+// precedence over operationTimeout for the operations they apply to.
+func ParseTimeouts(properties map[string]string) (Timeouts, error) {
+	var t Timeouts
+	operation, err := ParseDuration(properties, MetadataKeyOperationTimeout, 0)
+	if err != nil {
+		return t, err
+	}
+	t.Read, err = ParseDuration(properties, MetadataKeyReadTimeout, operation)
+	if err != nil {
+		return t, err
+	}
+	t.Write, err = ParseDuration(properties, MetadataKeyWriteTimeout, operation)
+	if err != nil {
+		return t, err
+	}
+
+	return t, nil
+}
+
+// WithRequestTimeout returns ctx with the deadline of the timeout request metadata, which is removed
+// from metadata so that it isn't stored with the object.
+func WithRequestTimeout(ctx context.Context, metadata map[string]string) (context.Context, context.CancelFunc, error) {
+	timeout, err := ParseDuration(metadata, MetadataKeyTimeout, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(metadata, MetadataKeyTimeout)
+	ctx, cancel := withTimeout(ctx, timeout)
+
+	return ctx, cancel, nil
+}
+
+// ParseDuration parses the duration of key in properties, defaultValue if it isn't set. Negative
+// durations are rejected.
+func ParseDuration(properties map[string]string, key string, defaultValue time.Duration) (time.Duration, error) {
+	val, ok := properties[key]
+	if !ok || val == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a positive duration such as 30s", key, val)
+	}
+
+	return d, nil
+}
+
+// ReadContext returns the context of a read operation, derived from parent.
+func (t Timeouts) ReadContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(parent, t.Read)
+}
+
+// WriteContext returns the context of a write operation, derived from parent.
+func (t Timeouts) WriteContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(parent, t.Write)
+}
+
+func withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, d)
+}
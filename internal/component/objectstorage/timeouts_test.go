@@ -0,0 +1,91 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeouts(t *testing.T) {
+	t.Run("default to no deadline", func(t *testing.T) {
+		timeouts, err := ParseTimeouts(map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, Timeouts{}, timeouts)
+
+		ctx, cancel := timeouts.ReadContext(context.Background())
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("specific timeouts take precedence over operationTimeout", func(t *testing.T) {
+		timeouts, err := ParseTimeouts(map[string]string{
+			"operationTimeout": "30s",
+			"writeTimeout":     "5m",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, Timeouts{Read: 30 * time.Second, Write: 5 * time.Minute}, timeouts)
+
+		ctx, cancel := timeouts.WriteContext(context.Background())
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+	})
+
+	t.Run("return error for invalid timeout", func(t *testing.T) {
+		_, err := ParseTimeouts(map[string]string{"readTimeout": "soon"})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration(map[string]string{}, "ttl", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, d)
+
+	d, err = ParseDuration(map[string]string{"ttl": "0s"}, "ttl", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	for _, val := range []string{"-1m", "soon"} {
+		_, err = ParseDuration(map[string]string{"ttl": val}, "ttl", time.Minute)
+		assert.Error(t, err, val)
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	t.Run("keep the parent context without timeout", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel, err := WithRequestTimeout(parent, map[string]string{})
+		assert.Nil(t, err)
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+
+		cancelParent()
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+
+	t.Run("set the deadline of the timeout", func(t *testing.T) {
+		metadata := map[string]string{"timeout": "10s", "blobName": "a"}
+		ctx, cancel, err := WithRequestTimeout(context.Background(), metadata)
+		assert.Nil(t, err)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(10*time.Second), deadline, time.Second)
+		assert.Equal(t, map[string]string{"blobName": "a"}, metadata)
+	})
+
+	t.Run("return error for invalid timeout", func(t *testing.T) {
+		_, _, err := WithRequestTimeout(context.Background(), map[string]string{"timeout": "-1s"})
+		assert.Error(t, err)
+	})
+}
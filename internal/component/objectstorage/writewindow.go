@@ -0,0 +1,168 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Time range in which the write operations are allowed, e.g. "22:00-06:00" or "Mon-Fri 09:00-17:00".
+	// The range starts on the listed days, and ends on the next day if it crosses midnight.
+	MetadataKeyAllowedWriteWindow = "allowedWriteWindow"
+	// IANA time zone of allowedWriteWindow, e.g. "Europe/Paris". Defaults to UTC.
+	MetadataKeyAllowedWriteWindowTimezone = "allowedWriteWindowTimezone"
+)
+
+// ErrOutsideWriteWindow is returned for write operations outside of allowedWriteWindow.
+var ErrOutsideWriteWindow = errors.New("outside allowed write window")
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// WriteWindow is the time range in which a binding accepts write operations. A nil WriteWindow
+// allows writes at any time.
+type WriteWindow struct {
+	spec     string
+	days     [7]bool
+	start    time.Duration
+	end      time.Duration
+	location *time.Location
+}
+
+// ParseWriteWindow parses allowedWriteWindow from the component metadata. It returns nil if it
+// is not set.
+func ParseWriteWindow(properties map[string]string) (*WriteWindow, error) {
+	spec := strings.TrimSpace(properties[MetadataKeyAllowedWriteWindow])
+	if spec == "" {
+		return nil, nil
+	}
+
+	w := &WriteWindow{spec: spec, location: time.UTC}
+	if val := properties[MetadataKeyAllowedWriteWindowTimezone]; val != "" {
+		location, err := time.LoadLocation(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", MetadataKeyAllowedWriteWindowTimezone, val, err)
+		}
+		w.location = location
+	}
+
+	fields := strings.Fields(spec)
+	hours := fields[len(fields)-1]
+	switch len(fields) {
+	case 1:
+		for i := range w.days {
+			w.days[i] = true
+		}
+	case 2:
+		if err := w.parseDays(fields[0]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalidWriteWindowError(spec)
+	}
+
+	bounds := strings.Split(hours, "-")
+	if len(bounds) != 2 {
+		return nil, invalidWriteWindowError(spec)
+	}
+	var err error
+	if w.start, err = parseTimeOfDay(bounds[0]); err != nil {
+		return nil, invalidWriteWindowError(spec)
+	}
+	if w.end, err = parseTimeOfDay(bounds[1]); err != nil {
+		return nil, invalidWriteWindowError(spec)
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("invalid %s %q, the start and end times must differ", MetadataKeyAllowedWriteWindow, spec)
+	}
+
+	return w, nil
+}
+
+// parseDays parses a list of days and ranges of days separated by commas, e.g. "Mon-Fri,Sun".
+func (w *WriteWindow) parseDays(val string) error {
+	for _, item := range strings.Split(val, ",") {
+		bounds := strings.Split(item, "-")
+		if len(bounds) > 2 {
+			return invalidWriteWindowError(w.spec)
+		}
+		first, ok := weekdays[strings.ToLower(bounds[0])]
+		if !ok {
+			return invalidWriteWindowError(w.spec)
+		}
+		last, ok := weekdays[strings.ToLower(bounds[len(bounds)-1])]
+		if !ok {
+			return invalidWriteWindowError(w.spec)
+		}
+		// Ranges can wrap around the end of the week, e.g. "Sat-Mon"
+		for day := first; ; day = (day + 1) % 7 {
+			w.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTimeOfDay parses a time of day as HH:MM, "24:00" being the end of the day.
+func parseTimeOfDay(val string) (time.Duration, error) {
+	if val == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", val)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func invalidWriteWindowError(spec string) error {
+	return fmt.Errorf("invalid %s %q, expected a time range such as \"09:00-17:00\" optionally preceded by days such as \"Mon-Fri\"", MetadataKeyAllowedWriteWindow, spec)
+}
+
+// Check returns ErrOutsideWriteWindow if writes are not allowed at t.
+func (w *WriteWindow) Check(t time.Time) error {
+	if w == nil || w.Allows(t) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: writes are allowed %s %s", ErrOutsideWriteWindow, w.spec, w.location)
+}
+
+// Allows returns true if writes are allowed at t.
+func (w *WriteWindow) Allows(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	// The wall clock time, so that the window doesn't move on daylight saving time changes
+	t = t.In(w.location)
+	elapsed := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return w.days[t.Weekday()] && elapsed >= w.start && elapsed < w.end
+	}
+
+	// The window crosses midnight: the time after midnight belongs to the window of the previous day
+	if elapsed >= w.start {
+		return w.days[t.Weekday()]
+	}
+
+	return elapsed < w.end && w.days[(t.Weekday()+6)%7]
+}
@@ -0,0 +1,84 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation and Dapr Contributors.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package objectstorage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteWindow(t *testing.T) {
+	// 2026-10-12 is a Monday
+	at := func(day int, hour, minute int) time.Time {
+		return time.Date(2026, 10, 12+day, hour, minute, 0, 0, time.UTC)
+	}
+
+	t.Run("allow writes at any time without window", func(t *testing.T) {
+		w, err := ParseWriteWindow(map[string]string{})
+		assert.Nil(t, err)
+		assert.Nil(t, w)
+		assert.Nil(t, w.Check(at(0, 3, 0)))
+	})
+
+	t.Run("allow writes in the hours of every day", func(t *testing.T) {
+		w, err := ParseWriteWindow(map[string]string{"allowedWriteWindow": "09:00-17:30"})
+		assert.Nil(t, err)
+		assert.True(t, w.Allows(at(0, 9, 0)))
+		assert.True(t, w.Allows(at(6, 17, 29)))
+		assert.False(t, w.Allows(at(0, 17, 30)))
+		assert.False(t, w.Allows(at(0, 8, 59)))
+
+		err = w.Check(at(0, 20, 0))
+		assert.True(t, errors.Is(err, ErrOutsideWriteWindow))
+		assert.Contains(t, err.Error(), "09:00-17:30 UTC")
+	})
+
+	t.Run("allow writes in the hours of the days", func(t *testing.T) {
+		w, err := ParseWriteWindow(map[string]string{"allowedWriteWindow": "Mon-Wed,Fri 09:00-24:00"})
+		assert.Nil(t, err)
+		assert.True(t, w.Allows(at(0, 10, 0)))
+		assert.True(t, w.Allows(at(2, 23, 59)))
+		assert.False(t, w.Allows(at(3, 10, 0)))
+		assert.True(t, w.Allows(at(4, 10, 0)))
+		assert.False(t, w.Allows(at(5, 10, 0)))
+	})
+
+	t.Run("allow writes in a window crossing midnight", func(t *testing.T) {
+		w, err := ParseWriteWindow(map[string]string{"allowedWriteWindow": "Fri-Sat 22:00-06:00"})
+		assert.Nil(t, err)
+		assert.False(t, w.Allows(at(3, 23, 0)))
+		assert.True(t, w.Allows(at(4, 23, 0)))
+		assert.True(t, w.Allows(at(5, 5, 0)))
+		assert.True(t, w.Allows(at(6, 5, 59)))
+		assert.False(t, w.Allows(at(6, 6, 0)))
+		assert.False(t, w.Allows(at(6, 23, 0)))
+		assert.False(t, w.Allows(at(4, 5, 0)))
+	})
+
+	t.Run("use the time zone", func(t *testing.T) {
+		w, err := ParseWriteWindow(map[string]string{
+			"allowedWriteWindow":         "09:00-17:00",
+			"allowedWriteWindowTimezone": "Asia/Tokyo",
+		})
+		assert.Nil(t, err)
+		// 09:00 in Tokyo is 00:00 UTC
+		assert.True(t, w.Allows(at(0, 0, 0)))
+		assert.False(t, w.Allows(at(0, 9, 0)))
+	})
+
+	t.Run("return error for invalid window", func(t *testing.T) {
+		for _, val := range []string{"9-17", "09:00", "09:00-09:00", "Someday 09:00-17:00", "Mon-Tue-Wed 09:00-17:00", "Mon Tue 09:00-17:00", "09:00-25:00"} {
+			_, err := ParseWriteWindow(map[string]string{"allowedWriteWindow": val})
+			assert.Error(t, err, val)
+		}
+
+		_, err := ParseWriteWindow(map[string]string{"allowedWriteWindow": "09:00-17:00", "allowedWriteWindowTimezone": "Mars/Olympus"})
+		assert.Error(t, err)
+	})
+}